@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/database"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+// Imports a Toggl or Clockify CSV export for a user
+//
+// Usage (from the backend directory):
+//
+//	go run ./cmd/import_entries -user 1 -file toggl.csv -tz Europe/Berlin -dry-run
+func main() {
+	userID := flag.Uint("user", 0, "ID of the user to import entries for")
+	filePath := flag.String("file", "", "path to the CSV export")
+	source := flag.String("source", "", "export format: toggl or clockify (detected from the header if empty)")
+	tz := flag.String("tz", "", "timezone the export was written in (defaults to local time)")
+	dateFormat := flag.String("date-format", "", "order of slash dates: DD/MM/YYYY or MM/DD/YYYY (detected from the file if empty)")
+	dryRun := flag.Bool("dry-run", false, "validate and preview without writing anything")
+	flag.Parse()
+
+	if *userID == 0 || *filePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	loc := time.Local
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			log.Fatalf("Invalid timezone %q: %v", *tz, err)
+		}
+	}
+
+	if format := models.DateFormat(*dateFormat); format != "" && format != models.DateFormatDMY && format != models.DateFormatMDY {
+		log.Fatalf("Invalid date format %q, use DD/MM/YYYY or MM/DD/YYYY", *dateFormat)
+	}
+
+	file, err := os.Open(*filePath)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *filePath, err)
+	}
+	defer file.Close()
+
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	if err := database.Initialize(logger); err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer database.Close(logger)
+
+	var user models.User
+	if err := database.DB.First(&user, *userID).Error; err != nil {
+		log.Fatalf("User with ID %d not found", *userID)
+	}
+
+	result, err := services.ImportTimeEntries(database.DB, user.ID, file, services.ImportOptions{
+		Source:     services.ImportSource(*source),
+		Location:   loc,
+		DateFormat: models.DateFormat(*dateFormat),
+		DryRun:     *dryRun,
+	})
+	if err != nil {
+		log.Fatal("Import failed:", err)
+	}
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case services.ImportRowOK:
+			fmt.Printf("row %4d  ok         %s  %s  %s\n", row.Row, row.StartTime.Format("2006-01-02 15:04"), row.ActivityName, utils.FormatDuration(row.DurationSeconds))
+		case services.ImportRowDuplicate:
+			fmt.Printf("row %4d  duplicate  %s  %s\n", row.Row, row.StartTime.Format("2006-01-02 15:04"), row.ActivityName)
+		default:
+			fmt.Printf("row %4d  error      %s\n", row.Row, row.Error)
+		}
+	}
+
+	mode := "Imported"
+	if result.DryRun {
+		mode = "Would import"
+	}
+	fmt.Printf("\n%s %d of %d rows from %s (%d duplicates, %d errors)\n",
+		mode, result.Imported, result.TotalRows, result.Source, result.Duplicates, result.Failed)
+}
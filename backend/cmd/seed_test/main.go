@@ -35,6 +35,7 @@ func main() {
 		ActivityID: activity.ID,
 		StartTime:  startTime,
 		EndTime:    nil, // Still running
+		Billable:   true,
 	}
 	db.Create(&entry)
 	fmt.Printf("Created time entry ID: %d, started at: %s\n", entry.ID, startTime.Format("15:04:05"))
@@ -1,9 +1,9 @@
 package database
 
 import (
+	"embed"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/Felipalds/go-pomodoro/models"
 	"go.uber.org/zap"
@@ -13,24 +13,70 @@ import (
 
 var DB *gorm.DB
 
-// Initialize sets up the database connection and runs migrations
+// Initialize sets up the database connection, applies pending migrations and seeds default data
+// Set DB_AUTO_MIGRATE=false to only apply migrations explicitly with the migrate command
 func Initialize(logger *zap.Logger) error {
+	if err := Connect(logger); err != nil {
+		return err
+	}
+
+	if getEnv("DB_AUTO_MIGRATE", "true") == "true" {
+		if err := runMigrations(logger); err != nil {
+			return err
+		}
+	} else {
+		pending, err := PendingMigrations(DB)
+		if err != nil {
+			return fmt.Errorf("failed to check migrations: %w", err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d pending migrations, run the migrate up command first", pending)
+		}
+	}
+
+	if err := PrepareData(logger); err != nil {
+		return err
+	}
+
+	logger.Info("Database initialized successfully")
+	return nil
+}
+
+// Connect opens the database connection without touching the schema
+// DB_DRIVER=sqlite uses a single database file at DB_PATH instead of PostgreSQL
+func Connect(logger *zap.Logger) error {
 	var err error
 
-	// Build DSN from environment variables
-	dsn := buildDSN()
-	logger.Info("Connecting to PostgreSQL database...")
+	switch driver := getEnv("DB_DRIVER", "postgres"); driver {
+	case "postgres":
+		// Build DSN from environment variables
+		dsn := buildDSN()
+		logger.Info("Connecting to PostgreSQL database...")
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	case "sqlite":
+		dbPath := getEnv("DB_PATH", "timetracker.db")
+		logger.Info("Opening SQLite database...", zap.String("path", dbPath))
+
+		DB, err = OpenSQLite(dbPath, &gorm.Config{})
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q, use postgres or sqlite", driver)
+	}
 	if err != nil {
 		logger.Error("Failed to connect to database", zap.Error(err))
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	logger.Info("Database connection established")
+	return nil
+}
 
-	// Run auto-migration
-	if err := runMigrations(logger); err != nil {
+// PrepareData runs the data steps that follow schema migrations: splitting categories
+// and tags that predate per-user ownership and seeding the default categories
+func PrepareData(logger *zap.Logger) error {
+	// Split categories and tags that predate per-user ownership
+	splitShared, err := migrateSharedOwnership(logger)
+	if err != nil {
 		return err
 	}
 
@@ -39,7 +85,13 @@ func Initialize(logger *zap.Logger) error {
 		return err
 	}
 
-	logger.Info("Database initialized successfully")
+	// Users migrated from shared categories also get the defaults they never had
+	if splitShared {
+		if err := copyDefaultsToUsersWithoutCategories(logger); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -72,36 +124,32 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// runMigrations runs GORM auto-migration for all models
+// runMigrations applies the pending versioned migrations embedded in the binary
 func runMigrations(logger *zap.Logger) error {
 	logger.Info("Running database migrations...")
 
-	err := DB.AutoMigrate(
-		&models.User{},
-		&models.Category{},
-		&models.Tag{},
-		&models.Activity{},
-		&models.TimeEntry{},
-		&models.UserReward{},
-		&models.ChampionMastery{},
-	)
-
+	applied, err := MigrateUp(DB, logger)
 	if err != nil {
 		logger.Error("Migration failed", zap.Error(err))
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
-	logger.Info("Migrations completed successfully")
+	logger.Info("Migrations completed successfully", zap.Int("applied", applied))
 	return nil
 }
 
-// seedData seeds initial data from SQL files if the database is empty
+// seedFiles holds the seed SQL so seeding works regardless of the working directory
+//
+//go:embed seeds/*.sql
+var seedFiles embed.FS
+
+// seedData seeds the default categories from the embedded SQL files if none exist
 func seedData(logger *zap.Logger) error {
 	logger.Info("Checking if seed data is needed...")
 
-	// Check if categories already exist
+	// Check if the default categories (owned by no user) already exist
 	var count int64
-	DB.Model(&models.Category{}).Count(&count)
+	DB.Model(&models.Category{}).Where("user_id = 0").Count(&count)
 
 	if count > 0 {
 		logger.Info("Seed data already exists, skipping")
@@ -111,8 +159,8 @@ func seedData(logger *zap.Logger) error {
 	logger.Info("Seeding initial data from SQL files...")
 
 	// Read and execute the categories seed file
-	seedFile := filepath.Join("database", "seeds", "initial_categories.sql")
-	sqlContent, err := os.ReadFile(seedFile)
+	seedFile := "seeds/initial_categories.sql"
+	sqlContent, err := seedFiles.ReadFile(seedFile)
 	if err != nil {
 		logger.Error("Failed to read seed file", zap.String("file", seedFile), zap.Error(err))
 		return fmt.Errorf("failed to read seed file %s: %w", seedFile, err)
@@ -125,7 +173,7 @@ func seedData(logger *zap.Logger) error {
 	}
 
 	// Count how many categories were inserted
-	DB.Model(&models.Category{}).Count(&count)
+	DB.Model(&models.Category{}).Where("user_id = 0").Count(&count)
 	logger.Info("Seed data created successfully", zap.Int64("categories", count))
 
 	return nil
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS champion_masteries;
+DROP TABLE IF EXISTS user_rewards;
+DROP TABLE IF EXISTS time_entries;
+DROP TABLE IF EXISTS activity_tags;
+DROP TABLE IF EXISTS activities;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
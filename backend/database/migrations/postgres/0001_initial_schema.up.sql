@@ -0,0 +1,106 @@
+-- Initial schema, matching what GORM AutoMigrate created before versioned migrations
+-- Every statement is guarded with IF NOT EXISTS so databases created by AutoMigrate
+-- are adopted as-is and only recorded in schema_migrations
+
+CREATE TABLE IF NOT EXISTS users (
+    id            bigserial PRIMARY KEY,
+    name          varchar(100) NOT NULL,
+    email         varchar(255) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    deleted_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT uni_categories_name UNIQUE (name)
+);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    deleted_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT uni_tags_name UNIQUE (name)
+);
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags (deleted_at);
+
+CREATE TABLE IF NOT EXISTS activities (
+    id                 bigserial PRIMARY KEY,
+    user_id            bigint NOT NULL,
+    name               text NOT NULL,
+    main_category_id   bigint NOT NULL,
+    sub_category_id    bigint,
+    intervals_rewarded bigint DEFAULT 0,
+    deleted_at         timestamptz,
+    created_at         timestamptz,
+    updated_at         timestamptz,
+    CONSTRAINT fk_users_activities FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_activities_main_category FOREIGN KEY (main_category_id) REFERENCES categories (id),
+    CONSTRAINT fk_activities_sub_category FOREIGN KEY (sub_category_id) REFERENCES categories (id)
+);
+CREATE INDEX IF NOT EXISTS idx_activities_deleted_at ON activities (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_activities_sub_category_id ON activities (sub_category_id);
+CREATE INDEX IF NOT EXISTS idx_activities_main_category_id ON activities (main_category_id);
+CREATE INDEX IF NOT EXISTS idx_activities_user_id ON activities (user_id);
+
+CREATE TABLE IF NOT EXISTS activity_tags (
+    activity_id bigint,
+    tag_id      bigint,
+    PRIMARY KEY (activity_id, tag_id),
+    CONSTRAINT fk_activity_tags_activity FOREIGN KEY (activity_id) REFERENCES activities (id),
+    CONSTRAINT fk_activity_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS time_entries (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    activity_id bigint NOT NULL,
+    start_time  timestamptz NOT NULL,
+    end_time    timestamptz,
+    notes       text,
+    created_at  timestamptz,
+    CONSTRAINT fk_activities_time_entries FOREIGN KEY (activity_id) REFERENCES activities (id) ON DELETE CASCADE,
+    CONSTRAINT fk_users_time_entries FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_time_entries_end_time ON time_entries (end_time);
+CREATE INDEX IF NOT EXISTS idx_time_entries_start_time ON time_entries (start_time);
+CREATE INDEX IF NOT EXISTS idx_time_entries_activity_id ON time_entries (activity_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_user_id ON time_entries (user_id);
+
+CREATE TABLE IF NOT EXISTS user_rewards (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    reward_type text NOT NULL,
+    external_id text NOT NULL,
+    name        text NOT NULL,
+    image_url   text NOT NULL,
+    rarity      text NOT NULL,
+    created_at  timestamptz,
+    CONSTRAINT fk_users_user_rewards FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_rewards_user_id ON user_rewards (user_id);
+
+CREATE TABLE IF NOT EXISTS champion_masteries (
+    id             bigserial PRIMARY KEY,
+    user_id        bigint NOT NULL,
+    champion_id    text NOT NULL,
+    champion_name  text NOT NULL,
+    image_url      text NOT NULL,
+    mastery_level  bigint DEFAULT 1,
+    times_obtained bigint DEFAULT 1,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    CONSTRAINT fk_users_champion_mastery FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_champion ON champion_masteries (champion_id);
+CREATE INDEX IF NOT EXISTS idx_champion_masteries_user_id ON champion_masteries (user_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_time_entries_pomodoro_session_id;
+ALTER TABLE time_entries DROP COLUMN IF EXISTS pomodoro_completed;
+ALTER TABLE time_entries DROP COLUMN IF EXISTS pomodoro_session_id;
+
+DROP TABLE IF EXISTS pomodoro_sessions;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS pomodoro_sessions (
+    id                  bigserial PRIMARY KEY,
+    user_id             bigint NOT NULL,
+    activity_id         bigint NOT NULL,
+    work_minutes        bigint NOT NULL DEFAULT 25,
+    short_break_minutes bigint NOT NULL DEFAULT 5,
+    long_break_minutes  bigint NOT NULL DEFAULT 15,
+    long_break_every    bigint NOT NULL DEFAULT 4,
+    phase               text NOT NULL,
+    status              text NOT NULL,
+    completed_pomodoros bigint DEFAULT 0,
+    phase_started_at    timestamptz NOT NULL,
+    phase_ends_at       timestamptz,
+    remaining_seconds   bigint DEFAULT 0,
+    time_entry_id       bigint,
+    ended_at            timestamptz,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    CONSTRAINT fk_pomodoro_sessions_activity FOREIGN KEY (activity_id) REFERENCES activities (id)
+);
+CREATE INDEX IF NOT EXISTS idx_pomodoro_sessions_status ON pomodoro_sessions (status);
+CREATE INDEX IF NOT EXISTS idx_pomodoro_sessions_activity_id ON pomodoro_sessions (activity_id);
+CREATE INDEX IF NOT EXISTS idx_pomodoro_sessions_user_id ON pomodoro_sessions (user_id);
+
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS pomodoro_session_id bigint;
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS pomodoro_completed boolean DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_time_entries_pomodoro_session_id ON time_entries (pomodoro_session_id);
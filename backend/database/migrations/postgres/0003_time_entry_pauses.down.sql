@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS time_pauses;
+
+ALTER TABLE time_entries DROP COLUMN IF EXISTS paused_seconds;
+ALTER TABLE time_entries DROP COLUMN IF EXISTS paused_at;
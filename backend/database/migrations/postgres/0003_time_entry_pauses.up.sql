@@ -0,0 +1,12 @@
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS paused_at timestamptz;
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS paused_seconds bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS time_pauses (
+    id            bigserial PRIMARY KEY,
+    time_entry_id bigint NOT NULL,
+    start_time    timestamptz NOT NULL,
+    end_time      timestamptz,
+    created_at    timestamptz,
+    CONSTRAINT fk_time_entries_pauses FOREIGN KEY (time_entry_id) REFERENCES time_entries (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_time_pauses_time_entry_id ON time_pauses (time_entry_id);
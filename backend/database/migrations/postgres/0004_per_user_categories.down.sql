@@ -0,0 +1,9 @@
+-- Fails if two users own a category or tag with the same name; merge them first
+
+DROP INDEX IF EXISTS idx_tags_user_name;
+ALTER TABLE tags ADD CONSTRAINT uni_tags_name UNIQUE (name);
+ALTER TABLE tags DROP COLUMN IF EXISTS user_id;
+
+DROP INDEX IF EXISTS idx_categories_user_name;
+ALTER TABLE categories ADD CONSTRAINT uni_categories_name UNIQUE (name);
+ALTER TABLE categories DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,10 @@
+-- Categories and tags become owned per user; rows with user_id 0 are the default templates
+-- Existing shared rows are split per user by the ownership data migration that runs afterwards
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS user_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS uni_categories_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_name ON categories (user_id, name);
+
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS user_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS uni_tags_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags (user_id, name);
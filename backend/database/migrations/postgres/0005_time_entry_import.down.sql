@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_time_entries_import_key;
+ALTER TABLE time_entries DROP COLUMN IF EXISTS import_key;
+ALTER TABLE time_entries DROP COLUMN IF EXISTS import_source;
@@ -0,0 +1,3 @@
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS import_source text;
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS import_key text;
+CREATE INDEX IF NOT EXISTS idx_time_entries_import_key ON time_entries (import_key);
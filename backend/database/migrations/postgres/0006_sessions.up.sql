@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id                 bigserial PRIMARY KEY,
+    user_id            bigint NOT NULL,
+    refresh_token_hash varchar(64) NOT NULL,
+    user_agent         varchar(255),
+    ip_address         varchar(64),
+    expires_at         timestamptz NOT NULL,
+    last_used_at       timestamptz NOT NULL,
+    revoked_at         timestamptz,
+    created_at         timestamptz,
+    updated_at         timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_revoked_at ON sessions (revoked_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_refresh_token_hash ON sessions (refresh_token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
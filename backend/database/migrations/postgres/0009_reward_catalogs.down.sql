@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reward_catalog_items;
+DROP TABLE IF EXISTS reward_catalogs;
+
+ALTER TABLE user_rewards DROP COLUMN IF EXISTS catalog;
+ALTER TABLE users DROP COLUMN IF EXISTS reward_catalog;
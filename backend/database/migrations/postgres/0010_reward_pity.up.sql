@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS reward_pity (
+    user_id           bigint PRIMARY KEY,
+    claims_since_rare bigint NOT NULL DEFAULT 0,
+    last_rare_at      timestamptz,
+    updated_at        timestamptz,
+    CONSTRAINT fk_users_reward_pity FOREIGN KEY (user_id) REFERENCES users (id)
+);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS essence_balances (
+    user_id    bigint PRIMARY KEY,
+    balance    bigint NOT NULL DEFAULT 0,
+    updated_at timestamptz,
+    CONSTRAINT fk_users_essence_balance FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT chk_essence_balances_balance CHECK (balance >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS essence_transactions (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    amount      bigint NOT NULL,
+    balance     bigint NOT NULL,
+    reason      varchar(20) NOT NULL,
+    catalog     varchar(64) NOT NULL,
+    reward_type text NOT NULL,
+    external_id text NOT NULL,
+    name        text NOT NULL,
+    created_at  timestamptz,
+    CONSTRAINT fk_users_essence_transactions FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_essence_transactions_user_id ON essence_transactions (user_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_achievements (
+    id              bigserial PRIMARY KEY,
+    user_id         bigint NOT NULL,
+    achievement_key varchar(64) NOT NULL,
+    unlocked_at     timestamptz NOT NULL,
+    CONSTRAINT fk_users_user_achievements FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_achievements_user_key ON user_achievements (user_id, achievement_key);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS streak_bonus_claims;
+DROP TABLE IF EXISTS streak_freezes;
+DROP TABLE IF EXISTS streak_states;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,30 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS streak_states (
+    user_id       bigint PRIMARY KEY,
+    min_minutes   bigint NOT NULL DEFAULT 15,
+    freeze_tokens bigint NOT NULL DEFAULT 0,
+    updated_at    timestamptz,
+    CONSTRAINT fk_users_streak_state FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT chk_streak_states_freeze_tokens CHECK (freeze_tokens >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS streak_freezes (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    day        varchar(10) NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_users_streak_freezes FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_streak_freezes_user_day ON streak_freezes (user_id, day);
+
+CREATE TABLE IF NOT EXISTS streak_bonus_claims (
+    id             bigserial PRIMARY KEY,
+    user_id        bigint NOT NULL,
+    streak_start   varchar(10) NOT NULL,
+    milestone      bigint NOT NULL,
+    user_reward_id bigint,
+    claimed_at     timestamptz NOT NULL,
+    CONSTRAINT fk_users_streak_bonus_claims FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_streak_bonus_claims_user_streak_milestone ON streak_bonus_claims (user_id, streak_start, milestone);
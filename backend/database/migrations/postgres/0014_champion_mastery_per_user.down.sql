@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_champion;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_champion ON champion_masteries (champion_id);
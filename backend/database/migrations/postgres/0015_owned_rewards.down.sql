@@ -0,0 +1,2 @@
+-- Repaired skin IDs are kept: the malformed ones cannot be told apart from valid IDs anymore
+DROP TABLE IF EXISTS owned_rewards;
@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT '';
+
+UPDATE users SET timezone = user_settings.timezone
+FROM user_settings
+WHERE user_settings.user_id = users.id;
+
+DROP TABLE IF EXISTS user_settings;
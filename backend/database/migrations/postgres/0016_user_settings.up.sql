@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id     bigint PRIMARY KEY,
+    timezone    varchar(64) NOT NULL DEFAULT '',
+    week_start  bigint NOT NULL DEFAULT 1,
+    date_format varchar(16) NOT NULL DEFAULT 'YYYY-MM-DD',
+    updated_at  timestamptz,
+    CONSTRAINT fk_users_user_settings FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT chk_user_settings_week_start CHECK (week_start BETWEEN 0 AND 6)
+);
+
+-- The timezone moves from users to the settings
+INSERT INTO user_settings (user_id, timezone, updated_at)
+SELECT id, timezone, now() FROM users WHERE timezone <> ''
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS goals (
+    id             bigserial PRIMARY KEY,
+    user_id        bigint NOT NULL,
+    name           varchar(100) NOT NULL DEFAULT '',
+    kind           varchar(16) NOT NULL,
+    period         varchar(16) NOT NULL,
+    target_minutes bigint NOT NULL,
+    scope_type     varchar(16) NOT NULL,
+    scope_id       bigint NOT NULL,
+    crossed_period varchar(10) NOT NULL DEFAULT '',
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    CONSTRAINT fk_users_goals FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals (user_id);
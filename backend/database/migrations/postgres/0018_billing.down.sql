@@ -0,0 +1,10 @@
+ALTER TABLE time_entries DROP COLUMN IF EXISTS billable;
+
+DROP INDEX IF EXISTS idx_activities_project_id;
+ALTER TABLE activities DROP CONSTRAINT IF EXISTS fk_activities_project;
+ALTER TABLE activities DROP COLUMN IF EXISTS currency;
+ALTER TABLE activities DROP COLUMN IF EXISTS hourly_rate_cents;
+ALTER TABLE activities DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS clients;
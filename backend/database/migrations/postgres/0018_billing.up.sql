@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS clients (
+    id                bigserial PRIMARY KEY,
+    user_id           bigint NOT NULL,
+    name              varchar(200) NOT NULL,
+    hourly_rate_cents bigint,
+    currency          varchar(3) NOT NULL DEFAULT 'USD',
+    rounding_minutes  bigint NOT NULL DEFAULT 0,
+    rounding_mode     varchar(8) NOT NULL DEFAULT 'up',
+    deleted_at        timestamptz,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    CONSTRAINT fk_users_clients FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_clients_user_id ON clients (user_id);
+CREATE INDEX IF NOT EXISTS idx_clients_deleted_at ON clients (deleted_at);
+
+CREATE TABLE IF NOT EXISTS projects (
+    id                bigserial PRIMARY KEY,
+    user_id           bigint NOT NULL,
+    client_id         bigint NOT NULL,
+    name              varchar(200) NOT NULL,
+    hourly_rate_cents bigint,
+    currency          varchar(3) NOT NULL DEFAULT '',
+    deleted_at        timestamptz,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    CONSTRAINT fk_users_projects FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_projects_client FOREIGN KEY (client_id) REFERENCES clients (id)
+);
+CREATE INDEX IF NOT EXISTS idx_projects_user_id ON projects (user_id);
+CREATE INDEX IF NOT EXISTS idx_projects_client_id ON projects (client_id);
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects (deleted_at);
+
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS project_id bigint;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS hourly_rate_cents bigint;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT '';
+ALTER TABLE activities ADD CONSTRAINT fk_activities_project FOREIGN KEY (project_id) REFERENCES projects (id);
+CREATE INDEX IF NOT EXISTS idx_activities_project_id ON activities (project_id);
+
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS billable boolean NOT NULL DEFAULT true;
@@ -0,0 +1,3 @@
+-- The day a claimed milestone was reached, so the claim still counts when the streak start moves forward
+ALTER TABLE streak_bonus_claims ADD COLUMN IF NOT EXISTS reached_on varchar(10) NOT NULL DEFAULT '';
+UPDATE streak_bonus_claims SET reached_on = to_char(streak_start::date + (milestone - 1), 'YYYY-MM-DD');
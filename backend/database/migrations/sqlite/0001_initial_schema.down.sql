@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS champion_masteries;
+DROP TABLE IF EXISTS user_rewards;
+DROP TABLE IF EXISTS time_pauses;
+DROP TABLE IF EXISTS time_entries;
+DROP TABLE IF EXISTS pomodoro_sessions;
+DROP TABLE IF EXISTS activity_tags;
+DROP TABLE IF EXISTS activities;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
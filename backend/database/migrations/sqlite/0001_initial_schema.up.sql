@@ -0,0 +1,192 @@
+-- Full schema for SQLite installs, equivalent to postgres migrations 0001 through 0008
+-- SQLite databases start from this baseline; later changes get their own numbered pair
+
+CREATE TABLE users (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    name          varchar(100) NOT NULL,
+    email         varchar(255) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    created_at    datetime,
+    updated_at    datetime,
+    deleted_at    datetime
+);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+CREATE TABLE categories (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL DEFAULT 0,
+    name       text NOT NULL,
+    deleted_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX idx_categories_deleted_at ON categories (deleted_at);
+CREATE UNIQUE INDEX idx_categories_user_name ON categories (user_id, name);
+
+CREATE TABLE tags (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL DEFAULT 0,
+    name       text NOT NULL,
+    deleted_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX idx_tags_deleted_at ON tags (deleted_at);
+CREATE UNIQUE INDEX idx_tags_user_name ON tags (user_id, name);
+
+CREATE TABLE activities (
+    id                 integer PRIMARY KEY AUTOINCREMENT,
+    user_id            integer NOT NULL,
+    name               text NOT NULL,
+    main_category_id   integer NOT NULL,
+    sub_category_id    integer,
+    intervals_rewarded integer DEFAULT 0,
+    deleted_at         datetime,
+    created_at         datetime,
+    updated_at         datetime,
+    CONSTRAINT fk_users_activities FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_activities_main_category FOREIGN KEY (main_category_id) REFERENCES categories (id),
+    CONSTRAINT fk_activities_sub_category FOREIGN KEY (sub_category_id) REFERENCES categories (id)
+);
+CREATE INDEX idx_activities_deleted_at ON activities (deleted_at);
+CREATE INDEX idx_activities_sub_category_id ON activities (sub_category_id);
+CREATE INDEX idx_activities_main_category_id ON activities (main_category_id);
+CREATE INDEX idx_activities_user_id ON activities (user_id);
+
+CREATE TABLE activity_tags (
+    activity_id integer,
+    tag_id      integer,
+    PRIMARY KEY (activity_id, tag_id),
+    CONSTRAINT fk_activity_tags_activity FOREIGN KEY (activity_id) REFERENCES activities (id),
+    CONSTRAINT fk_activity_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE pomodoro_sessions (
+    id                  integer PRIMARY KEY AUTOINCREMENT,
+    user_id             integer NOT NULL,
+    activity_id         integer NOT NULL,
+    work_minutes        integer NOT NULL DEFAULT 25,
+    short_break_minutes integer NOT NULL DEFAULT 5,
+    long_break_minutes  integer NOT NULL DEFAULT 15,
+    long_break_every    integer NOT NULL DEFAULT 4,
+    phase               text NOT NULL,
+    status              text NOT NULL,
+    completed_pomodoros integer DEFAULT 0,
+    phase_started_at    datetime NOT NULL,
+    phase_ends_at       datetime,
+    remaining_seconds   integer DEFAULT 0,
+    time_entry_id       integer,
+    ended_at            datetime,
+    created_at          datetime,
+    updated_at          datetime,
+    CONSTRAINT fk_pomodoro_sessions_activity FOREIGN KEY (activity_id) REFERENCES activities (id)
+);
+CREATE INDEX idx_pomodoro_sessions_status ON pomodoro_sessions (status);
+CREATE INDEX idx_pomodoro_sessions_activity_id ON pomodoro_sessions (activity_id);
+CREATE INDEX idx_pomodoro_sessions_user_id ON pomodoro_sessions (user_id);
+
+CREATE TABLE time_entries (
+    id                  integer PRIMARY KEY AUTOINCREMENT,
+    user_id             integer NOT NULL,
+    activity_id         integer NOT NULL,
+    start_time          datetime NOT NULL,
+    end_time            datetime,
+    paused_at           datetime,
+    paused_seconds      integer NOT NULL DEFAULT 0,
+    notes               text,
+    created_at          datetime,
+    pomodoro_session_id integer,
+    pomodoro_completed  numeric DEFAULT false,
+    import_source       text,
+    import_key          text,
+    CONSTRAINT fk_activities_time_entries FOREIGN KEY (activity_id) REFERENCES activities (id) ON DELETE CASCADE,
+    CONSTRAINT fk_users_time_entries FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_time_entries_import_key ON time_entries (import_key);
+CREATE INDEX idx_time_entries_pomodoro_session_id ON time_entries (pomodoro_session_id);
+CREATE INDEX idx_time_entries_end_time ON time_entries (end_time);
+CREATE INDEX idx_time_entries_start_time ON time_entries (start_time);
+CREATE INDEX idx_time_entries_activity_id ON time_entries (activity_id);
+CREATE INDEX idx_time_entries_user_id ON time_entries (user_id);
+
+CREATE TABLE time_pauses (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    time_entry_id integer NOT NULL,
+    start_time    datetime NOT NULL,
+    end_time      datetime,
+    created_at    datetime,
+    CONSTRAINT fk_time_entries_pauses FOREIGN KEY (time_entry_id) REFERENCES time_entries (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_time_pauses_time_entry_id ON time_pauses (time_entry_id);
+
+CREATE TABLE user_rewards (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    user_id     integer NOT NULL,
+    reward_type text NOT NULL,
+    external_id text NOT NULL,
+    name        text NOT NULL,
+    image_url   text NOT NULL,
+    rarity      text NOT NULL,
+    created_at  datetime,
+    CONSTRAINT fk_users_user_rewards FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_user_rewards_user_id ON user_rewards (user_id);
+
+CREATE TABLE champion_masteries (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    user_id        integer NOT NULL,
+    champion_id    text NOT NULL,
+    champion_name  text NOT NULL,
+    image_url      text NOT NULL,
+    mastery_level  integer DEFAULT 1,
+    times_obtained integer DEFAULT 1,
+    created_at     datetime,
+    updated_at     datetime,
+    CONSTRAINT fk_users_champion_mastery FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX idx_user_champion ON champion_masteries (champion_id);
+CREATE INDEX idx_champion_masteries_user_id ON champion_masteries (user_id);
+
+CREATE TABLE sessions (
+    id                 integer PRIMARY KEY AUTOINCREMENT,
+    user_id            integer NOT NULL,
+    refresh_token_hash varchar(64) NOT NULL,
+    user_agent         varchar(255),
+    ip_address         varchar(64),
+    expires_at         datetime NOT NULL,
+    last_used_at       datetime NOT NULL,
+    revoked_at         datetime,
+    created_at         datetime,
+    updated_at         datetime
+);
+CREATE INDEX idx_sessions_revoked_at ON sessions (revoked_at);
+CREATE UNIQUE INDEX idx_sessions_refresh_token_hash ON sessions (refresh_token_hash);
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE password_reset_tokens (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at datetime NOT NULL,
+    used_at    datetime,
+    created_at datetime
+);
+CREATE UNIQUE INDEX idx_password_reset_tokens_token_hash ON password_reset_tokens (token_hash);
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+
+CREATE TABLE api_tokens (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    user_id      integer NOT NULL,
+    name         varchar(100) NOT NULL,
+    token_hash   varchar(64) NOT NULL,
+    prefix       varchar(16) NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   datetime,
+    last_used_at datetime,
+    revoked_at   datetime,
+    created_at   datetime
+);
+CREATE INDEX idx_api_tokens_revoked_at ON api_tokens (revoked_at);
+CREATE UNIQUE INDEX idx_api_tokens_token_hash ON api_tokens (token_hash);
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reward_catalog_items;
+DROP TABLE IF EXISTS reward_catalogs;
+
+ALTER TABLE user_rewards DROP COLUMN catalog;
+ALTER TABLE users DROP COLUMN reward_catalog;
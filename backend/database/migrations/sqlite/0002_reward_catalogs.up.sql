@@ -0,0 +1,23 @@
+ALTER TABLE users ADD COLUMN reward_catalog varchar(64) NOT NULL DEFAULT 'lol';
+ALTER TABLE user_rewards ADD COLUMN catalog varchar(64) NOT NULL DEFAULT 'lol';
+
+CREATE TABLE reward_catalogs (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    key        varchar(64) NOT NULL,
+    name       varchar(100) NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE UNIQUE INDEX idx_reward_catalogs_key ON reward_catalogs (key);
+
+CREATE TABLE reward_catalog_items (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    catalog_id  integer NOT NULL,
+    external_id varchar(100) NOT NULL,
+    reward_type varchar(64) NOT NULL,
+    name        text NOT NULL,
+    image_url   text NOT NULL DEFAULT '',
+    rarity      text NOT NULL,
+    CONSTRAINT fk_reward_catalogs_items FOREIGN KEY (catalog_id) REFERENCES reward_catalogs (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_reward_catalog_items_catalog_external ON reward_catalog_items (catalog_id, external_id);
@@ -0,0 +1,7 @@
+CREATE TABLE reward_pity (
+    user_id           integer PRIMARY KEY,
+    claims_since_rare integer NOT NULL DEFAULT 0,
+    last_rare_at      datetime,
+    updated_at        datetime,
+    CONSTRAINT fk_users_reward_pity FOREIGN KEY (user_id) REFERENCES users (id)
+);
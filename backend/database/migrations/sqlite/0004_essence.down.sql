@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS essence_transactions;
+DROP TABLE IF EXISTS essence_balances;
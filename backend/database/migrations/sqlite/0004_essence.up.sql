@@ -0,0 +1,22 @@
+CREATE TABLE essence_balances (
+    user_id    integer PRIMARY KEY,
+    balance    integer NOT NULL DEFAULT 0,
+    updated_at datetime,
+    CONSTRAINT fk_users_essence_balance FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT chk_essence_balances_balance CHECK (balance >= 0)
+);
+
+CREATE TABLE essence_transactions (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    user_id     integer NOT NULL,
+    amount      integer NOT NULL,
+    balance     integer NOT NULL,
+    reason      varchar(20) NOT NULL,
+    catalog     varchar(64) NOT NULL,
+    reward_type text NOT NULL,
+    external_id text NOT NULL,
+    name        text NOT NULL,
+    created_at  datetime,
+    CONSTRAINT fk_users_essence_transactions FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_essence_transactions_user_id ON essence_transactions (user_id);
@@ -0,0 +1,8 @@
+CREATE TABLE user_achievements (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    user_id         integer NOT NULL,
+    achievement_key varchar(64) NOT NULL,
+    unlocked_at     datetime NOT NULL,
+    CONSTRAINT fk_users_user_achievements FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX idx_user_achievements_user_key ON user_achievements (user_id, achievement_key);
@@ -0,0 +1,30 @@
+ALTER TABLE users ADD COLUMN timezone varchar(64) NOT NULL DEFAULT '';
+
+CREATE TABLE streak_states (
+    user_id       integer PRIMARY KEY,
+    min_minutes   integer NOT NULL DEFAULT 15,
+    freeze_tokens integer NOT NULL DEFAULT 0,
+    updated_at    datetime,
+    CONSTRAINT fk_users_streak_state FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT chk_streak_states_freeze_tokens CHECK (freeze_tokens >= 0)
+);
+
+CREATE TABLE streak_freezes (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL,
+    day        varchar(10) NOT NULL,
+    created_at datetime,
+    CONSTRAINT fk_users_streak_freezes FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX idx_streak_freezes_user_day ON streak_freezes (user_id, day);
+
+CREATE TABLE streak_bonus_claims (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    user_id        integer NOT NULL,
+    streak_start   varchar(10) NOT NULL,
+    milestone      integer NOT NULL,
+    user_reward_id integer,
+    claimed_at     datetime NOT NULL,
+    CONSTRAINT fk_users_streak_bonus_claims FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX idx_streak_bonus_claims_user_streak_milestone ON streak_bonus_claims (user_id, streak_start, milestone);
@@ -0,0 +1,2 @@
+DROP INDEX idx_user_champion;
+CREATE UNIQUE INDEX idx_user_champion ON champion_masteries (champion_id);
@@ -0,0 +1,3 @@
+-- Mastery is per user: the index only covered champion_id, so two users could not own the same champion
+DROP INDEX idx_user_champion;
+CREATE UNIQUE INDEX idx_user_champion ON champion_masteries (user_id, champion_id);
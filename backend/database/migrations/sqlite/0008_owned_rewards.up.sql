@@ -0,0 +1,33 @@
+-- Skin IDs were built from the character at '0' + skin number, so skins 10 and up were
+-- stored as e.g. Ahri_: instead of Ahri_10; rebuild the number from that character
+UPDATE user_rewards
+SET external_id = substr(external_id, 1, length(external_id) - 1) || (unicode(substr(external_id, -1)) - 48)
+WHERE catalog = 'lol' AND reward_type = 'skin' AND substr(external_id, -1) NOT GLOB '[0-9]';
+
+UPDATE essence_transactions
+SET external_id = substr(external_id, 1, length(external_id) - 1) || (unicode(substr(external_id, -1)) - 48)
+WHERE catalog = 'lol' AND reward_type = 'skin' AND substr(external_id, -1) NOT GLOB '[0-9]';
+
+CREATE TABLE owned_rewards (
+    id                integer PRIMARY KEY AUTOINCREMENT,
+    user_id           integer NOT NULL,
+    catalog           varchar(64) NOT NULL,
+    reward_type       text NOT NULL,
+    external_id       text NOT NULL,
+    name              text NOT NULL,
+    image_url         text NOT NULL,
+    rarity            text NOT NULL,
+    count             integer NOT NULL DEFAULT 1,
+    first_obtained_at datetime NOT NULL,
+    last_obtained_at  datetime NOT NULL,
+    CONSTRAINT fk_users_owned_rewards FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT chk_owned_rewards_count CHECK (count > 0)
+);
+CREATE UNIQUE INDEX idx_owned_rewards_user_reward ON owned_rewards (user_id, catalog, reward_type, external_id);
+
+-- Every drop so far is a copy in the collection
+INSERT INTO owned_rewards (user_id, catalog, reward_type, external_id, name, image_url, rarity, count, first_obtained_at, last_obtained_at)
+SELECT user_id, catalog, reward_type, external_id, MAX(name), MAX(image_url), MAX(rarity), COUNT(*),
+       COALESCE(MIN(created_at), CURRENT_TIMESTAMP), COALESCE(MAX(created_at), CURRENT_TIMESTAMP)
+FROM user_rewards
+GROUP BY user_id, catalog, reward_type, external_id;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN timezone varchar(64) NOT NULL DEFAULT '';
+
+UPDATE users SET timezone = (SELECT timezone FROM user_settings WHERE user_settings.user_id = users.id)
+WHERE id IN (SELECT user_id FROM user_settings);
+
+DROP TABLE IF EXISTS user_settings;
@@ -0,0 +1,15 @@
+CREATE TABLE goals (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    user_id        integer NOT NULL,
+    name           varchar(100) NOT NULL DEFAULT '',
+    kind           varchar(16) NOT NULL,
+    period         varchar(16) NOT NULL,
+    target_minutes integer NOT NULL,
+    scope_type     varchar(16) NOT NULL,
+    scope_id       integer NOT NULL,
+    crossed_period varchar(10) NOT NULL DEFAULT '',
+    created_at     datetime,
+    updated_at     datetime,
+    CONSTRAINT fk_users_goals FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_goals_user_id ON goals (user_id);
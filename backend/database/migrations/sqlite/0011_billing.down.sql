@@ -0,0 +1,9 @@
+ALTER TABLE time_entries DROP COLUMN billable;
+
+DROP INDEX IF EXISTS idx_activities_project_id;
+ALTER TABLE activities DROP COLUMN currency;
+ALTER TABLE activities DROP COLUMN hourly_rate_cents;
+ALTER TABLE activities DROP COLUMN project_id;
+
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS clients;
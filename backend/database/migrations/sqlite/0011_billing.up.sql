@@ -0,0 +1,40 @@
+CREATE TABLE clients (
+    id                integer PRIMARY KEY AUTOINCREMENT,
+    user_id           integer NOT NULL,
+    name              varchar(200) NOT NULL,
+    hourly_rate_cents integer,
+    currency          varchar(3) NOT NULL DEFAULT 'USD',
+    rounding_minutes  integer NOT NULL DEFAULT 0,
+    rounding_mode     varchar(8) NOT NULL DEFAULT 'up',
+    deleted_at        datetime,
+    created_at        datetime,
+    updated_at        datetime,
+    CONSTRAINT fk_users_clients FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_clients_user_id ON clients (user_id);
+CREATE INDEX idx_clients_deleted_at ON clients (deleted_at);
+
+CREATE TABLE projects (
+    id                integer PRIMARY KEY AUTOINCREMENT,
+    user_id           integer NOT NULL,
+    client_id         integer NOT NULL,
+    name              varchar(200) NOT NULL,
+    hourly_rate_cents integer,
+    currency          varchar(3) NOT NULL DEFAULT '',
+    deleted_at        datetime,
+    created_at        datetime,
+    updated_at        datetime,
+    CONSTRAINT fk_users_projects FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_projects_client FOREIGN KEY (client_id) REFERENCES clients (id)
+);
+CREATE INDEX idx_projects_user_id ON projects (user_id);
+CREATE INDEX idx_projects_client_id ON projects (client_id);
+CREATE INDEX idx_projects_deleted_at ON projects (deleted_at);
+
+-- No foreign key on project_id, SQLite could not drop the column again; the API checks the project
+ALTER TABLE activities ADD COLUMN project_id integer;
+ALTER TABLE activities ADD COLUMN hourly_rate_cents integer;
+ALTER TABLE activities ADD COLUMN currency varchar(3) NOT NULL DEFAULT '';
+CREATE INDEX idx_activities_project_id ON activities (project_id);
+
+ALTER TABLE time_entries ADD COLUMN billable numeric NOT NULL DEFAULT true;
@@ -0,0 +1 @@
+ALTER TABLE streak_bonus_claims DROP COLUMN reached_on;
@@ -0,0 +1,3 @@
+-- The day a claimed milestone was reached, so the claim still counts when the streak start moves forward
+ALTER TABLE streak_bonus_claims ADD COLUMN reached_on varchar(10) NOT NULL DEFAULT '';
+UPDATE streak_bonus_claims SET reached_on = date(streak_start, '+' || (milestone - 1) || ' days');
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrateSharedOwnership splits categories and tags that were shared between all users
+// Before per-user scoping every row had UserID 0. Each user whose activities reference
+// a shared row gets an owned copy and their activities are pointed at it. Shared rows
+// are then dropped so only the seeded defaults remain as UserID 0 templates.
+// Returns true if a split happened, so defaults can be handed out afterwards.
+func migrateSharedOwnership(logger *zap.Logger) (bool, error) {
+	var referencedCategories, referencedTags int64
+
+	DB.Model(&models.Activity{}).
+		Joins("JOIN categories ON categories.id = activities.main_category_id OR categories.id = activities.sub_category_id").
+		Where("categories.user_id = 0").
+		Count(&referencedCategories)
+
+	DB.Table("activity_tags").
+		Joins("JOIN tags ON tags.id = activity_tags.tag_id").
+		Where("tags.user_id = 0").
+		Count(&referencedTags)
+
+	if referencedCategories == 0 && referencedTags == 0 {
+		return false, nil
+	}
+
+	logger.Info("Splitting shared categories and tags per user...")
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := splitSharedCategories(tx); err != nil {
+			return fmt.Errorf("failed to split categories: %w", err)
+		}
+		if err := splitSharedTags(tx); err != nil {
+			return fmt.Errorf("failed to split tags: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Ownership migration failed", zap.Error(err))
+		return false, err
+	}
+
+	logger.Info("Shared categories and tags split per user")
+	return true, nil
+}
+
+// splitSharedCategories gives every referencing user an owned copy of each shared category
+func splitSharedCategories(tx *gorm.DB) error {
+	var shared []models.Category
+	if err := tx.Where("user_id = 0").Find(&shared).Error; err != nil {
+		return err
+	}
+
+	for _, category := range shared {
+		var userIDs []uint
+		if err := tx.Model(&models.Activity{}).
+			Where("main_category_id = ? OR sub_category_id = ?", category.ID, category.ID).
+			Distinct().
+			Pluck("user_id", &userIDs).Error; err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			owned := models.Category{UserID: userID, Name: category.Name, DeletedAt: category.DeletedAt}
+			if err := tx.Where("user_id = ? AND name = ?", userID, category.Name).FirstOrCreate(&owned).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.Activity{}).
+				Where("user_id = ? AND main_category_id = ?", userID, category.ID).
+				Update("main_category_id", owned.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Activity{}).
+				Where("user_id = ? AND sub_category_id = ?", userID, category.ID).
+				Update("sub_category_id", owned.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// Shared rows are no longer referenced; seedData recreates the default templates
+	return tx.Where("user_id = 0").Delete(&models.Category{}).Error
+}
+
+// splitSharedTags gives every referencing user an owned copy of each shared tag
+func splitSharedTags(tx *gorm.DB) error {
+	var shared []models.Tag
+	if err := tx.Where("user_id = 0").Find(&shared).Error; err != nil {
+		return err
+	}
+
+	for _, tag := range shared {
+		var userIDs []uint
+		if err := tx.Table("activity_tags").
+			Joins("JOIN activities ON activities.id = activity_tags.activity_id").
+			Where("activity_tags.tag_id = ?", tag.ID).
+			Distinct().
+			Pluck("activities.user_id", &userIDs).Error; err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			owned := models.Tag{UserID: userID, Name: tag.Name, DeletedAt: tag.DeletedAt}
+			if err := tx.Where("user_id = ? AND name = ?", userID, tag.Name).FirstOrCreate(&owned).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`
+				UPDATE activity_tags SET tag_id = ?
+				WHERE tag_id = ? AND activity_id IN (SELECT id FROM activities WHERE user_id = ?)
+			`, owned.ID, tag.ID, userID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// Tags have no defaults, so every shared row goes away
+	return tx.Where("user_id = 0").Delete(&models.Tag{}).Error
+}
+
+// copyDefaultsToUsersWithoutCategories hands the default categories to users that own none
+func copyDefaultsToUsersWithoutCategories(logger *zap.Logger) error {
+	var userIDs []uint
+	if err := DB.Model(&models.User{}).
+		Where("NOT EXISTS (SELECT 1 FROM categories WHERE categories.user_id = users.id)").
+		Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := services.CopyDefaultCategories(DB, userID); err != nil {
+			logger.Error("Failed to copy default categories", zap.Uint("user_id", userID), zap.Error(err))
+			return err
+		}
+	}
+
+	logger.Info("Default categories copied", zap.Int("users", len(userIDs)))
+	return nil
+}
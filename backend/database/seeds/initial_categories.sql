@@ -1,5 +1,6 @@
 -- Initial categories for the time tracker
 -- These categories will be seeded when the database is first initialized
+-- They belong to no user (user_id defaults to 0) and are copied to each user on registration
 
 INSERT INTO categories (name, created_at, updated_at) VALUES
 ('Work', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
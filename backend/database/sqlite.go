@@ -0,0 +1,29 @@
+package database
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// OpenSQLite opens a SQLite database file, or a private in-memory database for ":memory:"
+// Times are stored in SQLite's own text format so its date functions can do duration math
+func OpenSQLite(dbPath string, config *gorm.Config) (*gorm.DB, error) {
+	dsn := "file:" + dbPath + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+	if dbPath != ":memory:" {
+		dsn += "&_pragma=journal_mode(WAL)"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dsn), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite has a single writer, and every connection to ":memory:" would get its own empty database
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return db, nil
+}
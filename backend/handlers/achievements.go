@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type AchievementHandler struct {
+	Logger *zap.Logger
+	Events *services.EventHub
+	Repos  *repository.Repositories
+}
+
+// GetAchievements returns every achievement with the user's progress towards it
+// It evaluates them first, so history tracked before an achievement existed still unlocks it
+func (h *AchievementHandler) GetAchievements(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+
+	progress, err := services.GetAchievementProgress(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch achievements", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch achievements")
+		return
+	}
+
+	unlocked := 0
+	for _, achievement := range progress {
+		if achievement.Unlocked {
+			unlocked++
+		}
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"achievements": progress,
+		"unlocked":     unlocked,
+		"total":        len(progress),
+	})
+}
+
+// unlockAchievements evaluates the user's achievements and announces the newly unlocked ones
+// Failures are only logged so they never fail the request that triggered the evaluation
+func unlockAchievements(logger *zap.Logger, events *services.EventHub, repos *repository.Repositories, userID uint) []services.AchievementDefinition {
+	unlocked, err := services.EvaluateAchievements(repos.DB, userID)
+	if err != nil {
+		logger.Error("Failed to evaluate achievements", zap.Uint("user_id", userID), zap.Error(err))
+		return nil
+	}
+
+	for _, achievement := range unlocked {
+		events.Publish(userID, services.EventAchievementUnlocked, achievement)
+	}
+	return unlocked
+}
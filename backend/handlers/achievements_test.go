@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+func TestGetAchievementsUnlocksFromHistory(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &AchievementHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	// 20 minutes at noon on each of the last 7 days, spread over 5 categories
+	var activities []*models.Activity
+	for i := 0; i < 5; i++ {
+		activities = append(activities, createTestActivity(t, repos, user.ID, fmt.Sprintf("Activity %d", i)))
+	}
+
+	today := time.Now()
+	for day := 1; day <= 7; day++ {
+		activity := activities[day%len(activities)]
+		noon := time.Date(today.Year(), today.Month(), today.Day()-day, 12, 0, 0, 0, time.Local)
+		createTestEntry(t, repos, activity, noon, noon.Add(20*time.Minute), 0)
+	}
+
+	body := decodeResponse(t, serve(t, h.GetAchievements, http.MethodGet, "/achievements", "/achievements", nil, user.ID), http.StatusOK)
+
+	achievements := make(map[string]map[string]interface{})
+	for _, item := range body["achievements"].([]interface{}) {
+		achievement := item.(map[string]interface{})
+		achievements[achievement["key"].(string)] = achievement
+	}
+	if len(achievements) != len(services.Achievements) {
+		t.Fatalf("got %d achievements, want %d", len(achievements), len(services.Achievements))
+	}
+
+	for _, key := range []string{"first_hour", "streak_7", "categories_5"} {
+		if achievements[key]["unlocked"] != true || achievements[key]["unlocked_at"] == nil {
+			t.Errorf("%s = %v, want unlocked", key, achievements[key])
+		}
+	}
+	for _, key := range []string{"hours_100", "streak_30", "mastery_7"} {
+		if achievements[key]["unlocked"] != false {
+			t.Errorf("%s = %v, want locked", key, achievements[key])
+		}
+	}
+	if achievements["hours_100"]["current"] != float64(2) || achievements["streak_30"]["current"] != float64(7) {
+		t.Errorf("progress = %v / %v, want 2 hours and a 7 day streak",
+			achievements["hours_100"]["current"], achievements["streak_30"]["current"])
+	}
+
+	// Unlocks are only reported once
+	unlocked, err := services.EvaluateAchievements(repos.DB, user.ID)
+	if err != nil || len(unlocked) != 0 {
+		t.Errorf("evaluate again = %v, %v; want nothing new", unlocked, err)
+	}
+}
+
+func TestStopTimerUnlocksAchievements(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Deep work")
+
+	running := models.TimeEntry{UserID: user.ID, ActivityID: activity.ID, StartTime: time.Now().Add(-61 * time.Minute)}
+	if err := repos.TimeEntries.Create(&running); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+
+	body := decodeResponse(t, serve(t, h.StopTimer, http.MethodPost, "/time-entries/stop", "/time-entries/stop", nil, user.ID), http.StatusOK)
+	unlocked := body["achievements_unlocked"].([]interface{})
+	if len(unlocked) != 1 || unlocked[0].(map[string]interface{})["key"] != "first_hour" {
+		t.Errorf("achievements_unlocked = %v, want first_hour", unlocked)
+	}
+}
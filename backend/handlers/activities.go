@@ -1,13 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/Felipalds/go-pomodoro/database"
 	"github.com/Felipalds/go-pomodoro/middleware"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/Felipalds/go-pomodoro/utils"
 	"github.com/go-chi/chi/v5"
@@ -16,6 +18,8 @@ import (
 
 type ActivityHandler struct {
 	Logger *zap.Logger
+	Events *services.EventHub
+	Repos  *repository.Repositories
 }
 
 // CreateActivityInput represents the input for creating an activity
@@ -57,7 +61,7 @@ func (h *ActivityHandler) CreateActivity(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Find or create main category
-	mainCategory, err := services.FindOrCreateCategory(database.DB, input.MainCategoryName)
+	mainCategory, err := services.FindOrCreateCategory(h.Repos.DB, userID, input.MainCategoryName)
 	if err != nil {
 		h.Logger.Error("Failed to find/create main category", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process main category")
@@ -67,7 +71,7 @@ func (h *ActivityHandler) CreateActivity(w http.ResponseWriter, r *http.Request)
 	// Find or create sub category (if provided)
 	var subCategoryID *uint
 	if input.SubCategoryName != nil && *input.SubCategoryName != "" {
-		subCategory, err := services.FindOrCreateCategory(database.DB, *input.SubCategoryName)
+		subCategory, err := services.FindOrCreateCategory(h.Repos.DB, userID, *input.SubCategoryName)
 		if err != nil {
 			h.Logger.Error("Failed to find/create sub category", zap.Error(err))
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process sub category")
@@ -84,32 +88,16 @@ func (h *ActivityHandler) CreateActivity(w http.ResponseWriter, r *http.Request)
 		SubCategoryID:  subCategoryID,
 	}
 
-	if err := database.DB.Create(&activity).Error; err != nil {
+	// Find or create tags to associate with the activity
+	tags := h.findOrCreateTags(userID, input.TagNames)
+
+	if err := h.Repos.Activities.Create(&activity, tags); err != nil {
 		h.Logger.Error("Failed to create activity", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create activity")
 		return
 	}
 
-	// Find or create tags and associate them
-	var tags []models.Tag
-	for _, tagName := range input.TagNames {
-		tag, err := services.FindOrCreateTag(database.DB, tagName)
-		if err != nil {
-			h.Logger.Error("Failed to find/create tag", zap.String("tag", tagName), zap.Error(err))
-			continue // Skip this tag but continue with others
-		}
-		tags = append(tags, *tag)
-	}
-
-	// Associate tags with activity
-	if len(tags) > 0 {
-		if err := database.DB.Model(&activity).Association("Tags").Append(&tags); err != nil {
-			h.Logger.Error("Failed to associate tags", zap.Error(err))
-		}
-	}
-
-	// Load the complete activity with relationships
-	database.DB.Preload("MainCategory").Preload("SubCategory").Preload("Tags").First(&activity, activity.ID)
+	h.Events.Publish(userID, services.EventActivityCreated, activity)
 
 	utils.CreatedResponse(w, activity)
 }
@@ -117,15 +105,10 @@ func (h *ActivityHandler) CreateActivity(w http.ResponseWriter, r *http.Request)
 // GetActivities returns all active activities
 func (h *ActivityHandler) GetActivities(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r)
-	var activities []models.Activity
 
 	// Get all activities that are not deleted, with relationships
-	if err := database.DB.
-		Preload("MainCategory").
-		Preload("SubCategory").
-		Preload("Tags").
-		Where("user_id = ? AND deleted_at IS NULL", userID).
-		Find(&activities).Error; err != nil {
+	activities, err := h.Repos.Activities.List(userID)
+	if err != nil {
 		h.Logger.Error("Failed to fetch activities", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch activities")
 		return
@@ -146,13 +129,8 @@ func (h *ActivityHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var activity models.Activity
-	if err := database.DB.
-		Preload("MainCategory").
-		Preload("SubCategory").
-		Preload("Tags").
-		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).
-		First(&activity).Error; err != nil {
+	activity, err := h.Repos.Activities.FindWithDetails(userID, uint(id))
+	if err != nil {
 		h.Logger.Error("Activity not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
@@ -183,8 +161,8 @@ func (h *ActivityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var activity models.Activity
-	if err := database.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&activity).Error; err != nil {
+	activity, err := h.Repos.Activities.Find(userID, uint(id))
+	if err != nil {
 		h.Logger.Error("Activity not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
@@ -195,7 +173,7 @@ func (h *ActivityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 
 	// Find or create main category
 	if input.MainCategoryName != "" {
-		mainCategory, err := services.FindOrCreateCategory(database.DB, input.MainCategoryName)
+		mainCategory, err := services.FindOrCreateCategory(h.Repos.DB, userID, input.MainCategoryName)
 		if err != nil {
 			h.Logger.Error("Failed to find/create main category", zap.Error(err))
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process main category")
@@ -206,7 +184,7 @@ func (h *ActivityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 
 	// Find or create sub category (if provided)
 	if input.SubCategoryName != nil && *input.SubCategoryName != "" {
-		subCategory, err := services.FindOrCreateCategory(database.DB, *input.SubCategoryName)
+		subCategory, err := services.FindOrCreateCategory(h.Repos.DB, userID, *input.SubCategoryName)
 		if err != nil {
 			h.Logger.Error("Failed to find/create sub category", zap.Error(err))
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to process sub category")
@@ -217,36 +195,86 @@ func (h *ActivityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 		activity.SubCategoryID = nil
 	}
 
+	// Update tags - replace all existing tags when tag names are given
+	var tags []models.Tag
+	if input.TagNames != nil {
+		tags = h.findOrCreateTags(userID, input.TagNames)
+		if tags == nil {
+			tags = []models.Tag{}
+		}
+	}
+
 	// Save the activity
-	if err := database.DB.Save(&activity).Error; err != nil {
+	if err := h.Repos.Activities.Update(activity, tags); err != nil {
 		h.Logger.Error("Failed to update activity", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update activity")
 		return
 	}
 
-	// Update tags - replace all existing tags
-	if input.TagNames != nil {
-		// Clear existing tags
-		database.DB.Model(&activity).Association("Tags").Clear()
-
-		// Add new tags
-		var tags []models.Tag
-		for _, tagName := range input.TagNames {
-			tag, err := services.FindOrCreateTag(database.DB, tagName)
-			if err != nil {
-				h.Logger.Error("Failed to find/create tag", zap.String("tag", tagName), zap.Error(err))
-				continue
-			}
-			tags = append(tags, *tag)
-		}
+	h.Events.Publish(userID, services.EventActivityUpdated, activity)
 
-		if len(tags) > 0 {
-			database.DB.Model(&activity).Association("Tags").Append(&tags)
-		}
+	utils.SuccessResponse(w, activity)
+}
+
+// ActivityBillingInput represents the project, rate and currency of an activity
+// A left out project leaves the activity unbilled; a left out rate or currency falls back to the project's
+type ActivityBillingInput struct {
+	ProjectID       *uint  `json:"project_id"`
+	HourlyRateCents *int64 `json:"hourly_rate_cents"`
+	Currency        string `json:"currency"`
+}
+
+// UpdateActivityBilling replaces the project an activity is billed to and its own rate
+func (h *ActivityHandler) UpdateActivityBilling(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid activity ID")
+		return
 	}
 
-	// Reload with relationships
-	database.DB.Preload("MainCategory").Preload("SubCategory").Preload("Tags").First(&activity, activity.ID)
+	var input ActivityBillingInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	activity, err := h.Repos.Activities.Find(userID, uint(id))
+	if err != nil {
+		h.Logger.Error("Activity not found", zap.Uint64("id", id), zap.Error(err))
+		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
+		return
+	}
+
+	activity.ProjectID = input.ProjectID
+	activity.HourlyRateCents = input.HourlyRateCents
+	activity.Currency = strings.ToUpper(input.Currency)
+
+	if err := activity.ValidateBilling(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = services.SetActivityBilling(h.Repos.DB, activity)
+	if errors.Is(err, services.ErrProjectNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to update activity billing", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update activity billing")
+		return
+	}
+
+	activity, err = h.Repos.Activities.FindWithDetails(userID, activity.ID)
+	if err != nil {
+		h.Logger.Error("Failed to reload activity", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update activity billing")
+		return
+	}
+
+	h.Events.Publish(userID, services.EventActivityUpdated, activity)
 
 	utils.SuccessResponse(w, activity)
 }
@@ -261,20 +289,22 @@ func (h *ActivityHandler) DeleteActivity(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var activity models.Activity
-	if err := database.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&activity).Error; err != nil {
+	activity, err := h.Repos.Activities.Find(userID, uint(id))
+	if err != nil {
 		h.Logger.Error("Activity not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
 	}
 
 	// Soft delete
-	if err := database.DB.Delete(&activity).Error; err != nil {
+	if err := h.Repos.Activities.Delete(activity); err != nil {
 		h.Logger.Error("Failed to delete activity", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete activity")
 		return
 	}
 
+	h.Events.Publish(userID, services.EventActivityDeleted, map[string]interface{}{"id": activity.ID})
+
 	utils.SuccessResponse(w, map[string]string{
 		"message": "Activity deleted successfully",
 	})
@@ -290,19 +320,23 @@ func (h *ActivityHandler) GetActivityTime(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	var activity models.Activity
-	if err := database.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&activity).Error; err != nil {
+	activity, err := h.Repos.Activities.Find(userID, uint(id))
+	if err != nil {
 		h.Logger.Error("Activity not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
 	}
 
 	// Get all time entries for this activity
-	var entries []models.TimeEntry
-	database.DB.Where("activity_id = ?", id).Order("start_time DESC").Find(&entries)
+	entries, err := h.Repos.TimeEntries.ListByActivity(activity.ID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch time entries", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch time entries")
+		return
+	}
 
 	// Calculate statistics
-	stats, err := services.GetActivityStats(database.DB, uint(id))
+	stats, err := services.GetActivityStats(h.Repos.DB, activity.ID)
 	if err != nil {
 		h.Logger.Error("Failed to get activity stats", zap.Error(err))
 		stats = &services.ActivityStats{TotalSeconds: 0, EntryCount: 0}
@@ -327,7 +361,7 @@ func (h *ActivityHandler) GetActivityTime(w http.ResponseWriter, r *http.Request
 		}
 
 		if entry.EndTime != nil {
-			duration := utils.CalculateDuration(entry.StartTime, *entry.EndTime)
+			duration := entry.DurationSeconds(*entry.EndTime)
 			formatted.DurationSeconds = &duration
 		}
 
@@ -347,15 +381,10 @@ func (h *ActivityHandler) GetActivityTime(w http.ResponseWriter, r *http.Request
 // GetActivitiesStats returns all activities with their time statistics
 func (h *ActivityHandler) GetActivitiesStats(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r)
-	var activities []models.Activity
 
 	// Get all active activities with relationships
-	if err := database.DB.
-		Preload("MainCategory").
-		Preload("SubCategory").
-		Preload("Tags").
-		Where("user_id = ? AND deleted_at IS NULL", userID).
-		Find(&activities).Error; err != nil {
+	activities, err := h.Repos.Activities.List(userID)
+	if err != nil {
 		h.Logger.Error("Failed to fetch activities", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch activities")
 		return
@@ -363,16 +392,13 @@ func (h *ActivityHandler) GetActivitiesStats(w http.ResponseWriter, r *http.Requ
 
 	var result []ActivityWithStats
 	for _, activity := range activities {
-		stats, _ := services.GetActivityStats(database.DB, activity.ID)
+		stats, _ := services.GetActivityStats(h.Repos.DB, activity.ID)
 		if stats == nil {
 			stats = &services.ActivityStats{TotalSeconds: 0, EntryCount: 0}
 		}
 
 		// Get last tracked time
-		var lastEntry models.TimeEntry
-		err := database.DB.Where("activity_id = ? AND end_time IS NOT NULL", activity.ID).
-			Order("end_time DESC").
-			First(&lastEntry).Error
+		lastEntry, err := h.Repos.TimeEntries.LastStopped(activity.ID)
 
 		activityStats := ActivityWithStats{
 			Activity:       activity,
@@ -392,3 +418,18 @@ func (h *ActivityHandler) GetActivitiesStats(w http.ResponseWriter, r *http.Requ
 		"activities": result,
 	})
 }
+
+// findOrCreateTags resolves tag names to the user's tags, creating missing ones
+// Names that can't be resolved are logged and skipped
+func (h *ActivityHandler) findOrCreateTags(userID uint, names []string) []models.Tag {
+	var tags []models.Tag
+	for _, tagName := range names {
+		tag, err := services.FindOrCreateTag(h.Repos.DB, userID, tagName)
+		if err != nil {
+			h.Logger.Error("Failed to find/create tag", zap.String("tag", tagName), zap.Error(err))
+			continue // Skip this tag but continue with others
+		}
+		tags = append(tags, *tag)
+	}
+	return tags
+}
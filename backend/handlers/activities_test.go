@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"go.uber.org/zap"
+)
+
+func TestCreateActivityWithCategoriesAndTags(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	subCategory := "Backend"
+	body := decodeResponse(t, serve(t, h.CreateActivity, http.MethodPost, "/activities", "/activities", CreateActivityInput{
+		Name:             "Write tests",
+		MainCategoryName: "Work",
+		SubCategoryName:  &subCategory,
+		TagNames:         []string{"focus", "go", "focus"},
+	}, user.ID), http.StatusCreated)
+
+	if got := body["main_category"].(map[string]interface{})["name"]; got != "Work" {
+		t.Errorf("main category = %v, want Work", got)
+	}
+	if got := body["sub_category"].(map[string]interface{})["name"]; got != "Backend" {
+		t.Errorf("sub category = %v, want Backend", got)
+	}
+	if tags := body["tags"].([]interface{}); len(tags) != 2 {
+		t.Errorf("got %d tags, want 2", len(tags))
+	}
+}
+
+func TestUpdateActivityReplacesTags(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	created := decodeResponse(t, serve(t, h.CreateActivity, http.MethodPost, "/activities", "/activities", CreateActivityInput{
+		Name:             "Reading",
+		MainCategoryName: "Study",
+		TagNames:         []string{"books"},
+	}, user.ID), http.StatusCreated)
+	target := fmt.Sprintf("/activities/%v", created["id"])
+
+	body := decodeResponse(t, serve(t, h.UpdateActivity, http.MethodPut, "/activities/{id}", target, CreateActivityInput{
+		Name:     "Reading papers",
+		TagNames: []string{"papers", "research"},
+	}, user.ID), http.StatusOK)
+
+	if body["name"] != "Reading papers" {
+		t.Errorf("name = %v, want Reading papers", body["name"])
+	}
+	tags := body["tags"].([]interface{})
+	if len(tags) != 2 {
+		t.Fatalf("got %d tags, want 2", len(tags))
+	}
+	for _, tag := range tags {
+		if name := tag.(map[string]interface{})["name"]; name == "books" {
+			t.Errorf("old tag books was kept")
+		}
+	}
+
+	// An empty list clears every tag
+	body = decodeResponse(t, serve(t, h.UpdateActivity, http.MethodPut, "/activities/{id}", target, CreateActivityInput{
+		Name:     "Reading papers",
+		TagNames: []string{},
+	}, user.ID), http.StatusOK)
+	if tags, _ := body["tags"].([]interface{}); len(tags) != 0 {
+		t.Errorf("got %d tags after clearing, want 0", len(tags))
+	}
+}
+
+func TestDeleteActivityKeepsTrackedTime(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Gym")
+
+	end := time.Now().Add(-time.Hour)
+	entry := createTestEntry(t, repos, activity, end.Add(-30*time.Minute), end, 0)
+	target := fmt.Sprintf("/activities/%d", activity.ID)
+
+	decodeResponse(t, serve(t, h.DeleteActivity, http.MethodDelete, "/activities/{id}", target, nil, user.ID), http.StatusOK)
+	decodeResponse(t, serve(t, h.GetActivity, http.MethodGet, "/activities/{id}", target, nil, user.ID), http.StatusNotFound)
+
+	var count int64
+	repos.DB.Model(&models.TimeEntry{}).Where("id = ?", entry.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("time entry of the deleted activity was removed")
+	}
+
+	body := decodeResponse(t, serve(t, h.GetActivities, http.MethodGet, "/activities", "/activities", nil, user.ID), http.StatusOK)
+	if activities, _ := body["activities"].([]interface{}); len(activities) != 0 {
+		t.Errorf("deleted activity is still listed")
+	}
+}
+
+func TestActivitiesAreScopedToTheirUser(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	owner := createTestUser(t, repos, "owner@example.com")
+	other := createTestUser(t, repos, "other@example.com")
+	activity := createTestActivity(t, repos, owner.ID, "Private")
+	target := fmt.Sprintf("/activities/%d", activity.ID)
+
+	decodeResponse(t, serve(t, h.GetActivity, http.MethodGet, "/activities/{id}", target, nil, other.ID), http.StatusNotFound)
+	decodeResponse(t, serve(t, h.DeleteActivity, http.MethodDelete, "/activities/{id}", target, nil, other.ID), http.StatusNotFound)
+	decodeResponse(t, serve(t, h.GetActivity, http.MethodGet, "/activities/{id}", target, nil, owner.ID), http.StatusOK)
+}
+
+func TestGetActivitiesStatsExcludesPausedTime(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Coding")
+
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-40*time.Minute), end, 10*60)
+
+	body := decodeResponse(t, serve(t, h.GetActivitiesStats, http.MethodGet, "/activities/stats", "/activities/stats", nil, user.ID), http.StatusOK)
+	stats := body["activities"].([]interface{})[0].(map[string]interface{})
+	if stats["total_seconds"] != float64(30*60) {
+		t.Errorf("total_seconds = %v, want %d", stats["total_seconds"], 30*60)
+	}
+	if stats["last_tracked"] == nil {
+		t.Errorf("last_tracked is empty")
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type AnalyticsHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// GetAnalytics returns a weekday by hour heatmap of tracked time and session statistics for a date range
+// Query params: from, to, activity_id, category_id, tag_id as for reports, and tz (defaults to the user's timezone)
+func (h *AnalyticsHandler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	query := r.URL.Query()
+
+	settings, ok := userSettings(w, h.Logger, h.Repos.DB, userID)
+	if !ok {
+		return
+	}
+
+	loc, ok := parseTimezone(w, query, settings.Location())
+	if !ok {
+		return
+	}
+
+	filter, ok := parseReportFilter(w, query, loc)
+	if !ok {
+		return
+	}
+	filter.WeekStart = settings.WeekStart
+
+	analytics, err := services.BuildAnalytics(h.Repos.DB, userID, filter)
+	if err != nil {
+		h.Logger.Error("Failed to build analytics", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build analytics")
+		return
+	}
+
+	utils.SuccessResponse(w, analytics)
+}
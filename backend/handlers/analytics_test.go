@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"go.uber.org/zap"
+)
+
+func TestGetAnalyticsSplitsEntriesByLocalHour(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &AnalyticsHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	settings := models.DefaultUserSettings(user.ID)
+	settings.Timezone = "Asia/Kolkata"
+	if err := repos.Users.SaveSettings(&settings); err != nil {
+		t.Fatalf("set timezone: %v", err)
+	}
+	coding := createTestActivity(t, repos, user.ID, "Coding")
+	reading := createTestActivity(t, repos, user.ID, "Reading")
+
+	// Monday 23:30 to Tuesday 01:15 in a zone offset by half an hour, then 20 tracked minutes on Wednesday
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	start := time.Date(2026, 3, 2, 23, 30, 0, 0, kolkata)
+	createTestEntry(t, repos, coding, start, start.Add(105*time.Minute), 0)
+	wednesday := time.Date(2026, 3, 4, 10, 0, 0, 0, kolkata)
+	createTestEntry(t, repos, reading, wednesday, wednesday.Add(30*time.Minute), 10*60)
+
+	get := func(query string) map[string]interface{} {
+		return decodeResponse(t, serve(t, h.GetAnalytics, http.MethodGet, "/analytics", "/analytics?"+query, nil, user.ID), http.StatusOK)
+	}
+	cell := func(body map[string]interface{}, weekday time.Weekday, hour int) float64 {
+		return body["heatmap"].([]interface{})[weekday].([]interface{})[hour].(float64)
+	}
+
+	body := get("from=2026-03-02&to=2026-03-08")
+	if body["timezone"] != "Asia/Kolkata" {
+		t.Errorf("timezone = %v, want the user's", body["timezone"])
+	}
+	for _, want := range []struct {
+		weekday time.Weekday
+		hour    int
+		seconds float64
+	}{{time.Monday, 23, 1800}, {time.Tuesday, 0, 3600}, {time.Tuesday, 1, 900}, {time.Wednesday, 10, 1200}} {
+		if got := cell(body, want.weekday, want.hour); got != want.seconds {
+			t.Errorf("%s %02d:00 = %v, want %v", want.weekday, want.hour, got, want.seconds)
+		}
+	}
+	if body["total_seconds"] != float64(7500) || body["session_count"] != float64(2) || body["average_session_seconds"] != float64(3750) {
+		t.Errorf("totals = %v / %v / %v, want 7500 seconds over 2 sessions of 3750 on average",
+			body["total_seconds"], body["session_count"], body["average_session_seconds"])
+	}
+	if longest := body["longest_session"].(map[string]interface{}); longest["seconds"] != float64(6300) || longest["activity_name"] != "Coding" {
+		t.Errorf("longest_session = %v, want the 105 minutes of coding", longest)
+	}
+	if body["active_days"] != float64(3) || body["median_daily_seconds"] != float64(1800) {
+		t.Errorf("daily = %v days with a median of %v, want 3 and 1800", body["active_days"], body["median_daily_seconds"])
+	}
+
+	// Only the part of an entry inside the range counts towards the heatmap
+	body = get("from=2026-03-03&to=2026-03-08")
+	if cell(body, time.Monday, 23) != 0 || body["total_seconds"] != float64(5700) {
+		t.Errorf("clipped total = %v, want 5700 without Monday", body["total_seconds"])
+	}
+
+	body = get("from=2026-03-02&to=2026-03-08&tz=UTC&activity_id=" + strconv.Itoa(int(reading.ID)))
+	if body["total_seconds"] != float64(1200) || cell(body, time.Wednesday, 4) != 1200 {
+		t.Errorf("filtered analytics = %v, want Reading only, at 04:00 UTC", body)
+	}
+
+	decodeResponse(t, serve(t, h.GetAnalytics, http.MethodGet, "/analytics", "/analytics?from=2026-03-02", nil, user.ID), http.StatusBadRequest)
+}
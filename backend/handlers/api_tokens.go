@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type APITokenHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// CreateAPITokenInput represents the request body for creating a personal API token
+type CreateAPITokenInput struct {
+	Name      string              `json:"name"`
+	Scopes    []models.TokenScope `json:"scopes"`
+	ExpiresAt *time.Time          `json:"expires_at"` // optional, never expires if omitted
+}
+
+// GetAPITokens lists the user's active personal API tokens
+func (h *APITokenHandler) GetAPITokens(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	tokens, err := services.ListAPITokens(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch API tokens", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch API tokens")
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, formatAPIToken(&tokens[i]))
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"tokens": result,
+	})
+}
+
+// CreateAPIToken creates a personal API token
+// The token value is only included in this response and cannot be retrieved again
+func (h *APITokenHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input CreateAPITokenInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if len(input.Name) == 0 || len(input.Name) > 100 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Token name must be 1-100 characters")
+		return
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	apiToken, token, err := services.CreateAPIToken(h.Repos.DB, userID, input.Name, input.Scopes, input.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTokenScope) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid scopes. Use one or more of: read, timer, write")
+			return
+		}
+		h.Logger.Error("Failed to create API token", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create API token")
+		return
+	}
+
+	h.Logger.Info("API token created", zap.Uint("user_id", userID), zap.Uint("token_id", apiToken.ID))
+
+	response := formatAPIToken(apiToken)
+	response["token"] = token
+	utils.CreatedResponse(w, response)
+}
+
+// RevokeAPIToken revokes a personal API token so it can no longer be used
+func (h *APITokenHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := services.RevokeAPIToken(h.Repos.DB, userID, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "API token not found")
+			return
+		}
+		h.Logger.Error("Failed to revoke API token", zap.Uint64("id", id), zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to revoke API token")
+		return
+	}
+
+	h.Logger.Info("API token revoked", zap.Uint("user_id", userID), zap.Uint64("token_id", id))
+	utils.SuccessResponse(w, map[string]string{"message": "API token revoked"})
+}
+
+// formatAPIToken builds the response for a token without its secret
+func formatAPIToken(token *models.APIToken) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           token.ID,
+		"name":         token.Name,
+		"prefix":       token.Prefix,
+		"scopes":       token.ScopeList(),
+		"expires_at":   token.ExpiresAt,
+		"last_used_at": token.LastUsedAt,
+		"created_at":   token.CreatedAt,
+	}
+}
@@ -1,15 +1,27 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/Felipalds/go-pomodoro/database"
+	"github.com/Felipalds/go-pomodoro/middleware"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
 )
 
+type AuthHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// minPasswordLength is the shortest password accepted on registration or password change
+const minPasswordLength = 6
+
 // RegisterRequest represents the registration request body
 type RegisterRequest struct {
 	Name     string `json:"name"`
@@ -23,10 +35,19 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// RefreshRequest represents the token refresh request body
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // AuthResponse represents the authentication response
+// Token is the short-lived access token; RefreshToken is exchanged for a new pair at /auth/refresh
 type AuthResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token"`
+	User             UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        string       `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt string       `json:"refresh_expires_at"`
 }
 
 // UserResponse represents the user data in responses
@@ -38,7 +59,7 @@ type UserResponse struct {
 }
 
 // Register creates a new user account
-func Register(w http.ResponseWriter, r *http.Request) {
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -60,14 +81,13 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Password) < 6 {
+	if len(req.Password) < minPasswordLength {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Password must be at least 6 characters")
 		return
 	}
 
 	// Check if email already exists
-	var existingUser models.User
-	if err := database.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+	if _, err := h.Repos.Users.FindByEmail(req.Email); err == nil {
 		utils.ErrorResponse(w, http.StatusConflict, "Email already registered")
 		return
 	}
@@ -83,33 +103,25 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
+	// Create the user together with their own copy of the default categories
+	if err := h.Repos.Users.Create(&user); err != nil {
+		h.Logger.Error("Failed to create user", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create user")
 		return
 	}
 
-	// Generate JWT token
-	token, err := services.GenerateToken(user.ID, user.Email)
+	// Start a session and issue its tokens
+	tokens, err := services.CreateSession(h.Repos.DB, &user, sessionClient(r))
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	response := AuthResponse{
-		User: UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		},
-		Token: token,
-	}
-
-	utils.CreatedResponse(w, response)
+	utils.CreatedResponse(w, newAuthResponse(&user, tokens))
 }
 
 // Login authenticates a user and returns a token
-func Login(w http.ResponseWriter, r *http.Request) {
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -125,8 +137,8 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find user by email
-	var user models.User
-	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	user, err := h.Repos.Users.FindByEmail(req.Email)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
@@ -137,32 +149,80 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := services.GenerateToken(user.ID, user.Email)
+	// Start a session and issue its tokens
+	tokens, err := services.CreateSession(h.Repos.DB, user, sessionClient(r))
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	response := AuthResponse{
-		User: UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		},
-		Token: token,
+	utils.SuccessResponse(w, newAuthResponse(user, tokens))
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair
+// The old refresh token stops working once it has been used
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+
+	tokens, user, err := services.RefreshSession(h.Repos.DB, req.RefreshToken, sessionClient(r))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
 	}
 
-	utils.SuccessResponse(w, response)
+	utils.SuccessResponse(w, newAuthResponse(user, tokens))
+}
+
+// Logout revokes the session of the current access token
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	sessionID := middleware.GetSessionIDFromContext(r)
+
+	if err := services.RevokeSession(h.Repos.DB, userID, sessionID); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]string{"message": "Logged out"})
+}
+
+// LogoutAll revokes every session of the current user, including this one
+// API tokens are kept so scripts and integrations keep working; they are revoked through
+// DELETE /api/auth/tokens/{id}, or all at once by changing or resetting the password
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	revoked, err := services.RevokeAllSessions(h.Repos.DB, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to log out sessions")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"message":          "Logged out of all sessions",
+		"revoked_sessions": revoked,
+	})
 }
 
 // GetMe returns the current authenticated user
-func GetMe(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(uint)
+func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
+	user, err := h.Repos.Users.FindByID(userID)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
 		return
 	}
@@ -176,3 +236,27 @@ func GetMe(w http.ResponseWriter, r *http.Request) {
 
 	utils.SuccessResponse(w, map[string]interface{}{"user": response})
 }
+
+// newAuthResponse builds the response returned after login, registration or refresh
+func newAuthResponse(user *models.User, tokens *services.AuthTokens) AuthResponse {
+	return AuthResponse{
+		User: UserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		},
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt.UTC().Format(time.RFC3339),
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// sessionClient describes the device making the request
+func sessionClient(r *http.Request) services.SessionClient {
+	return services.SessionClient{
+		UserAgent: r.UserAgent(),
+		IPAddress: r.RemoteAddr,
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"go.uber.org/zap"
+)
+
+func TestRegisterCopiesDefaultCategoriesAndLogsIn(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &AuthHandler{Logger: zap.NewNop(), Repos: repos}
+
+	if err := repos.DB.Create(&models.Category{Name: "Work"}).Error; err != nil {
+		t.Fatalf("seed default category: %v", err)
+	}
+
+	body := decodeResponse(t, serve(t, h.Register, http.MethodPost, "/auth/register", "/auth/register", RegisterRequest{
+		Name:     "Ada",
+		Email:    " Ada@Example.com ",
+		Password: "secret123",
+	}, 0), http.StatusCreated)
+
+	if body["token"] == "" || body["refresh_token"] == "" {
+		t.Fatalf("expected tokens in %v", body)
+	}
+	user := body["user"].(map[string]interface{})
+	if user["email"] != "ada@example.com" {
+		t.Errorf("email = %v, want normalized ada@example.com", user["email"])
+	}
+
+	var owned int64
+	repos.DB.Model(&models.Category{}).Where("user_id = ? AND name = ?", uint(user["id"].(float64)), "Work").Count(&owned)
+	if owned != 1 {
+		t.Errorf("user owns %d copies of the default category, want 1", owned)
+	}
+
+	decodeResponse(t, serve(t, h.Login, http.MethodPost, "/auth/login", "/auth/login", LoginRequest{
+		Email:    "ada@example.com",
+		Password: "secret123",
+	}, 0), http.StatusOK)
+}
+
+func TestRegisterRejectsDuplicateEmail(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &AuthHandler{Logger: zap.NewNop(), Repos: repos}
+	createTestUser(t, repos, "taken@example.com")
+
+	rec := serve(t, h.Register, http.MethodPost, "/auth/register", "/auth/register", RegisterRequest{
+		Name:     "Someone",
+		Email:    "taken@example.com",
+		Password: "secret123",
+	}, 0)
+	decodeResponse(t, rec, http.StatusConflict)
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &AuthHandler{Logger: zap.NewNop(), Repos: repos}
+	createTestUser(t, repos, "user@example.com")
+
+	rec := serve(t, h.Login, http.MethodPost, "/auth/login", "/auth/login", LoginRequest{
+		Email:    "user@example.com",
+		Password: "wrong-password",
+	}, 0)
+	decodeResponse(t, rec, http.StatusUnauthorized)
+}
+
+func TestGetMeReturnsCurrentUser(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &AuthHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "me@example.com")
+
+	body := decodeResponse(t, serve(t, h.GetMe, http.MethodGet, "/auth/me", "/auth/me", nil, user.ID), http.StatusOK)
+	if got := body["user"].(map[string]interface{})["email"]; got != "me@example.com" {
+		t.Errorf("email = %v, want me@example.com", got)
+	}
+}
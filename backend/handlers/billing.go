@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type BillingHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// GetBillingSummary returns the amounts owed per client for billable time in a date range
+// Query params: from, to, activity_id, category_id, tag_id as for reports, client_id (comma-separated IDs),
+// and tz (defaults to the user's timezone)
+func (h *BillingHandler) GetBillingSummary(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	query := r.URL.Query()
+
+	settings, ok := userSettings(w, h.Logger, h.Repos.DB, userID)
+	if !ok {
+		return
+	}
+
+	loc, ok := parseTimezone(w, query, settings.Location())
+	if !ok {
+		return
+	}
+
+	filter, ok := parseReportFilter(w, query, loc)
+	if !ok {
+		return
+	}
+
+	clientIDs, err := utils.ParseIDList(query.Get("client_id"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client_id")
+		return
+	}
+
+	summary, err := services.BuildBillingSummary(h.Repos.DB, userID, filter, clientIDs)
+	if err != nil {
+		h.Logger.Error("Failed to build billing summary", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build billing summary")
+		return
+	}
+
+	utils.SuccessResponse(w, summary)
+}
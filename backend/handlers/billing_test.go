@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestBillingSummaryPricesRoundedTimePerClient(t *testing.T) {
+	repos := newTestRepos(t)
+	clients := &ClientHandler{Logger: zap.NewNop(), Repos: repos}
+	projects := &ProjectHandler{Logger: zap.NewNop(), Repos: repos}
+	activities := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	entries := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	h := &BillingHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	create := func(handler http.HandlerFunc, target string, input map[string]interface{}) uint {
+		t.Helper()
+		body := decodeResponse(t, serve(t, handler, http.MethodPost, target, target, input, user.ID), http.StatusCreated)
+		return uint(body["id"].(float64))
+	}
+	bill := func(activityID uint, input map[string]interface{}, status int) {
+		t.Helper()
+		target := fmt.Sprintf("/activities/%d/billing", activityID)
+		decodeResponse(t, serve(t, activities.UpdateActivityBilling, http.MethodPut, "/activities/{id}/billing", target, input, user.ID), status)
+	}
+
+	// Acme bills 100.00 USD an hour in started quarter hours; Globex only has a project rate, in EUR
+	acme := create(clients.CreateClient, "/clients", map[string]interface{}{"name": "Acme", "hourly_rate_cents": 10000, "rounding_minutes": 15})
+	globex := create(clients.CreateClient, "/clients", map[string]interface{}{"name": "Globex", "currency": "eur"})
+	website := create(projects.CreateProject, "/projects", map[string]interface{}{"client_id": acme, "name": "Website"})
+	audit := create(projects.CreateProject, "/projects", map[string]interface{}{"client_id": globex, "name": "Audit", "hourly_rate_cents": 9000})
+
+	design := createTestActivity(t, repos, user.ID, "Design")
+	dev := createTestActivity(t, repos, user.ID, "Dev")
+	review := createTestActivity(t, repos, user.ID, "Review")
+	personal := createTestActivity(t, repos, user.ID, "Personal")
+	bill(design.ID, map[string]interface{}{"project_id": website}, http.StatusOK)
+	bill(dev.ID, map[string]interface{}{"project_id": website, "hourly_rate_cents": 12000}, http.StatusOK)
+	bill(review.ID, map[string]interface{}{"project_id": audit}, http.StatusOK)
+
+	day := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC)
+	createTestEntry(t, repos, design, day, day.Add(50*time.Minute), 0)                              // Billed as 60 minutes
+	createTestEntry(t, repos, dev, day.Add(time.Hour), day.Add(time.Hour+25*time.Minute), 5*60)     // 20 minutes, billed as 30
+	createTestEntry(t, repos, review, day.Add(2*time.Hour), day.Add(2*time.Hour+40*time.Minute), 0) // Exact, no rounding
+	createTestEntry(t, repos, personal, day.Add(3*time.Hour), day.Add(4*time.Hour), 0)              // No project
+	createTestEntry(t, repos, review, day.AddDate(0, 1, 0), day.AddDate(0, 1, 0).Add(time.Hour), 0) // Outside the range
+
+	body := decodeResponse(t, serve(t, entries.CreateTimeEntry, http.MethodPost, "/time-entries", "/time-entries", map[string]interface{}{
+		"activity_id": design.ID,
+		"start_time":  day.Add(5 * time.Hour),
+		"end_time":    day.Add(6 * time.Hour),
+		"billable":    false,
+	}, user.ID), http.StatusCreated)
+	if body["billable"] != false {
+		t.Fatalf("entry = %v, want it not billable", body)
+	}
+	body = decodeResponse(t, serve(t, entries.CreateTimeEntry, http.MethodPost, "/time-entries", "/time-entries", map[string]interface{}{
+		"activity_id": personal.ID,
+		"start_time":  day.Add(6 * time.Hour),
+		"end_time":    day.Add(7 * time.Hour),
+	}, user.ID), http.StatusCreated)
+	if body["billable"] != true {
+		t.Fatalf("entry = %v, want it billable by default", body)
+	}
+
+	body = decodeResponse(t, serve(t, h.GetBillingSummary, http.MethodGet, "/billing/summary",
+		"/billing/summary?from=2024-03-01&to=2024-03-31&tz=UTC", nil, user.ID), http.StatusOK)
+
+	amounts := body["amounts"].([]interface{})
+	if len(amounts) != 2 ||
+		amounts[0].(map[string]interface{})["currency"] != "EUR" || amounts[0].(map[string]interface{})["amount_cents"] != float64(6000) ||
+		amounts[1].(map[string]interface{})["currency"] != "USD" || amounts[1].(map[string]interface{})["amount_cents"] != float64(16000) {
+		t.Errorf("amounts = %v, want 60.00 EUR and 160.00 USD", amounts)
+	}
+
+	summaryClients := body["clients"].([]interface{})
+	if len(summaryClients) != 2 {
+		t.Fatalf("got %d clients, want 2", len(summaryClients))
+	}
+	acmeBilling := summaryClients[0].(map[string]interface{})
+	if acmeBilling["name"] != "Acme" || acmeBilling["tracked_seconds"] != float64(70*60) || acmeBilling["billed_seconds"] != float64(90*60) {
+		t.Errorf("Acme = %v tracked, %v billed, want 70 and 90 minutes", acmeBilling["tracked_seconds"], acmeBilling["billed_seconds"])
+	}
+	lines := acmeBilling["projects"].([]interface{})[0].(map[string]interface{})["lines"].([]interface{})
+	for i, want := range []struct {
+		name   string
+		source string
+		amount float64
+	}{
+		{"Design", "client", 10000},
+		{"Dev", "activity", 6000},
+	} {
+		line := lines[i].(map[string]interface{})
+		rate := line["rate"].(map[string]interface{})
+		if line["activity_name"] != want.name || rate["source"] != want.source || line["amount_cents"] != want.amount {
+			t.Errorf("line %d = %v, want %s at the %s rate for %v cents", i, line, want.name, want.source, want.amount)
+		}
+	}
+
+	body = decodeResponse(t, serve(t, h.GetBillingSummary, http.MethodGet, "/billing/summary",
+		fmt.Sprintf("/billing/summary?from=2024-03-01&to=2024-03-31&tz=UTC&client_id=%d", globex), nil, user.ID), http.StatusOK)
+	summaryClients = body["clients"].([]interface{})
+	if len(summaryClients) != 1 || summaryClients[0].(map[string]interface{})["name"] != "Globex" {
+		t.Errorf("clients = %v, want only Globex", summaryClients)
+	}
+}
+
+func TestBillingRejectsInvalidRatesAndOtherUsersRecords(t *testing.T) {
+	repos := newTestRepos(t)
+	clients := &ClientHandler{Logger: zap.NewNop(), Repos: repos}
+	projects := &ProjectHandler{Logger: zap.NewNop(), Repos: repos}
+	activities := &ActivityHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	other := createTestUser(t, repos, "other@example.com")
+
+	for _, input := range []map[string]interface{}{
+		{"name": ""},
+		{"name": "Acme", "currency": "dollars"},
+		{"name": "Acme", "hourly_rate_cents": -1},
+		{"name": "Acme", "rounding_mode": "sideways"},
+	} {
+		decodeResponse(t, serve(t, clients.CreateClient, http.MethodPost, "/clients", "/clients", input, user.ID), http.StatusBadRequest)
+	}
+
+	body := decodeResponse(t, serve(t, clients.CreateClient, http.MethodPost, "/clients", "/clients",
+		map[string]interface{}{"name": "Theirs"}, other.ID), http.StatusCreated)
+	if body["currency"] != "USD" || body["rounding_mode"] != "up" {
+		t.Errorf("client = %v, want USD rounded up by default", body)
+	}
+	theirClient := body["id"]
+
+	decodeResponse(t, serve(t, projects.CreateProject, http.MethodPost, "/projects", "/projects",
+		map[string]interface{}{"client_id": theirClient, "name": "Stolen"}, user.ID), http.StatusNotFound)
+
+	body = decodeResponse(t, serve(t, projects.CreateProject, http.MethodPost, "/projects", "/projects",
+		map[string]interface{}{"client_id": theirClient, "name": "Theirs"}, other.ID), http.StatusCreated)
+
+	activity := createTestActivity(t, repos, user.ID, "Mine")
+	target := fmt.Sprintf("/activities/%d/billing", activity.ID)
+	decodeResponse(t, serve(t, activities.UpdateActivityBilling, http.MethodPut, "/activities/{id}/billing", target,
+		map[string]interface{}{"project_id": body["id"]}, user.ID), http.StatusNotFound)
+
+	// Deleting the client takes its projects with it
+	decodeResponse(t, serve(t, clients.DeleteClient, http.MethodDelete, "/clients/{id}", fmt.Sprintf("/clients/%v", theirClient), nil, other.ID), http.StatusOK)
+	body = decodeResponse(t, serve(t, projects.GetProjects, http.MethodGet, "/projects", "/projects", nil, other.ID), http.StatusOK)
+	if got := len(body["projects"].([]interface{})); got != 0 {
+		t.Errorf("got %d projects after deleting their client, want 0", got)
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/Felipalds/go-pomodoro/database"
+	"github.com/Felipalds/go-pomodoro/middleware"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/utils"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -13,14 +14,16 @@ import (
 
 type CategoryHandler struct {
 	Logger *zap.Logger
+	Repos  *repository.Repositories
 }
 
 // GetCategories returns all active categories
 func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	var categories []models.Category
 
 	// Get all categories that are not deleted
-	if err := database.DB.Where("deleted_at IS NULL").Find(&categories).Error; err != nil {
+	if err := h.Repos.DB.Where("user_id = ? AND deleted_at IS NULL", userID).Find(&categories).Error; err != nil {
 		h.Logger.Error("Failed to fetch categories", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch categories")
 		return
@@ -33,6 +36,7 @@ func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request)
 
 // GetCategory returns a single category by ID
 func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -41,7 +45,7 @@ func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var category models.Category
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&category).Error; err != nil {
+	if err := h.Repos.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&category).Error; err != nil {
 		h.Logger.Error("Category not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Category not found")
 		return
@@ -52,6 +56,7 @@ func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 
 // UpdateCategory updates a category name
 func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -75,15 +80,25 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 	}
 
 	var category models.Category
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&category).Error; err != nil {
+	if err := h.Repos.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&category).Error; err != nil {
 		h.Logger.Error("Category not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Category not found")
 		return
 	}
 
+	// Names are unique per user (case-insensitive)
+	var existing int64
+	h.Repos.DB.Model(&models.Category{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, input.Name, category.ID).
+		Count(&existing)
+	if existing > 0 {
+		utils.ErrorResponse(w, http.StatusConflict, "Category with this name already exists")
+		return
+	}
+
 	// Update the category
 	category.Name = input.Name
-	if err := database.DB.Save(&category).Error; err != nil {
+	if err := h.Repos.DB.Save(&category).Error; err != nil {
 		h.Logger.Error("Failed to update category", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update category")
 		return
@@ -94,6 +109,7 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 
 // DeleteCategory soft deletes a category
 func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -102,14 +118,14 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	}
 
 	var category models.Category
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&category).Error; err != nil {
+	if err := h.Repos.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&category).Error; err != nil {
 		h.Logger.Error("Category not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Category not found")
 		return
 	}
 
 	// Soft delete by setting deleted_at
-	if err := database.DB.Delete(&category).Error; err != nil {
+	if err := h.Repos.DB.Delete(&category).Error; err != nil {
 		h.Logger.Error("Failed to delete category", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete category")
 		return
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type ClientHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// ClientInput represents the input for creating or replacing a client
+// A left out rate means the client has none; currency defaults to USD and rounding to none
+type ClientInput struct {
+	Name            string              `json:"name"`
+	HourlyRateCents *int64              `json:"hourly_rate_cents"`
+	Currency        string              `json:"currency"`
+	RoundingMinutes int                 `json:"rounding_minutes"`
+	RoundingMode    models.RoundingMode `json:"rounding_mode"`
+}
+
+// apply copies the input onto the client
+func (input *ClientInput) apply(client *models.Client) {
+	client.Name = strings.TrimSpace(input.Name)
+	client.HourlyRateCents = input.HourlyRateCents
+	client.Currency = strings.ToUpper(input.Currency)
+	if client.Currency == "" {
+		client.Currency = models.DefaultCurrency
+	}
+	client.RoundingMinutes = input.RoundingMinutes
+	client.RoundingMode = input.RoundingMode
+	if client.RoundingMode == "" {
+		client.RoundingMode = models.RoundUp
+	}
+}
+
+// GetClients returns the user's clients
+func (h *ClientHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	clients, err := services.ListClients(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch clients", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch clients")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"clients": clients,
+	})
+}
+
+// CreateClient creates a client
+func (h *ClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input ClientInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	client := models.Client{UserID: userID}
+	input.apply(&client)
+
+	if !h.saveClient(w, &client) {
+		return
+	}
+
+	utils.CreatedResponse(w, client)
+}
+
+// UpdateClient replaces a client's name, rate, currency and rounding
+func (h *ClientHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+		return
+	}
+
+	var input ClientInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	client, err := services.FindClient(h.Repos.DB, userID, uint(id))
+	if errors.Is(err, services.ErrClientNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to fetch client", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update client")
+		return
+	}
+
+	input.apply(client)
+
+	if !h.saveClient(w, client) {
+		return
+	}
+
+	utils.SuccessResponse(w, client)
+}
+
+// DeleteClient soft deletes a client and its projects
+func (h *ClientHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client ID")
+		return
+	}
+
+	err = services.DeleteClient(h.Repos.DB, userID, uint(id))
+	if errors.Is(err, services.ErrClientNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to delete client", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete client")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]string{
+		"message": "Client deleted successfully",
+	})
+}
+
+// saveClient validates and stores a client
+// Writes the error response and returns false when the client is invalid or cannot be saved
+func (h *ClientHandler) saveClient(w http.ResponseWriter, client *models.Client) bool {
+	if err := client.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	if err := services.SaveClient(h.Repos.DB, client); err != nil {
+		h.Logger.Error("Failed to save client", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save client")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+// fakeDataDragon serves a small Data Dragon catalog and counts the requests per path
+type fakeDataDragon struct {
+	mu          sync.Mutex
+	version     string
+	failChampID string // champion whose skin request fails, if set
+	requests    map[string]int
+}
+
+func (f *fakeDataDragon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests[r.URL.Path]++
+
+	prefix := "/cdn/" + f.version + "/data/en_US/"
+	var body interface{}
+	switch path := r.URL.Path; {
+	case path == "/api/versions.json":
+		body = []string{f.version, "1.0.0"}
+	case path == prefix+"champion.json":
+		body = map[string]interface{}{"data": map[string]interface{}{
+			"Ahri": map[string]string{"id": "Ahri", "name": "Ahri", "title": "the Nine-Tailed Fox"},
+			"Lux":  map[string]string{"id": "Lux", "name": "Lux", "title": "the Lady of Luminosity"},
+		}}
+	case path == prefix+"item.json":
+		body = map[string]interface{}{"data": map[string]interface{}{"1001": map[string]string{"name": "Boots"}}}
+	case path == prefix+"profileicon.json":
+		body = map[string]interface{}{"data": map[string]interface{}{"1": map[string]int{"id": 1}}}
+	case strings.HasPrefix(path, prefix+"champion/"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, prefix+"champion/"), ".json")
+		if id == f.failChampID {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		body = map[string]interface{}{"data": map[string]interface{}{id: map[string]interface{}{
+			"skins": []map[string]interface{}{{"num": 0, "name": "default"}, {"num": 1, "name": f.version + " " + id}},
+		}}}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// take returns the requests made since the last call and resets the count
+func (f *fakeDataDragon) take() map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = map[string]int{}
+	return requests
+}
+
+func TestDataDragonCatalogIsCachedAndOnlyRefetchedForNewVersions(t *testing.T) {
+	fake := &fakeDataDragon{version: "14.1.1", requests: map[string]int{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "data", "ddragon_catalog.json")
+	newService := func() *services.DataDragonService {
+		return &services.DataDragonService{
+			BaseURL:     server.URL,
+			CachePath:   cachePath,
+			Concurrency: 2,
+			Client:      server.Client(),
+			Logger:      zap.NewNop(),
+		}
+	}
+	expectCatalog := func(s *services.DataDragonService, version string) {
+		t.Helper()
+		if got := s.GetVersion(); got != version {
+			t.Errorf("version = %q, want %q", got, version)
+		}
+		stats := s.GetStats()
+		if stats["champions"] != 2 || stats["items"] != 1 || stats["icons"] != 1 || stats["skins"] != 2 {
+			t.Errorf("stats = %v, want 2 champions, 1 item, 1 icon and 2 skins", stats)
+		}
+		if skin, err := s.Find(models.RewardTypeSkin, "Ahri_1"); err != nil || skin.Name != version+" Ahri" {
+			t.Errorf("skin = %v, %v, want the %s skin of Ahri", skin, err, version)
+		}
+	}
+	ctx := context.Background()
+
+	// Cold start: nothing cached, everything is fetched and written to the cache
+	service := newService()
+	if err := service.LoadCache(); err != nil {
+		t.Fatalf("load missing cache: %v", err)
+	}
+	if service.IsReady() {
+		t.Fatal("catalog is ready before the first refresh")
+	}
+	if err := service.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	expectCatalog(service, "14.1.1")
+	if requests := fake.take(); len(requests) != 6 {
+		t.Errorf("cold start made requests %v, want versions, champions, items, icons and 2 champion files", requests)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("cache was not written: %v", err)
+	}
+
+	// Restart: the catalog comes from the cache without any request
+	service = newService()
+	if err := service.LoadCache(); err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	expectCatalog(service, "14.1.1")
+	if requests := fake.take(); len(requests) != 0 {
+		t.Errorf("loading the cache made requests %v, want none", requests)
+	}
+
+	// Same version: only the version list is fetched
+	before := time.Now()
+	if err := service.Refresh(ctx); err != nil {
+		t.Fatalf("refresh unchanged version: %v", err)
+	}
+	if requests := fake.take(); len(requests) != 1 || requests["/api/versions.json"] != 1 {
+		t.Errorf("unchanged refresh made requests %v, want only versions.json", requests)
+	}
+	var cached services.DataDragonCatalog
+	data, err := os.ReadFile(cachePath)
+	if err == nil {
+		err = json.Unmarshal(data, &cached)
+	}
+	if err != nil || cached.FetchedAt.Before(before) {
+		t.Errorf("cache fetched at %v (%v), want it renewed after %v", cached.FetchedAt, err, before)
+	}
+
+	// New version with a failing champion: the previous catalog is kept, in memory and on disk
+	fake.mu.Lock()
+	fake.version, fake.failChampID = "14.2.1", "Lux"
+	fake.mu.Unlock()
+	if err := service.Refresh(ctx); err == nil {
+		t.Fatal("refresh succeeded with a failing champion")
+	}
+	expectCatalog(service, "14.1.1")
+
+	service = newService()
+	if err := service.LoadCache(); err != nil {
+		t.Fatalf("load cache after failed refresh: %v", err)
+	}
+	expectCatalog(service, "14.1.1")
+
+	// Once the champion is back the new version replaces it
+	fake.mu.Lock()
+	fake.failChampID = ""
+	fake.mu.Unlock()
+	if err := service.Refresh(ctx); err != nil {
+		t.Fatalf("refresh new version: %v", err)
+	}
+	expectCatalog(service, "14.2.1")
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+// eventKeepAliveInterval is how often a comment is sent so proxies don't close idle streams
+const eventKeepAliveInterval = 25 * time.Second
+
+// DefaultEventCredentialCheckInterval is how often an open stream checks its session or API token is still active
+const DefaultEventCredentialCheckInterval = 30 * time.Second
+
+type EventHandler struct {
+	Logger  *zap.Logger
+	Hub     *services.EventHub
+	Tickets *services.StreamTicketStore
+	Repos   *repository.Repositories
+	// CredentialCheckInterval defaults to DefaultEventCredentialCheckInterval
+	CredentialCheckInterval time.Duration
+}
+
+// CreateStreamTicket issues a single-use ticket for opening the event stream from a browser,
+// which passes it as the ticket query parameter because EventSource cannot set headers
+func (h *EventHandler) CreateStreamTicket(w http.ResponseWriter, r *http.Request) {
+	ticket, expiresAt, err := h.Tickets.Issue(services.StreamTicket{
+		UserID:     middleware.GetUserIDFromContext(r),
+		SessionID:  middleware.GetSessionIDFromContext(r),
+		APITokenID: middleware.GetAPITokenIDFromContext(r),
+		ExpiresAt:  middleware.GetTokenExpiryFromContext(r),
+	})
+	if err != nil {
+		h.Logger.Error("Failed to issue stream ticket", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to issue stream ticket")
+		return
+	}
+
+	utils.CreatedResponse(w, map[string]interface{}{
+		"ticket":     ticket,
+		"expires_at": expiresAt,
+	})
+}
+
+// StreamEvents streams the user's live updates as Server-Sent Events
+// Each event is sent with its type as the SSE event name and the JSON payload as data
+// The stream is closed once the access token it was opened with expires or its session or API token is revoked;
+// clients reconnect with fresh credentials
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	sessionID := middleware.GetSessionIDFromContext(r)
+	apiTokenID := middleware.GetAPITokenIDFromContext(r)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	events, unsubscribe := h.Hub.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell the browser how long to wait before reconnecting
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	h.Logger.Info("Event stream opened", zap.Uint("user_id", userID), zap.Int("connections", h.Hub.SubscriberCount(userID)))
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	checkInterval := h.CredentialCheckInterval
+	if checkInterval <= 0 {
+		checkInterval = DefaultEventCredentialCheckInterval
+	}
+	credentialCheck := time.NewTicker(checkInterval)
+	defer credentialCheck.Stop()
+
+	// Never fires for API tokens, which are checked on the ticker instead
+	var expired <-chan time.Time
+	if expiresAt := middleware.GetTokenExpiryFromContext(r); !expiresAt.IsZero() {
+		expiry := time.NewTimer(time.Until(expiresAt))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.Logger.Info("Event stream closed", zap.Uint("user_id", userID))
+			return
+
+		case <-expired:
+			h.Logger.Info("Event stream closed, access token expired", zap.Uint("user_id", userID))
+			return
+
+		case <-credentialCheck.C:
+			active, err := h.credentialActive(userID, sessionID, apiTokenID)
+			if err != nil {
+				// Keep streaming through a failed check; the next one decides
+				h.Logger.Error("Failed to verify event stream credentials", zap.Uint("user_id", userID), zap.Error(err))
+				continue
+			}
+			if !active {
+				h.Logger.Info("Event stream closed, credentials revoked", zap.Uint("user_id", userID))
+				return
+			}
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.Logger.Error("Failed to encode event", zap.String("type", string(event.Type)), zap.Error(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// credentialActive reports whether the session or API token a stream was opened with is still active
+func (h *EventHandler) credentialActive(userID, sessionID, apiTokenID uint) (bool, error) {
+	if apiTokenID != 0 {
+		return services.IsAPITokenActive(h.Repos.DB, userID, apiTokenID)
+	}
+	return services.IsSessionActive(h.Repos.DB, userID, sessionID)
+}
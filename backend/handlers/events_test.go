@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+func TestStreamTicketsAreSingleUseAndReplaceQueryTokens(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &EventHandler{Logger: zap.NewNop(), Hub: services.NewEventHub(), Tickets: services.NewStreamTicketStore()}
+	user := createTestUser(t, repos, "user@example.com")
+
+	// Stands in for StreamEvents, which would keep the connection open
+	stream := middleware.StreamTicketAuth(h.Tickets)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.SuccessResponse(w, map[string]uint{"user_id": middleware.GetUserIDFromContext(r)})
+	}))
+
+	body := decodeResponse(t, serve(t, h.CreateStreamTicket, http.MethodPost, "/events/ticket", "/events/ticket", nil, user.ID), http.StatusCreated)
+	target := "/events?ticket=" + url.QueryEscape(body["ticket"].(string))
+
+	body = decodeResponse(t, serve(t, stream.ServeHTTP, http.MethodGet, "/events", target, nil, 0), http.StatusOK)
+	if body["user_id"] != float64(user.ID) {
+		t.Errorf("stream opened for user %v, want %d", body["user_id"], user.ID)
+	}
+
+	// A used ticket, an unknown one and a token in the query string are all refused
+	for _, target := range []string{target, "/events?ticket=unknown", "/events?access_token=tt_secret"} {
+		decodeResponse(t, serve(t, stream.ServeHTTP, http.MethodGet, "/events", target, nil, 0), http.StatusUnauthorized)
+	}
+}
+
+func TestEventStreamClosesWhenCredentialsEnd(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &EventHandler{
+		Logger:                  zap.NewNop(),
+		Hub:                     services.NewEventHub(),
+		Tickets:                 services.NewStreamTicketStore(),
+		Repos:                   repos,
+		CredentialCheckInterval: 10 * time.Millisecond,
+	}
+	user := createTestUser(t, repos, "user@example.com")
+
+	server := httptest.NewServer(middleware.StreamTicketAuth(h.Tickets)(http.HandlerFunc(h.StreamEvents)))
+	defer server.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	// open starts a stream for a new session whose access token expires at expiresAt
+	open := func(expiresAt time.Time) (*http.Response, *services.AuthTokens) {
+		t.Helper()
+		tokens, err := services.CreateSession(repos.DB, user, services.SessionClient{})
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		ticket, _, err := h.Tickets.Issue(services.StreamTicket{UserID: user.ID, SessionID: tokens.SessionID, ExpiresAt: expiresAt})
+		if err != nil {
+			t.Fatalf("issue ticket: %v", err)
+		}
+		resp, err := client.Get(server.URL + "/events?ticket=" + url.QueryEscape(ticket))
+		if err != nil {
+			t.Fatalf("open stream: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		return resp, tokens
+	}
+
+	// expectClosed reads the stream until the server ends it, failing on the client timeout
+	expectClosed := func(resp *http.Response) {
+		t.Helper()
+		defer resp.Body.Close()
+		if _, err := io.ReadAll(resp.Body); err != nil {
+			t.Fatalf("stream was not closed: %v", err)
+		}
+	}
+
+	resp, tokens := open(time.Now().Add(time.Hour))
+	if err := services.RevokeSession(repos.DB, user.ID, tokens.SessionID); err != nil {
+		t.Fatalf("revoke session: %v", err)
+	}
+	expectClosed(resp)
+
+	resp, _ = open(time.Now().Add(100 * time.Millisecond))
+	expectClosed(resp)
+}
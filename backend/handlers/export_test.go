@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+func TestExportStreamsEveryEntryInStartOrder(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ExportHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Study")
+
+	// Entries are stored newest first, like a backfilled history, so ids run against start times
+	// Every third entry shares its start time with the previous one to exercise the id tie-break
+	total := 2*services.ExportBatchSize + 7
+	base := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	entries := make([]models.TimeEntry, 0, total)
+	for i := total - 1; i >= 0; i-- {
+		start := base.Add(time.Duration(i-i%3/2) * time.Hour)
+		end := start.Add(30 * time.Minute)
+		entries = append(entries, models.TimeEntry{UserID: user.ID, ActivityID: activity.ID, StartTime: start, EndTime: &end, Billable: true})
+	}
+	if err := repos.DB.CreateInBatches(&entries, 200).Error; err != nil {
+		t.Fatalf("create entries: %v", err)
+	}
+	other := createTestUser(t, repos, "other@example.com")
+	createTestEntry(t, repos, createTestActivity(t, repos, other.ID, "Theirs"), base, base.Add(time.Hour), 0)
+
+	// checkRows expects every entry once, in start order, as (id, start_time) pairs
+	checkRows := func(format string, rows [][2]string) {
+		t.Helper()
+		if len(rows) != total {
+			t.Fatalf("%s: exported %d rows, want %d", format, len(rows), total)
+		}
+		seen := make(map[string]bool, total)
+		for i, row := range rows {
+			if seen[row[0]] {
+				t.Fatalf("%s: entry %s exported twice", format, row[0])
+			}
+			seen[row[0]] = true
+			if i > 0 && row[1] < rows[i-1][1] {
+				t.Fatalf("%s: row %d starts at %s, before the previous row at %s", format, i, row[1], rows[i-1][1])
+			}
+		}
+	}
+
+	export := func(format string) io.Reader {
+		t.Helper()
+		target := fmt.Sprintf("/export/time-entries?format=%s&from=2024-01-01&to=2024-12-31&tz=UTC", format)
+		rec := serve(t, h.ExportTimeEntries, http.MethodGet, "/export/time-entries", target, nil, user.ID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d; body: %s", format, rec.Code, http.StatusOK, rec.Body.String())
+		}
+		if disposition := rec.Header().Get("Content-Disposition"); !strings.HasSuffix(disposition, "."+format+`"`) {
+			t.Errorf("%s: Content-Disposition = %q, want a .%s attachment", format, disposition, format)
+		}
+		return rec.Body
+	}
+
+	records, err := csv.NewReader(export("csv")).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if strings.Join(records[0], ",") != strings.Join(services.ExportHeader, ",") {
+		t.Fatalf("csv header = %v, want %v", records[0], services.ExportHeader)
+	}
+	var rows [][2]string
+	for _, record := range records[1:] {
+		rows = append(rows, [2]string{record[0], record[6]})
+	}
+	checkRows("csv", rows)
+
+	rows = nil
+	decoder := json.NewDecoder(export("ndjson"))
+	for decoder.More() {
+		var row services.ExportRow
+		if err := decoder.Decode(&row); err != nil {
+			t.Fatalf("decode ndjson: %v", err)
+		}
+		if row.ActivityName != "Study" || row.DurationSeconds != 30*60 {
+			t.Fatalf("row = %+v, want 30 minutes of Study", row)
+		}
+		rows = append(rows, [2]string{fmt.Sprint(row.ID), row.StartTime})
+	}
+	checkRows("ndjson", rows)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type GoalHandler struct {
+	Logger *zap.Logger
+	Events *services.EventHub
+	Repos  *repository.Repositories
+}
+
+// GoalInput represents the input for creating or editing a goal
+// All fields are optional on update; only provided fields are changed
+type GoalInput struct {
+	Name          *string            `json:"name"`
+	Kind          *models.GoalKind   `json:"kind"`
+	Period        *models.GoalPeriod `json:"period"`
+	TargetMinutes *int               `json:"target_minutes"`
+	ScopeType     *models.GoalScope  `json:"scope_type"`
+	ScopeID       *uint              `json:"scope_id"`
+}
+
+// apply copies the provided fields onto the goal
+func (input *GoalInput) apply(goal *models.Goal) {
+	if input.Name != nil {
+		goal.Name = *input.Name
+	}
+	if input.Kind != nil {
+		goal.Kind = *input.Kind
+	}
+	if input.Period != nil {
+		goal.Period = *input.Period
+	}
+	if input.TargetMinutes != nil {
+		goal.TargetMinutes = *input.TargetMinutes
+	}
+	if input.ScopeType != nil {
+		goal.ScopeType = *input.ScopeType
+	}
+	if input.ScopeID != nil {
+		goal.ScopeID = *input.ScopeID
+	}
+}
+
+// GetGoals returns the user's goals
+func (h *GoalHandler) GetGoals(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	goals, err := services.ListGoals(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch goals", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch goals")
+		return
+	}
+
+	utils.SuccessResponse(w, goals)
+}
+
+// GetGoalStatus returns the progress of every goal in its current period and whether it is
+// on track, behind, completed or exceeded
+func (h *GoalHandler) GetGoalStatus(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	progress, err := services.GetGoalProgress(h.Repos.DB, userID, time.Now())
+	if err != nil {
+		h.Logger.Error("Failed to compute goal progress", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute goal progress")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"goals": progress,
+	})
+}
+
+// CreateGoal creates a goal
+func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input GoalInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	goal := models.Goal{UserID: userID}
+	input.apply(&goal)
+
+	if !h.saveGoal(w, &goal) {
+		return
+	}
+
+	utils.CreatedResponse(w, goal)
+}
+
+// UpdateGoal edits a goal
+func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid goal ID")
+		return
+	}
+
+	var input GoalInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	goal, err := services.FindGoal(h.Repos.DB, userID, uint(id))
+	if errors.Is(err, services.ErrGoalNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Goal not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to fetch goal", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update goal")
+		return
+	}
+
+	input.apply(goal)
+
+	if !h.saveGoal(w, goal) {
+		return
+	}
+
+	utils.SuccessResponse(w, goal)
+}
+
+// DeleteGoal deletes a goal
+func (h *GoalHandler) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid goal ID")
+		return
+	}
+
+	err = services.DeleteGoal(h.Repos.DB, userID, uint(id))
+	if errors.Is(err, services.ErrGoalNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Goal not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to delete goal", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete goal")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]string{
+		"message": "Goal deleted successfully",
+	})
+}
+
+// saveGoal validates and stores a goal
+// Writes the error response and returns false when the goal is invalid or cannot be saved
+func (h *GoalHandler) saveGoal(w http.ResponseWriter, goal *models.Goal) bool {
+	if err := goal.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	err := services.SaveGoal(h.Repos.DB, goal, time.Now())
+	if errors.Is(err, services.ErrGoalScopeNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Goal scope not found")
+		return false
+	}
+	if err != nil {
+		h.Logger.Error("Failed to save goal", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save goal")
+		return false
+	}
+	return true
+}
+
+// announceGoalCrossings announces the goals the user's tracked time newly crossed
+// Failures are only logged so they never fail the request that triggered the check
+func announceGoalCrossings(logger *zap.Logger, events *services.EventHub, repos *repository.Repositories, userID uint) {
+	crossed, err := services.CheckGoalCrossings(repos.DB, userID, time.Now())
+	if err != nil {
+		logger.Error("Failed to check goals", zap.Uint("user_id", userID), zap.Error(err))
+		return
+	}
+
+	for _, progress := range crossed {
+		events.Publish(userID, progress.CrossedEvent(), progress)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+// useMiddayTimezone sets the user's timezone to a fixed offset zone where it is currently around noon,
+// so entries of the last couple of hours fall on the same day, week and month
+func useMiddayTimezone(t *testing.T, repos *repository.Repositories, userID uint) {
+	t.Helper()
+
+	settings := models.DefaultUserSettings(userID)
+	// Etc/GMT-N is N hours ahead of UTC
+	settings.Timezone = fmt.Sprintf("Etc/GMT%+d", time.Now().UTC().Hour()-12)
+	if err := repos.Users.SaveSettings(&settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+}
+
+func TestGoalStatusComparesProgressWithTargets(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &GoalHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	useMiddayTimezone(t, repos, user.ID)
+
+	tag := models.Tag{UserID: user.ID, Name: "social"}
+	if err := repos.DB.Create(&tag).Error; err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	study := createTestActivity(t, repos, user.ID, "Study")
+	social := &models.Activity{UserID: user.ID, Name: "Chat", MainCategoryID: study.MainCategoryID}
+	if err := repos.Activities.Create(social, []models.Tag{tag}); err != nil {
+		t.Fatalf("create activity: %v", err)
+	}
+
+	now := time.Now()
+	createTestEntry(t, repos, study, now.Add(-2*time.Hour), now.Add(-75*time.Minute), 0)
+	createTestEntry(t, repos, social, now.Add(-70*time.Minute), now.Add(-10*time.Minute), 15*60)
+
+	create := func(input map[string]interface{}) float64 {
+		t.Helper()
+		body := decodeResponse(t, serve(t, h.CreateGoal, http.MethodPost, "/goals", "/goals", input, user.ID), http.StatusCreated)
+		return body["id"].(float64)
+	}
+	studyDaily := create(map[string]interface{}{"kind": "minimum", "period": "day", "target_minutes": 30, "scope_type": "activity", "scope_id": study.ID})
+	categoryMonthly := create(map[string]interface{}{"kind": "minimum", "period": "month", "target_minutes": 9000, "scope_type": "category", "scope_id": study.MainCategoryID})
+	socialDaily := create(map[string]interface{}{"kind": "maximum", "period": "day", "target_minutes": 30, "scope_type": "tag", "scope_id": tag.ID})
+	socialWeekly := create(map[string]interface{}{"name": "Social", "kind": "maximum", "period": "week", "target_minutes": 120, "scope_type": "tag", "scope_id": tag.ID})
+
+	body := decodeResponse(t, serve(t, h.GetGoalStatus, http.MethodGet, "/goals/status", "/goals/status", nil, user.ID), http.StatusOK)
+	statuses := make(map[float64]map[string]interface{})
+	for _, item := range body["goals"].([]interface{}) {
+		progress := item.(map[string]interface{})
+		statuses[progress["goal"].(map[string]interface{})["id"].(float64)] = progress
+	}
+
+	for _, want := range []struct {
+		id      float64
+		status  string
+		tracked float64
+	}{
+		{studyDaily, "completed", 45 * 60},
+		{categoryMonthly, "behind", 90 * 60}, // Both activities share the category
+		{socialDaily, "exceeded", 45 * 60},
+		{socialWeekly, "on_track", 45 * 60},
+	} {
+		progress := statuses[want.id]
+		if progress["status"] != want.status || progress["tracked_seconds"] != want.tracked {
+			t.Errorf("goal %v = %v after %v seconds, want %s after %v", want.id, progress["status"], progress["tracked_seconds"], want.status, want.tracked)
+		}
+	}
+	if statuses[socialWeekly]["remaining_seconds"] != float64(75*60) {
+		t.Errorf("remaining = %v, want %v", statuses[socialWeekly]["remaining_seconds"], 75*60)
+	}
+
+	// Invalid goals and scopes of other users are rejected
+	other := createTestUser(t, repos, "other@example.com")
+	otherActivity := createTestActivity(t, repos, other.ID, "Theirs")
+	for _, test := range []struct {
+		input  map[string]interface{}
+		status int
+	}{
+		{map[string]interface{}{"kind": "at_least", "period": "day", "target_minutes": 30, "scope_type": "activity", "scope_id": study.ID}, http.StatusBadRequest},
+		{map[string]interface{}{"kind": "minimum", "period": "year", "target_minutes": 30, "scope_type": "activity", "scope_id": study.ID}, http.StatusBadRequest},
+		{map[string]interface{}{"kind": "minimum", "period": "day", "target_minutes": 0, "scope_type": "activity", "scope_id": study.ID}, http.StatusBadRequest},
+		{map[string]interface{}{"kind": "minimum", "period": "day", "target_minutes": 30, "scope_type": "project", "scope_id": study.ID}, http.StatusBadRequest},
+		{map[string]interface{}{"kind": "minimum", "period": "day", "target_minutes": 30, "scope_type": "activity", "scope_id": otherActivity.ID}, http.StatusNotFound},
+	} {
+		decodeResponse(t, serve(t, h.CreateGoal, http.MethodPost, "/goals", "/goals", test.input, user.ID), test.status)
+	}
+
+	// Updates only change the fields that are given
+	target := fmt.Sprintf("/goals/%d", int(socialDaily))
+	body = decodeResponse(t, serve(t, h.UpdateGoal, http.MethodPut, "/goals/{id}", target,
+		map[string]interface{}{"target_minutes": 60}, user.ID), http.StatusOK)
+	if body["target_minutes"] != float64(60) || body["kind"] != "maximum" {
+		t.Errorf("updated goal = %v, want a 60 minute budget", body)
+	}
+
+	decodeResponse(t, serve(t, h.DeleteGoal, http.MethodDelete, "/goals/{id}", target, nil, other.ID), http.StatusNotFound)
+	decodeResponse(t, serve(t, h.DeleteGoal, http.MethodDelete, "/goals/{id}", target, nil, user.ID), http.StatusOK)
+	body = decodeResponse(t, serve(t, h.GetGoalStatus, http.MethodGet, "/goals/status", "/goals/status", nil, user.ID), http.StatusOK)
+	if got := len(body["goals"].([]interface{})); got != 3 {
+		t.Errorf("got %d goals after deleting one, want 3", got)
+	}
+}
+
+func TestRunningTimerAnnouncesExceededBudgetOnce(t *testing.T) {
+	repos := newTestRepos(t)
+	hub := services.NewEventHub()
+	h := &GoalHandler{Logger: zap.NewNop(), Events: hub, Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	useMiddayTimezone(t, repos, user.ID)
+	activity := createTestActivity(t, repos, user.ID, "Social")
+
+	decodeResponse(t, serve(t, h.CreateGoal, http.MethodPost, "/goals", "/goals",
+		map[string]interface{}{"kind": "maximum", "period": "day", "target_minutes": 60, "scope_type": "activity", "scope_id": activity.ID}, user.ID), http.StatusCreated)
+
+	now := time.Now()
+	running := models.TimeEntry{UserID: user.ID, ActivityID: activity.ID, StartTime: now.Add(-50 * time.Minute)}
+	if err := repos.TimeEntries.Create(&running); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+
+	events, unsubscribe := hub.Subscribe(user.ID)
+	defer unsubscribe()
+	watcher := services.NewGoalWatcher(repos.DB, hub, zap.NewNop())
+
+	// Still within budget
+	if err := watcher.CheckRunningTimers(now); err != nil {
+		t.Fatalf("check running timers: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("got %d events within budget, want none", len(events))
+	}
+
+	// The timer keeps running past the budget
+	for _, at := range []time.Time{now.Add(15 * time.Minute), now.Add(20 * time.Minute)} {
+		if err := watcher.CheckRunningTimers(at); err != nil {
+			t.Fatalf("check running timers: %v", err)
+		}
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want the crossed budget announced once", len(events))
+	}
+	event := <-events
+	progress := event.Data.(services.GoalProgress)
+	if event.Type != services.EventGoalBudgetExceeded || progress.Status != services.GoalExceeded {
+		t.Errorf("event = %s with status %s, want %s with %s", event.Type, progress.Status, services.EventGoalBudgetExceeded, services.GoalExceeded)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/database"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRepos returns repositories backed by a fresh in-memory SQLite database with the full schema
+func newTestRepos(t *testing.T) *repository.Repositories {
+	t.Helper()
+
+	db, err := database.OpenSQLite(":memory:", &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := database.MigrateUp(db, zap.NewNop()); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+
+	return repository.NewSQLite(db)
+}
+
+// createTestUser stores a user that requests can act as
+func createTestUser(t *testing.T, repos *repository.Repositories, email string) *models.User {
+	t.Helper()
+
+	user := models.User{Name: "Test User", Email: email}
+	if err := user.SetPassword("secret123"); err != nil {
+		t.Fatalf("set password: %v", err)
+	}
+	if err := repos.Users.Create(&user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return &user
+}
+
+// createTestActivity stores an activity in a new category of the user
+func createTestActivity(t *testing.T, repos *repository.Repositories, userID uint, name string) *models.Activity {
+	t.Helper()
+
+	category := models.Category{UserID: userID, Name: name + " category"}
+	if err := repos.DB.Create(&category).Error; err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	activity := models.Activity{UserID: userID, Name: name, MainCategoryID: category.ID}
+	if err := repos.Activities.Create(&activity, nil); err != nil {
+		t.Fatalf("create activity: %v", err)
+	}
+	return &activity
+}
+
+// createTestEntry stores a stopped time entry with the given paused seconds
+func createTestEntry(t *testing.T, repos *repository.Repositories, activity *models.Activity, start, end time.Time, pausedSeconds int64) *models.TimeEntry {
+	t.Helper()
+
+	entry := models.TimeEntry{
+		UserID:        activity.UserID,
+		ActivityID:    activity.ID,
+		StartTime:     start,
+		EndTime:       &end,
+		PausedSeconds: pausedSeconds,
+		Billable:      true,
+	}
+	if err := repos.TimeEntries.Create(&entry); err != nil {
+		t.Fatalf("create time entry: %v", err)
+	}
+	return &entry
+}
+
+// serve routes a single request to handler through chi so URL parameters resolve
+// userID is put in the request context the way the auth middleware does; 0 sends no user
+func serve(t *testing.T, handler http.HandlerFunc, method, pattern, target string, body interface{}, userID uint) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, target, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if userID != 0 {
+		req = req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+	}
+
+	router := chi.NewRouter()
+	router.Method(method, pattern, handler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeResponse checks the status code and decodes the JSON body
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, status int) map[string]interface{} {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body.String())
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	return body
+}
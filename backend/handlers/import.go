@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+// maxImportSize is the largest CSV file accepted by the import endpoint
+const maxImportSize = 10 << 20 // 10 MB
+
+type ImportHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// ImportTimeEntries imports a Toggl or Clockify CSV export uploaded as multipart form data
+// Form fields: file (required), source (toggl|clockify, detected if empty),
+// tz (timezone of the export, defaults to the user's timezone), dry_run (true to only preview),
+// date_format (DD/MM/YYYY or MM/DD/YYYY for slash dates, defaults to the user's date format if it is one of them,
+// otherwise detected from the file)
+func (h *ImportHandler) ImportTimeEntries(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid upload, send a CSV file up to 10 MB as 'file'")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	settings, ok := userSettings(w, h.Logger, h.Repos.DB, userID)
+	if !ok {
+		return
+	}
+
+	opts := services.ImportOptions{
+		Source:   services.ImportSource(r.FormValue("source")),
+		Location: settings.Location(),
+		DryRun:   r.FormValue("dry_run") == "true",
+	}
+
+	switch format := models.DateFormat(r.FormValue("date_format")); format {
+	case models.DateFormatDMY, models.DateFormatMDY:
+		opts.DateFormat = format
+	case "":
+		opts.DateFormat = settings.DateFormat
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date_format. Use: DD/MM/YYYY, MM/DD/YYYY")
+		return
+	}
+
+	if opts.Source != "" && opts.Source != services.ImportSourceToggl && opts.Source != services.ImportSourceClockify {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid source. Use: toggl, clockify")
+		return
+	}
+
+	if tz := r.FormValue("tz"); tz != "" {
+		if opts.Location, err = time.LoadLocation(tz); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid timezone")
+			return
+		}
+	}
+
+	result, err := services.ImportTimeEntries(h.Repos.DB, userID, file, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownImportFormat) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Unrecognized CSV format, expected a Toggl or Clockify detailed export")
+			return
+		}
+		if errors.Is(err, services.ErrAmbiguousImportDates) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Cannot tell whether dates are day or month first, set date_format to DD/MM/YYYY or MM/DD/YYYY")
+			return
+		}
+		h.Logger.Error("Failed to import time entries", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to import time entries")
+		return
+	}
+
+	h.Logger.Info("Imported time entries",
+		zap.Uint("user_id", userID),
+		zap.String("source", string(result.Source)),
+		zap.Bool("dry_run", result.DryRun),
+		zap.Int("imported", result.Imported),
+		zap.Int("duplicates", result.Duplicates),
+		zap.Int("failed", result.Failed),
+	)
+
+	utils.SuccessResponse(w, result)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"go.uber.org/zap"
+)
+
+// togglHeader is the header of a Toggl detailed CSV export
+const togglHeader = "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n"
+
+// uploadImport posts a CSV file with the form fields to the import endpoint as userID
+func uploadImport(t *testing.T, h *ImportHandler, csv string, fields map[string]string, userID uint) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			t.Fatalf("write field: %v", err)
+		}
+	}
+	file, err := form.CreateFormFile("file", "export.csv")
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	file.Write([]byte(csv))
+	if err := form.Close(); err != nil {
+		t.Fatalf("close form: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/import/time-entries", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+
+	rec := httptest.NewRecorder()
+	h.ImportTimeEntries(rec, req)
+	return rec
+}
+
+func TestImportReadsSlashDatesInOneOrderOnly(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ImportHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	// startOf returns the start time of every imported row
+	startOf := func(body map[string]interface{}) []interface{} {
+		var starts []interface{}
+		for _, row := range body["rows"].([]interface{}) {
+			starts = append(starts, row.(map[string]interface{})["start_time"])
+		}
+		return starts
+	}
+
+	// 13/02 can only be day first, so 03/02 is the 3rd of February as well
+	european := togglHeader +
+		"Me,me@example.com,Acme,Website,,,Yes,13/02/2024,09:00:00,13/02/2024,10:00:00,01:00:00,\n" +
+		"Me,me@example.com,Acme,Website,,,Yes,03/02/2024,09:00:00,03/02/2024,10:00:00,01:00:00,\n"
+	body := decodeResponse(t, uploadImport(t, h, european, map[string]string{"tz": "UTC", "dry_run": "true"}, user.ID), http.StatusOK)
+	if got := startOf(body); len(got) != 2 || got[0] != "2024-02-13T09:00:00Z" || got[1] != "2024-02-03T09:00:00Z" {
+		t.Errorf("starts = %v, want the 13th and 3rd of February", got)
+	}
+
+	// Nothing tells whether 03/02 is the 3rd of February or the 2nd of March
+	ambiguous := togglHeader + "Me,me@example.com,Acme,Website,,,Yes,03/02/2024,09:00:00,03/02/2024,10:00:00,01:00:00,\n"
+	decodeResponse(t, uploadImport(t, h, ambiguous, map[string]string{"tz": "UTC"}, user.ID), http.StatusBadRequest)
+	decodeResponse(t, uploadImport(t, h, ambiguous, map[string]string{"tz": "UTC", "date_format": "YYYY-MM-DD"}, user.ID), http.StatusBadRequest)
+
+	body = decodeResponse(t, uploadImport(t, h, ambiguous, map[string]string{"tz": "UTC", "date_format": "MM/DD/YYYY"}, user.ID), http.StatusOK)
+	if got := startOf(body); len(got) != 1 || got[0] != "2024-03-02T09:00:00Z" {
+		t.Errorf("starts = %v, want the 2nd of March", got)
+	}
+
+	// The user's date format is used when the upload does not name one
+	decodeResponse(t, serve(t, (&SettingsHandler{Logger: zap.NewNop(), Repos: repos}).UpdateSettings, http.MethodPut, "/settings", "/settings",
+		map[string]interface{}{"date_format": "DD/MM/YYYY"}, user.ID), http.StatusOK)
+	body = decodeResponse(t, uploadImport(t, h, ambiguous, map[string]string{"tz": "UTC", "dry_run": "true"}, user.ID), http.StatusOK)
+	if got := startOf(body); len(got) != 1 || got[0] != "2024-02-03T09:00:00Z" {
+		t.Errorf("starts = %v, want the 3rd of February", got)
+	}
+
+	// Month first dates in a day first file do not parse instead of being read the other way around
+	body = decodeResponse(t, uploadImport(t, h, togglHeader+"Me,me@example.com,Acme,Website,,,Yes,02/13/2024,09:00:00,02/13/2024,10:00:00,01:00:00,\n",
+		map[string]string{"tz": "UTC", "date_format": "DD/MM/YYYY"}, user.ID), http.StatusOK)
+	if body["failed"] != float64(1) || body["imported"] != float64(0) {
+		t.Errorf("result = %v, want the row to fail", body)
+	}
+}
+
+func TestImportKeepsTheBillableColumn(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ImportHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	toggl := togglHeader +
+		"Me,me@example.com,Acme,Website,,Billed,Yes,2024-02-05,09:00:00,2024-02-05,10:00:00,01:00:00,\n" +
+		"Me,me@example.com,Acme,Website,,Internal,No,2024-02-05,10:00:00,2024-02-05,11:00:00,01:00:00,\n" +
+		"Me,me@example.com,Acme,Website,,Unknown,Maybe,2024-02-05,11:00:00,2024-02-05,12:00:00,01:00:00,\n"
+	body := decodeResponse(t, uploadImport(t, h, toggl, map[string]string{"tz": "UTC"}, user.ID), http.StatusOK)
+	if body["imported"] != float64(2) || body["failed"] != float64(1) {
+		t.Fatalf("result = %v, want 2 imported and the invalid billable row failed", body)
+	}
+
+	// Exports without the column are billable
+	clockify := "Project,Client,Description,Task,User,Email,Tags,Start Date,Start Time,End Date,End Time,Duration (h)\n" +
+		"Website,Acme,Legacy,,Me,me@example.com,,2024-02-06,09:00:00,2024-02-06,10:00:00,01:00:00\n"
+	decodeResponse(t, uploadImport(t, h, clockify, map[string]string{"tz": "UTC"}, user.ID), http.StatusOK)
+
+	for notes, want := range map[string]bool{"Billed": true, "Internal": false, "Legacy": true} {
+		var entry models.TimeEntry
+		if err := repos.DB.Where("user_id = ? AND notes = ?", user.ID, notes).First(&entry).Error; err != nil {
+			t.Fatalf("find %s entry: %v", notes, err)
+		}
+		if entry.Billable != want {
+			t.Errorf("%s entry billable = %v, want %v", notes, entry.Billable, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type PasswordHandler struct {
+	Logger *zap.Logger
+	Mailer services.Mailer
+	Repos  *repository.Repositories
+}
+
+// ChangePasswordRequest represents the change password request body
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ForgotPasswordRequest represents the forgot password request body
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest represents the reset password request body
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePassword changes the current user's password after checking the current one
+// Other sessions and all API tokens are revoked; the session making the request stays signed in
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	sessionID := middleware.GetSessionIDFromContext(r)
+
+	var req ChangePasswordRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.NewPassword = strings.TrimSpace(req.NewPassword)
+	if len(req.NewPassword) < minPasswordLength {
+		utils.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Password must be at least %d characters", minPasswordLength))
+		return
+	}
+
+	err := services.ChangePassword(h.Repos.DB, userID, sessionID, strings.TrimSpace(req.CurrentPassword), req.NewPassword)
+	if err != nil {
+		if errors.Is(err, services.ErrIncorrectPassword) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Current password is incorrect")
+			return
+		}
+		h.Logger.Error("Failed to change password", zap.Uint("user_id", userID), zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to change password")
+		return
+	}
+
+	h.Logger.Info("Password changed", zap.Uint("user_id", userID))
+	utils.SuccessResponse(w, map[string]string{"message": "Password changed"})
+}
+
+// ForgotPassword emails a password reset link if an account exists for the email
+// The response is the same whether or not the account exists
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if req.Email == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+
+	// Failures are only logged so they don't reveal whether the account exists
+	if err := services.RequestPasswordReset(h.Repos.DB, h.Mailer, req.Email); err != nil {
+		h.Logger.Error("Failed to send password reset email", zap.Error(err))
+	}
+
+	utils.SuccessResponse(w, map[string]string{
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password using the token from a reset email
+// All sessions and API tokens of the user are revoked afterwards
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+	req.NewPassword = strings.TrimSpace(req.NewPassword)
+
+	if req.Token == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "token is required")
+		return
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		utils.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Password must be at least %d characters", minPasswordLength))
+		return
+	}
+
+	if err := services.ResetPassword(h.Repos.DB, req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid or expired reset token")
+			return
+		}
+		h.Logger.Error("Failed to reset password", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]string{"message": "Password has been reset, please log in again"})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+func TestPasswordChangesRevokeAPITokens(t *testing.T) {
+	repos := newTestRepos(t)
+	mailer := &services.LogMailer{Logger: zap.NewNop()}
+	h := &PasswordHandler{Logger: zap.NewNop(), Mailer: mailer, Repos: repos}
+	auth := &AuthHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	// newToken creates an API token and checks it authenticates
+	newToken := func() string {
+		t.Helper()
+		_, token, err := services.CreateAPIToken(repos.DB, user.ID, "script", []models.TokenScope{models.TokenScopeRead}, nil)
+		if err != nil {
+			t.Fatalf("create api token: %v", err)
+		}
+		if _, err := services.AuthenticateAPIToken(repos.DB, token); err != nil {
+			t.Fatalf("authenticate new api token: %v", err)
+		}
+		return token
+	}
+	expectRevoked := func(token string) {
+		t.Helper()
+		if _, err := services.AuthenticateAPIToken(repos.DB, token); !errors.Is(err, services.ErrInvalidAPIToken) {
+			t.Errorf("authenticate api token = %v, want ErrInvalidAPIToken", err)
+		}
+	}
+
+	// Logging out every session leaves the tokens of scripts and integrations alone
+	token := newToken()
+	decodeResponse(t, serve(t, auth.LogoutAll, http.MethodPost, "/auth/logout-all", "/auth/logout-all", nil, user.ID), http.StatusOK)
+	if _, err := services.AuthenticateAPIToken(repos.DB, token); err != nil {
+		t.Errorf("authenticate api token after logout-all: %v", err)
+	}
+
+	decodeResponse(t, serve(t, h.ForgotPassword, http.MethodPost, "/auth/forgot-password", "/auth/forgot-password",
+		map[string]string{"email": user.Email}, 0), http.StatusOK)
+	if len(mailer.Sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(mailer.Sent))
+	}
+	reset := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(mailer.Sent[0].Body)
+	if reset == nil {
+		t.Fatalf("no reset token in %q", mailer.Sent[0].Body)
+	}
+	decodeResponse(t, serve(t, h.ResetPassword, http.MethodPost, "/auth/reset-password", "/auth/reset-password",
+		map[string]string{"token": reset[1], "new_password": "new-secret123"}, 0), http.StatusOK)
+	expectRevoked(token)
+
+	token = newToken()
+	decodeResponse(t, serve(t, h.ChangePassword, http.MethodPost, "/auth/password", "/auth/password",
+		map[string]string{"current_password": "new-secret123", "new_password": "other-secret123"}, user.ID), http.StatusOK)
+	expectRevoked(token)
+}
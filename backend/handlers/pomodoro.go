@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type PomodoroHandler struct {
+	Logger *zap.Logger
+	Events *services.EventHub
+	Repos  *repository.Repositories
+}
+
+// StartPomodoroInput represents the input for starting a pomodoro cycle
+// Zero values fall back to the default 25/5/15 cycle
+type StartPomodoroInput struct {
+	ActivityID uint `json:"activity_id"`
+	services.PomodoroConfig
+}
+
+// GetPomodoro returns the user's active pomodoro session if any
+func (h *PomodoroHandler) GetPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	session, err := services.GetActivePomodoro(h.Repos.DB, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrNoActivePomodoro) {
+			utils.SuccessResponse(w, map[string]interface{}{
+				"pomodoro": nil,
+			})
+			return
+		}
+		h.Logger.Error("Failed to fetch pomodoro session", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch pomodoro session")
+		return
+	}
+
+	utils.SuccessResponse(w, h.sessionResponse(userID, session))
+}
+
+// StartPomodoro starts a new pomodoro cycle, or the next work phase after a break
+func (h *PomodoroHandler) StartPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input StartPomodoroInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	config := input.PomodoroConfig.WithDefaults()
+	if err := config.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session, err := services.StartPomodoro(h.Repos.DB, userID, input.ActivityID, config)
+	if err != nil {
+		h.handleError(w, err, "Failed to start pomodoro")
+		return
+	}
+
+	h.Logger.Info("Pomodoro phase started",
+		zap.Uint("session_id", session.ID),
+		zap.String("phase", string(session.Phase)),
+	)
+
+	utils.CreatedResponse(w, h.sessionResponse(userID, session))
+}
+
+// PausePomodoro pauses the current phase
+func (h *PomodoroHandler) PausePomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	session, err := services.PausePomodoro(h.Repos.DB, userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to pause pomodoro")
+		return
+	}
+
+	utils.SuccessResponse(w, h.sessionResponse(userID, session))
+}
+
+// ResumePomodoro resumes a paused phase
+func (h *PomodoroHandler) ResumePomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	session, err := services.ResumePomodoro(h.Repos.DB, userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to resume pomodoro")
+		return
+	}
+
+	utils.SuccessResponse(w, h.sessionResponse(userID, session))
+}
+
+// SkipPomodoro skips to the next phase of the cycle
+func (h *PomodoroHandler) SkipPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	session, err := services.SkipPomodoro(h.Repos.DB, userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to skip pomodoro phase")
+		return
+	}
+
+	utils.SuccessResponse(w, h.sessionResponse(userID, session))
+}
+
+// AbortPomodoro ends the current pomodoro cycle
+func (h *PomodoroHandler) AbortPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	session, err := services.AbortPomodoro(h.Repos.DB, userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to abort pomodoro")
+		return
+	}
+
+	utils.SuccessResponse(w, h.sessionResponse(userID, session))
+}
+
+// sessionResponse wraps the session in a response payload
+// Work phases that ended on the way, by running out or being skipped or aborted, are stopped entries,
+// so achievements are evaluated for them
+func (h *PomodoroHandler) sessionResponse(userID uint, session *models.PomodoroSession) map[string]interface{} {
+	response := map[string]interface{}{
+		"pomodoro": h.formatSession(session),
+	}
+	if session.ClosedWorkEntry {
+		response["achievements_unlocked"] = unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+	}
+	return response
+}
+
+// formatSession builds the response payload with the live remaining time of the phase
+func (h *PomodoroHandler) formatSession(session *models.PomodoroSession) map[string]interface{} {
+	remaining := services.PhaseRemainingSeconds(session, time.Now())
+
+	return map[string]interface{}{
+		"id":                  session.ID,
+		"activity_id":         session.ActivityID,
+		"phase":               session.Phase,
+		"status":              session.Status,
+		"completed_pomodoros": session.CompletedPomodoros,
+		"phase_started_at":    session.PhaseStartedAt,
+		"phase_ends_at":       session.PhaseEndsAt,
+		"remaining_seconds":   remaining,
+		"remaining":           utils.FormatDuration(remaining),
+		"time_entry_id":       session.TimeEntryID,
+		"ended_at":            session.EndedAt,
+		"config": services.PomodoroConfig{
+			WorkMinutes:       session.WorkMinutes,
+			ShortBreakMinutes: session.ShortBreakMinutes,
+			LongBreakMinutes:  session.LongBreakMinutes,
+			LongBreakEvery:    session.LongBreakEvery,
+		},
+	}
+}
+
+// handleError maps pomodoro service errors to HTTP responses
+func (h *PomodoroHandler) handleError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrActivityNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
+	case errors.Is(err, services.ErrNoActivePomodoro):
+		utils.ErrorResponse(w, http.StatusNotFound, "No active pomodoro session")
+	case errors.Is(err, services.ErrPomodoroAlreadyActive):
+		utils.ErrorResponse(w, http.StatusConflict, "A pomodoro session is already running")
+	case errors.Is(err, services.ErrInvalidPomodoroAction):
+		utils.ErrorResponse(w, http.StatusConflict, "Action not allowed in the current pomodoro state")
+	default:
+		h.Logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+func TestStartPomodoroOnlyForOwnActivities(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &PomodoroHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	other := createTestUser(t, repos, "other@example.com")
+	theirs := createTestActivity(t, repos, other.ID, "Theirs")
+	mine := createTestActivity(t, repos, user.ID, "Mine")
+
+	decodeResponse(t, serve(t, h.StartPomodoro, http.MethodPost, "/pomodoro/start", "/pomodoro/start",
+		map[string]interface{}{"activity_id": theirs.ID}, user.ID), http.StatusNotFound)
+
+	body := decodeResponse(t, serve(t, h.StartPomodoro, http.MethodPost, "/pomodoro/start", "/pomodoro/start",
+		map[string]interface{}{"activity_id": mine.ID}, user.ID), http.StatusCreated)
+	pomodoro := body["pomodoro"].(map[string]interface{})
+	if pomodoro["activity_id"] != float64(mine.ID) || pomodoro["phase"] != "work" {
+		t.Errorf("pomodoro = %v, want a work phase on activity %d", pomodoro, mine.ID)
+	}
+}
+
+func TestFinishedWorkPhasesUnlockAchievements(t *testing.T) {
+	repos := newTestRepos(t)
+	hub := services.NewEventHub()
+	h := &PomodoroHandler{Logger: zap.NewNop(), Events: hub, Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Study")
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	createTestEntry(t, repos, activity, yesterday, yesterday.Add(40*time.Minute), 0)
+
+	body := decodeResponse(t, serve(t, h.StartPomodoro, http.MethodPost, "/pomodoro/start", "/pomodoro/start",
+		map[string]interface{}{"activity_id": activity.ID}, user.ID), http.StatusCreated)
+	if _, ok := body["achievements_unlocked"]; ok {
+		t.Errorf("response = %v, want no achievements while the work phase runs", body)
+	}
+
+	// The work phase ran out a minute ago while nobody was looking, making the first hour
+	pomodoro := body["pomodoro"].(map[string]interface{})
+	started := time.Now().Add(-26 * time.Minute)
+	ended := started.Add(25 * time.Minute)
+	if err := repos.DB.Model(&models.PomodoroSession{}).Where("id = ?", pomodoro["id"]).
+		Updates(map[string]interface{}{"phase_started_at": started, "phase_ends_at": ended}).Error; err != nil {
+		t.Fatalf("backdate session: %v", err)
+	}
+	if err := repos.DB.Model(&models.TimeEntry{}).Where("id = ?", pomodoro["time_entry_id"]).Update("start_time", started).Error; err != nil {
+		t.Fatalf("backdate entry: %v", err)
+	}
+
+	events, unsubscribe := hub.Subscribe(user.ID)
+	defer unsubscribe()
+
+	body = decodeResponse(t, serve(t, h.GetPomodoro, http.MethodGet, "/pomodoro", "/pomodoro", nil, user.ID), http.StatusOK)
+	unlocked, _ := body["achievements_unlocked"].([]interface{})
+	if len(unlocked) != 1 || unlocked[0].(map[string]interface{})["key"] != "first_hour" {
+		t.Fatalf("achievements unlocked = %v, want first_hour", body["achievements_unlocked"])
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want the unlock announced", len(events))
+	}
+	if event := <-events; event.Type != services.EventAchievementUnlocked {
+		t.Errorf("event = %s, want %s", event.Type, services.EventAchievementUnlocked)
+	}
+}
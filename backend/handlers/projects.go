@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type ProjectHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// ProjectInput represents the input for creating or replacing a project
+// A left out rate or currency falls back to the client's
+type ProjectInput struct {
+	ClientID        uint   `json:"client_id"`
+	Name            string `json:"name"`
+	HourlyRateCents *int64 `json:"hourly_rate_cents"`
+	Currency        string `json:"currency"`
+}
+
+// apply copies the input onto the project
+func (input *ProjectInput) apply(project *models.Project) {
+	project.ClientID = input.ClientID
+	project.Name = strings.TrimSpace(input.Name)
+	project.HourlyRateCents = input.HourlyRateCents
+	project.Currency = strings.ToUpper(input.Currency)
+}
+
+// GetProjects returns the user's projects
+// Query params: client_id to only return the projects of one client
+func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var clientID uint64
+	if value := r.URL.Query().Get("client_id"); value != "" {
+		var err error
+		if clientID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client_id")
+			return
+		}
+	}
+
+	projects, err := services.ListProjects(h.Repos.DB, userID, uint(clientID))
+	if err != nil {
+		h.Logger.Error("Failed to fetch projects", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch projects")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// CreateProject creates a project for one of the user's clients
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input ProjectInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project := models.Project{UserID: userID}
+	input.apply(&project)
+
+	if !h.saveProject(w, &project) {
+		return
+	}
+
+	utils.CreatedResponse(w, project)
+}
+
+// UpdateProject replaces a project's client, name, rate and currency
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var input ProjectInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project, err := services.FindProject(h.Repos.DB, userID, uint(id))
+	if errors.Is(err, services.ErrProjectNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to fetch project", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update project")
+		return
+	}
+
+	input.apply(project)
+
+	if !h.saveProject(w, project) {
+		return
+	}
+
+	utils.SuccessResponse(w, project)
+}
+
+// DeleteProject soft deletes a project
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	err = services.DeleteProject(h.Repos.DB, userID, uint(id))
+	if errors.Is(err, services.ErrProjectNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to delete project", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete project")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]string{
+		"message": "Project deleted successfully",
+	})
+}
+
+// saveProject validates and stores a project
+// Writes the error response and returns false when the project is invalid or cannot be saved
+func (h *ProjectHandler) saveProject(w http.ResponseWriter, project *models.Project) bool {
+	if err := project.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	err := services.SaveProject(h.Repos.DB, project)
+	if errors.Is(err, services.ErrClientNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client not found")
+		return false
+	}
+	if err != nil {
+		h.Logger.Error("Failed to save project", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save project")
+		return false
+	}
+	return true
+}
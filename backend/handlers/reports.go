@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReportHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// GetReport returns tracked time for an arbitrary date range grouped up to two levels deep
+// Query params: from, to (YYYY-MM-DD or RFC3339, to is inclusive for plain dates),
+// group_by (e.g. "main_category,week"), activity_id, category_id, tag_id (comma-separated IDs),
+// tz (defaults to the user's timezone); weeks begin on the user's week start
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	query := r.URL.Query()
+
+	settings, ok := userSettings(w, h.Logger, h.Repos.DB, userID)
+	if !ok {
+		return
+	}
+
+	loc, ok := parseTimezone(w, query, settings.Location())
+	if !ok {
+		return
+	}
+
+	filter, ok := parseReportFilter(w, query, loc)
+	if !ok {
+		return
+	}
+	filter.WeekStart = settings.WeekStart
+
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = string(services.GroupByActivity)
+	}
+	for _, part := range strings.Split(groupBy, ",") {
+		dim := services.ReportDimension(strings.TrimSpace(part))
+		if !dim.IsValid() {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid group_by. Use: activity, main_category, sub_category, tag, day, week, month")
+			return
+		}
+		filter.GroupBy = append(filter.GroupBy, dim)
+	}
+
+	if len(filter.GroupBy) > services.MaxReportDepth {
+		utils.ErrorResponse(w, http.StatusBadRequest, "group_by supports at most 2 levels")
+		return
+	}
+
+	report, err := services.BuildReport(h.Repos.DB, userID, filter)
+	if err != nil {
+		h.Logger.Error("Failed to build report", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build report")
+		return
+	}
+
+	utils.SuccessResponse(w, report)
+}
+
+// userSettings returns the settings the user's dates are resolved with
+// It writes the error response and returns false when they cannot be loaded
+func userSettings(w http.ResponseWriter, logger *zap.Logger, db *gorm.DB, userID uint) (*models.UserSettings, bool) {
+	settings, err := services.GetUserSettings(db, userID)
+	if err != nil {
+		logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get user settings")
+		return nil, false
+	}
+	return settings, true
+}
+
+// parseTimezone reads the tz param, falling back to loc when it is not given
+// It writes the error response and returns false when the zone is unknown
+func parseTimezone(w http.ResponseWriter, query url.Values, loc *time.Location) (*time.Location, bool) {
+	tz := query.Get("tz")
+	if tz == "" {
+		return loc, true
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid timezone")
+		return nil, false
+	}
+	return loc, true
+}
+
+// parseReportFilter reads the date range and the activity, category and tag filters shared by reports and analytics
+// from and to are YYYY-MM-DD or RFC3339, to is inclusive for plain dates; the IDs are comma-separated
+// It writes the error response and returns false when a param is missing or invalid
+func parseReportFilter(w http.ResponseWriter, query url.Values, loc *time.Location) (services.ReportFilter, bool) {
+	filter := services.ReportFilter{Location: loc}
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "from and to are required")
+		return filter, false
+	}
+
+	var err error
+	if filter.From, err = utils.ParseDateParam(query.Get("from"), loc, false); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return filter, false
+	}
+	if filter.To, err = utils.ParseDateParam(query.Get("to"), loc, true); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return filter, false
+	}
+	if !filter.To.After(filter.From) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "to must be after from")
+		return filter, false
+	}
+
+	if filter.ActivityIDs, err = utils.ParseIDList(query.Get("activity_id")); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid activity_id")
+		return filter, false
+	}
+	if filter.CategoryIDs, err = utils.ParseIDList(query.Get("category_id")); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid category_id")
+		return filter, false
+	}
+	if filter.TagIDs, err = utils.ParseIDList(query.Get("tag_id")); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid tag_id")
+		return filter, false
+	}
+
+	return filter, true
+}
@@ -2,15 +2,17 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
-	"github.com/Felipalds/go-pomodoro/database"
 	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/utils"
 	"go.uber.org/zap"
 )
 
 type ResumeHandler struct {
 	Logger *zap.Logger
+	Repos  *repository.Repositories
 }
 
 type ActivityResume struct {
@@ -45,39 +47,18 @@ func (h *ResumeHandler) GetResume(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startDate, endDate := utils.GetPeriodDateRange(period)
-
-	// Query to get activity totals within the period
-	type ActivityTotal struct {
-		ActivityID   uint
-		ActivityName string
-		TotalSeconds int64
-		EntryCount   int
+	settings, err := h.Repos.Users.FindSettings(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get resume data")
+		return
 	}
 
-	var activityTotals []ActivityTotal
-
-	err := database.DB.Table("time_entries").
-		Select(`
-			time_entries.activity_id,
-			activities.name as activity_name,
-			SUM(CASE
-				WHEN time_entries.end_time IS NOT NULL
-				THEN EXTRACT(EPOCH FROM (time_entries.end_time - time_entries.start_time))::INTEGER
-				ELSE 0
-			END) as total_seconds,
-			COUNT(time_entries.id) as entry_count
-		`).
-		Joins("JOIN activities ON activities.id = time_entries.activity_id").
-		Where("time_entries.user_id = ?", userID).
-		Where("time_entries.start_time >= ? AND time_entries.start_time <= ?", startDate, endDate).
-		Where("time_entries.end_time IS NOT NULL").
-		Where("activities.deleted_at IS NULL").
-		Group("time_entries.activity_id, activities.name").
-		Order("total_seconds DESC").
-		Limit(3).
-		Scan(&activityTotals).Error
+	// Periods start at midnight in the user's timezone, weeks on their chosen day
+	startDate, endDate := utils.GetPeriodDateRange(period, time.Now().In(settings.Location()), settings.WeekStart)
 
+	// Top activities by time within the period
+	activityTotals, err := h.Repos.TimeEntries.TopActivities(userID, startDate, endDate, 3)
 	if err != nil {
 		h.Logger.Error("Failed to get resume data", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get resume data")
@@ -85,21 +66,12 @@ func (h *ResumeHandler) GetResume(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Calculate total time across all activities in period (not just top 3)
-	var overallTotal int64
-	database.DB.Table("time_entries").
-		Select(`
-			COALESCE(SUM(CASE
-				WHEN time_entries.end_time IS NOT NULL
-				THEN EXTRACT(EPOCH FROM (time_entries.end_time - time_entries.start_time))::INTEGER
-				ELSE 0
-			END), 0) as total
-		`).
-		Joins("JOIN activities ON activities.id = time_entries.activity_id").
-		Where("time_entries.user_id = ?", userID).
-		Where("time_entries.start_time >= ? AND time_entries.start_time <= ?", startDate, endDate).
-		Where("time_entries.end_time IS NOT NULL").
-		Where("activities.deleted_at IS NULL").
-		Scan(&overallTotal)
+	overallTotal, err := h.Repos.TimeEntries.TotalSeconds(userID, startDate, endDate)
+	if err != nil {
+		h.Logger.Error("Failed to get resume data", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get resume data")
+		return
+	}
 
 	// Build response
 	activities := make([]ActivityResume, 0, len(activityTotals))
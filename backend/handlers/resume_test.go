@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestGetResumeRanksActivitiesByTrackedTime(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ResumeHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	reading := createTestActivity(t, repos, user.ID, "Reading")
+	coding := createTestActivity(t, repos, user.ID, "Coding")
+	deleted := createTestActivity(t, repos, user.ID, "Old")
+
+	// Short entries just before now stay inside the current day
+	now := time.Now()
+	if now.Add(-20*time.Minute).Day() != now.Day() {
+		t.Skip("entries would cross midnight")
+	}
+	createTestEntry(t, repos, reading, now.Add(-20*time.Minute), now.Add(-15*time.Minute), 0)
+	createTestEntry(t, repos, coding, now.Add(-14*time.Minute), now.Add(-2*time.Minute), 2*60)
+	createTestEntry(t, repos, deleted, now.Add(-90*time.Second), now.Add(-30*time.Second), 0)
+	if err := repos.Activities.Delete(deleted); err != nil {
+		t.Fatalf("delete activity: %v", err)
+	}
+
+	body := decodeResponse(t, serve(t, h.GetResume, http.MethodGet, "/resume", "/resume?period=day", nil, user.ID), http.StatusOK)
+
+	if body["total_seconds"] != float64(15*60) {
+		t.Errorf("total_seconds = %v, want %d", body["total_seconds"], 15*60)
+	}
+
+	activities := body["activities"].([]interface{})
+	if len(activities) != 2 {
+		t.Fatalf("got %d activities, want 2", len(activities))
+	}
+	top := activities[0].(map[string]interface{})
+	if top["activity_name"] != "Coding" || top["total_seconds"] != float64(10*60) {
+		t.Errorf("top activity = %v with %v seconds, want Coding with %d", top["activity_name"], top["total_seconds"], 10*60)
+	}
+}
+
+func TestGetResumeRejectsUnknownPeriod(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ResumeHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	decodeResponse(t, serve(t, h.GetResume, http.MethodGet, "/resume", "/resume?period=decade", nil, user.ID), http.StatusBadRequest)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RewardCatalogHandler struct {
+	Logger   *zap.Logger
+	Catalogs *services.RewardCatalogRegistry
+	Repos    *repository.Repositories
+}
+
+// catalogSummary describes a catalog in listings
+func catalogSummary(catalog services.RewardCatalog) map[string]interface{} {
+	source := "builtin"
+	if generic, ok := catalog.(*services.GenericCatalog); ok {
+		source = "admin"
+		if generic.Path() != "" {
+			source = "file"
+		}
+	}
+
+	return map[string]interface{}{
+		"key":    catalog.Key(),
+		"name":   catalog.Name(),
+		"size":   catalog.Size(),
+		"source": source,
+	}
+}
+
+// GetCatalogs returns the catalogs the user can draw rewards from and the selected one
+func (h *RewardCatalogHandler) GetCatalogs(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	user, err := h.Repos.Users.FindByID(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	selected := user.RewardCatalog
+	if catalog := h.Catalogs.ForUser(user.RewardCatalog); catalog != nil {
+		selected = catalog.Key()
+	}
+
+	catalogs := []map[string]interface{}{}
+	for _, catalog := range h.Catalogs.List() {
+		catalogs = append(catalogs, catalogSummary(catalog))
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"selected": selected,
+		"catalogs": catalogs,
+	})
+}
+
+// GetCatalog returns the rewards of a custom catalog
+func (h *RewardCatalogHandler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	catalog, ok := h.Catalogs.Get(chi.URLParam(r, "key"))
+	if !ok {
+		utils.ErrorResponse(w, http.StatusNotFound, "Catalog not found")
+		return
+	}
+
+	response := catalogSummary(catalog)
+	if generic, ok := catalog.(*services.GenericCatalog); ok {
+		response["rewards"] = generic.Items()
+	}
+
+	utils.SuccessResponse(w, response)
+}
+
+// SelectCatalog changes the catalog the user's rewards are drawn from
+func (h *RewardCatalogHandler) SelectCatalog(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input struct {
+		Catalog string `json:"catalog"`
+	}
+
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	catalog, ok := h.Catalogs.Get(input.Catalog)
+	if !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Unknown reward catalog")
+		return
+	}
+
+	if err := h.Repos.Users.SetRewardCatalog(userID, catalog.Key()); err != nil {
+		h.Logger.Error("Failed to select reward catalog", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to select reward catalog")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"selected": catalog.Key(),
+		"catalog":  catalogSummary(catalog),
+	})
+}
+
+// SaveCatalog creates or replaces a custom catalog (admin only)
+// Built-in catalogs and catalogs loaded from files cannot be replaced
+func (h *RewardCatalogHandler) SaveCatalog(w http.ResponseWriter, r *http.Request) {
+	var definition services.RewardCatalogDefinition
+	if err := utils.DecodeJSON(r, &definition); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	definition.Key = chi.URLParam(r, "key")
+	if err := definition.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, exists := h.Catalogs.Get(definition.Key)
+	if exists && !isStoredCatalog(existing) {
+		utils.ErrorResponse(w, http.StatusConflict, "Catalog is not managed through the API")
+		return
+	}
+
+	record := models.RewardCatalogRecord{
+		Key:   definition.Key,
+		Name:  definition.Name,
+		Items: definition.Items(),
+	}
+	if err := h.Repos.Catalogs.Save(&record); err != nil {
+		h.Logger.Error("Failed to save reward catalog", zap.String("key", definition.Key), zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save reward catalog")
+		return
+	}
+
+	catalog := services.NewGenericCatalogFromRecord(&record)
+	h.Catalogs.Register(catalog)
+
+	if exists {
+		utils.SuccessResponse(w, catalogSummary(catalog))
+		return
+	}
+	utils.CreatedResponse(w, catalogSummary(catalog))
+}
+
+// DeleteCatalog removes a custom catalog (admin only)
+// Users who selected it go back to the default catalog
+func (h *RewardCatalogHandler) DeleteCatalog(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "key")
+
+	existing, exists := h.Catalogs.Get(key)
+	if exists && !isStoredCatalog(existing) {
+		utils.ErrorResponse(w, http.StatusConflict, "Catalog is not managed through the API")
+		return
+	}
+
+	if err := h.Repos.Catalogs.Delete(key, services.DefaultRewardCatalogKey); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Catalog not found")
+			return
+		}
+		h.Logger.Error("Failed to delete reward catalog", zap.String("key", key), zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete reward catalog")
+		return
+	}
+
+	h.Catalogs.Remove(key)
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"message": "Catalog deleted successfully",
+	})
+}
+
+// isStoredCatalog reports whether the catalog is stored in the database rather than built in or loaded from a file
+func isStoredCatalog(catalog services.RewardCatalog) bool {
+	generic, ok := catalog.(*services.GenericCatalog)
+	return ok && generic.Path() == ""
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+// newTestCatalogs returns a registry with an empty League of Legends catalog
+func newTestCatalogs() *services.RewardCatalogRegistry {
+	return services.NewRewardCatalogRegistry(&services.DataDragonService{})
+}
+
+func TestSaveCatalogAndClaimFromIt(t *testing.T) {
+	repos := newTestRepos(t)
+	catalogs := newTestCatalogs()
+	catalogHandler := &RewardCatalogHandler{Logger: zap.NewNop(), Catalogs: catalogs, Repos: repos}
+	rewardHandler := &RewardHandler{Logger: zap.NewNop(), Catalogs: catalogs, Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Reading")
+
+	definition := map[string]interface{}{
+		"name": "Books",
+		"rewards": []map[string]string{
+			{"id": "dune", "type": "novel", "name": "Dune", "rarity": "epic"},
+			{"id": "sapiens", "type": "nonfiction", "name": "Sapiens"},
+		},
+	}
+	body := decodeResponse(t, serve(t, catalogHandler.SaveCatalog, http.MethodPut, "/admin/reward-catalogs/{key}",
+		"/admin/reward-catalogs/books", definition, user.ID), http.StatusCreated)
+	if body["size"] != float64(2) || body["source"] != "admin" {
+		t.Errorf("saved catalog = %v, want 2 rewards managed by admin", body)
+	}
+
+	// Catalogs stored in the database are reloaded on startup
+	records, err := repos.Catalogs.List()
+	if err != nil || len(records) != 1 || len(records[0].Items) != 2 {
+		t.Fatalf("stored catalogs = %v, %v; want books with 2 rewards", records, err)
+	}
+
+	decodeResponse(t, serve(t, catalogHandler.SelectCatalog, http.MethodPut, "/rewards/catalog", "/rewards/catalog",
+		map[string]string{"catalog": "books"}, user.ID), http.StatusOK)
+
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-20*time.Minute), end, 0)
+
+	body = decodeResponse(t, serve(t, rewardHandler.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": activity.ID}, user.ID), http.StatusOK)
+	reward := body["reward"].(map[string]interface{})
+	if reward["catalog"] != "books" {
+		t.Errorf("reward catalog = %v, want books", reward["catalog"])
+	}
+	if id := reward["external_id"]; id != "dune" && id != "sapiens" {
+		t.Errorf("reward external_id = %v, want a book from the catalog", id)
+	}
+
+	// Deleting the catalog moves the user back to the default one
+	decodeResponse(t, serve(t, catalogHandler.DeleteCatalog, http.MethodDelete, "/admin/reward-catalogs/{key}",
+		"/admin/reward-catalogs/books", nil, user.ID), http.StatusOK)
+	body = decodeResponse(t, serve(t, catalogHandler.GetCatalogs, http.MethodGet, "/rewards/catalogs", "/rewards/catalogs", nil, user.ID), http.StatusOK)
+	if body["selected"] != services.DefaultRewardCatalogKey {
+		t.Errorf("selected = %v, want %s", body["selected"], services.DefaultRewardCatalogKey)
+	}
+}
+
+func TestSaveCatalogValidatesDefinition(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &RewardCatalogHandler{Logger: zap.NewNop(), Catalogs: newTestCatalogs(), Repos: repos}
+	user := createTestUser(t, repos, "admin@example.com")
+
+	invalid := map[string]interface{}{
+		"name":    "Books",
+		"rewards": []map[string]string{{"id": "dune", "type": "novel", "name": "Dune", "rarity": "mythic"}},
+	}
+	decodeResponse(t, serve(t, h.SaveCatalog, http.MethodPut, "/admin/reward-catalogs/{key}",
+		"/admin/reward-catalogs/books", invalid, user.ID), http.StatusBadRequest)
+
+	// The League of Legends catalog is built in and cannot be replaced
+	valid := map[string]interface{}{
+		"name":    "Not LoL",
+		"rewards": []map[string]string{{"id": "dune", "type": "novel", "name": "Dune"}},
+	}
+	decodeResponse(t, serve(t, h.SaveCatalog, http.MethodPut, "/admin/reward-catalogs/{key}",
+		"/admin/reward-catalogs/"+services.DataDragonCatalogKey, valid, user.ID), http.StatusConflict)
+}
+
+func TestSelectCatalogFromFile(t *testing.T) {
+	repos := newTestRepos(t)
+	catalogs := newTestCatalogs()
+	h := &RewardCatalogHandler{Logger: zap.NewNop(), Catalogs: catalogs, Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	dir := t.TempDir()
+	yamlCatalog := `name: Board games
+rewards:
+  - id: catan
+    type: game
+    name: Catan
+    rarity: rare
+    image_url: https://example.com/catan.png
+`
+	if err := os.WriteFile(filepath.Join(dir, "boardgames.yaml"), []byte(yamlCatalog), 0o644); err != nil {
+		t.Fatalf("write catalog file: %v", err)
+	}
+	fileCatalogs, err := services.LoadGenericCatalogDir(dir)
+	if err != nil || len(fileCatalogs) != 1 {
+		t.Fatalf("load catalog files = %v, %v; want one catalog", fileCatalogs, err)
+	}
+	catalogs.Register(fileCatalogs[0])
+
+	decodeResponse(t, serve(t, h.SelectCatalog, http.MethodPut, "/rewards/catalog", "/rewards/catalog",
+		map[string]string{"catalog": "unknown"}, user.ID), http.StatusBadRequest)
+	decodeResponse(t, serve(t, h.SelectCatalog, http.MethodPut, "/rewards/catalog", "/rewards/catalog",
+		map[string]string{"catalog": "boardgames"}, user.ID), http.StatusOK)
+
+	body := decodeResponse(t, serve(t, h.GetCatalogs, http.MethodGet, "/rewards/catalogs", "/rewards/catalogs", nil, user.ID), http.StatusOK)
+	if body["selected"] != "boardgames" {
+		t.Errorf("selected = %v, want boardgames", body["selected"])
+	}
+	if got := len(body["catalogs"].([]interface{})); got != 2 {
+		t.Errorf("got %d catalogs, want 2", got)
+	}
+
+	// File catalogs are not managed through the admin API
+	decodeResponse(t, serve(t, h.DeleteCatalog, http.MethodDelete, "/admin/reward-catalogs/{key}",
+		"/admin/reward-catalogs/boardgames", nil, user.ID), http.StatusConflict)
+}
+
+func TestAdminRoutesRequireAnAdminLoggedInWithAPassword(t *testing.T) {
+	repos := newTestRepos(t)
+	admin := createTestUser(t, repos, "admin@example.com")
+	user := createTestUser(t, repos, "user@example.com")
+	t.Setenv("ADMIN_EMAILS", "someone@example.com, ADMIN@example.com")
+
+	handler := middleware.RequireAdmin(repos.Users)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.SuccessResponse(w, map[string]string{"message": "ok"})
+	}))
+
+	for _, test := range []struct {
+		userID     uint
+		apiTokenID uint
+		status     int
+	}{
+		{admin.ID, 0, http.StatusOK},
+		{user.ID, 0, http.StatusForbidden},
+		{admin.ID, 1, http.StatusForbidden}, // A leaked API token of an admin is not enough
+	} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/admin/reward-catalogs/books", nil)
+		ctx := context.WithValue(req.Context(), "user_id", test.userID)
+		if test.apiTokenID != 0 {
+			ctx = context.WithValue(ctx, "api_token_id", test.apiTokenID)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+		if rec.Code != test.status {
+			t.Errorf("user %d with API token %d: status = %d, want %d", test.userID, test.apiTokenID, rec.Code, test.status)
+		}
+	}
+}
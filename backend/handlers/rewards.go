@@ -1,19 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
-	"github.com/Felipalds/go-pomodoro/database"
 	"github.com/Felipalds/go-pomodoro/middleware"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/Felipalds/go-pomodoro/utils"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type RewardHandler struct {
-	Logger    *zap.Logger
-	DDService *services.DataDragonService
+	Logger   *zap.Logger
+	Catalogs *services.RewardCatalogRegistry
+	Events   *services.EventHub
+	Repos    *repository.Repositories
 }
 
 // ClaimReward claims a reward for an activity
@@ -30,92 +36,153 @@ func (h *RewardHandler) ClaimReward(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if activity exists and belongs to user
-	var activity models.Activity
-	if err := database.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", input.ActivityID, userID).First(&activity).Error; err != nil {
+	activity, err := h.Repos.Activities.Find(userID, input.ActivityID)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
 	}
 
-	// Calculate claimable rewards
-	claimable, progress, totalMinutes, err := services.CalculateClaimableRewards(database.DB, input.ActivityID)
+	// Calculate claimable rewards; the claim itself checks again with the activity locked
+	totalSeconds, err := h.Repos.TimeEntries.ActivitySeconds(activity.ID)
 	if err != nil {
 		h.Logger.Error("Failed to calculate claimable rewards", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to calculate rewards")
 		return
 	}
+	claimable, progress, _ := services.CalculateClaimableRewards(totalSeconds, activity.IntervalsRewarded)
 
 	if claimable <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		utils.EncodeJSON(w, map[string]interface{}{
-			"error":                "No rewards available. Keep tracking time!",
-			"next_reward_progress": progress,
-		})
+		noRewardsResponse(w, progress)
+		return
+	}
+
+	user, err := h.Repos.Users.FindByID(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
 		return
 	}
 
-	// Generate reward based on total minutes (affects drop rates)
-	result, err := services.GenerateReward(database.DB, h.DDService, userID, totalMinutes)
-	if err != nil || result == nil {
-		h.Logger.Error("Failed to generate reward", zap.Error(err))
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate reward")
+	// Draw a reward from the user's catalog; the activity is locked until the interval is counted
+	claimed, intervalsRemaining, err := services.ClaimActivityReward(h.Repos.DB, h.trackedSeconds, h.Catalogs.ForUser(user.RewardCatalog), userID, activity.ID)
+	if errors.Is(err, services.ErrNoRewardsAvailable) {
+		// A concurrent claim spent the last interval first
+		noRewardsResponse(w, progress)
+		return
+	}
+	if errors.Is(err, services.ErrActivityNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
 	}
+	if errors.Is(err, services.ErrCatalogUnavailable) {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, "Rewards are not available yet, try again later")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to claim reward", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to claim reward")
+		return
+	}
+
+	response := map[string]interface{}{
+		"reward":              claimedRewardResponse(claimed),
+		"activity_id":         activity.ID,
+		"pity":                pityStatus(claimed.Result.Pity),
+		"intervals_remaining": intervalsRemaining,
+		"total_minutes":       claimed.TotalMinutes,
+	}
+	response["achievements_unlocked"] = unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+	h.Events.Publish(userID, services.EventRewardClaimed, response)
 
-	// Save reward to database
-	reward := models.UserReward{
-		UserID:     userID,
-		RewardType: result.RewardType,
-		ExternalID: result.ExternalID,
-		Name:       result.Name,
-		ImageURL:   result.ImageURL,
-		Rarity:     result.Rarity,
+	utils.SuccessResponse(w, response)
+}
+
+// ClaimAllRewards claims every available reward across the user's activities in one go
+// Either all of them are claimed or, on failure, none is
+func (h *RewardHandler) ClaimAllRewards(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	user, err := h.Repos.Users.FindByID(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
 	}
 
-	if err := database.DB.Create(&reward).Error; err != nil {
-		h.Logger.Error("Failed to save reward", zap.Error(err))
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save reward")
+	claimed, err := services.ClaimAllRewards(h.Repos.DB, h.trackedSeconds, h.Catalogs.ForUser(user.RewardCatalog), userID)
+	if errors.Is(err, services.ErrNoRewardsAvailable) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "No rewards available. Keep tracking time!")
+		return
+	}
+	if errors.Is(err, services.ErrCatalogUnavailable) {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, "Rewards are not available yet, try again later")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to claim rewards", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to claim rewards")
 		return
 	}
 
-	// Increment intervals_rewarded for the activity
-	activity.IntervalsRewarded++
-	database.DB.Save(&activity)
+	rewards := make([]map[string]interface{}, 0, len(claimed))
+	claimedByActivity := make(map[uint]int)
+	for i := range claimed {
+		reward := claimedRewardResponse(&claimed[i])
+		reward["activity_id"] = claimed[i].ActivityID
+		rewards = append(rewards, reward)
+		claimedByActivity[claimed[i].ActivityID]++
+	}
 
-	// Calculate remaining claimable
-	intervalsRemaining := claimable - 1
+	response := map[string]interface{}{
+		"rewards":             rewards,
+		"claimed":             len(claimed),
+		"claimed_by_activity": claimedByActivity,
+		"pity":                pityStatus(claimed[len(claimed)-1].Result.Pity),
+	}
+	response["achievements_unlocked"] = unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+	h.Events.Publish(userID, services.EventRewardsClaimedAll, response)
 
-	utils.SuccessResponse(w, map[string]interface{}{
-		"reward": map[string]interface{}{
-			"id":            reward.ID,
-			"reward_type":   reward.RewardType,
-			"external_id":   reward.ExternalID,
-			"name":          reward.Name,
-			"image_url":     reward.ImageURL,
-			"rarity":        reward.Rarity,
-			"is_duplicate":  result.IsDuplicate,
-			"mastery_level": result.MasteryLevel,
-		},
-		"intervals_remaining": intervalsRemaining,
-		"total_minutes":       totalMinutes,
+	utils.SuccessResponse(w, response)
+}
+
+// noRewardsResponse tells the user there is nothing to claim and how close the next reward is
+func noRewardsResponse(w http.ResponseWriter, progress float64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	utils.EncodeJSON(w, map[string]interface{}{
+		"error":                "No rewards available. Keep tracking time!",
+		"next_reward_progress": progress,
 	})
 }
 
+// claimedRewardResponse describes a claimed reward together with its duplicate and mastery info
+func claimedRewardResponse(claimed *services.ClaimedReward) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            claimed.Reward.ID,
+		"catalog":       claimed.Reward.Catalog,
+		"reward_type":   claimed.Reward.RewardType,
+		"external_id":   claimed.Reward.ExternalID,
+		"name":          claimed.Reward.Name,
+		"image_url":     claimed.Reward.ImageURL,
+		"rarity":        claimed.Reward.Rarity,
+		"is_duplicate":  claimed.Result.IsDuplicate,
+		"mastery_level": claimed.Result.MasteryLevel,
+	}
+}
+
 // GetRewards returns all user rewards and mastery info
 func (h *RewardHandler) GetRewards(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r)
 
 	// Get all rewards for this user
-	var rewards []models.UserReward
-	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&rewards).Error; err != nil {
+	rewards, err := h.Repos.Rewards.List(userID)
+	if err != nil {
 		h.Logger.Error("Failed to fetch rewards", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch rewards")
 		return
 	}
 
 	// Get all mastery records for this user
-	var mastery []models.ChampionMastery
-	if err := database.DB.Where("user_id = ?", userID).Order("mastery_level DESC, times_obtained DESC").Find(&mastery).Error; err != nil {
+	mastery, err := h.Repos.Rewards.ListMastery(userID)
+	if err != nil {
 		h.Logger.Error("Failed to fetch mastery", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch mastery")
 		return
@@ -141,19 +208,196 @@ func (h *RewardHandler) GetRewards(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetCollection returns the rewards the user owns, one per reward with how many copies they hold
+func (h *RewardHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	owned, err := h.Repos.Rewards.ListOwned(userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch collection", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch collection")
+		return
+	}
+
+	copies := 0
+	byRarity := map[models.Rarity]int{}
+	for _, reward := range owned {
+		copies += reward.Count
+		byRarity[reward.Rarity]++
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"collection": owned,
+		"stats": map[string]interface{}{
+			"unique_rewards": len(owned),
+			"total_copies":   copies,
+			"by_rarity":      byRarity,
+		},
+	})
+}
+
+// trackedSeconds counts tracked time for a claim inside its transaction with the same queries as the status
+func (h *RewardHandler) trackedSeconds(tx *gorm.DB) services.TrackedSeconds {
+	return h.Repos.TimeEntries.WithTx(tx)
+}
+
 // GetRewardStatus returns claimable rewards status
 func (h *RewardHandler) GetRewardStatus(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r)
 
-	activities, totalClaimable, err := services.GetAllClaimableRewards(database.DB, userID)
+	activities, err := h.Repos.Activities.List(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get reward status", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get reward status")
+		return
+	}
+
+	activitySeconds, err := h.Repos.TimeEntries.SecondsByActivity(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get reward status", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get reward status")
+		return
+	}
+
+	pity, err := h.Repos.Rewards.FindPity(userID)
 	if err != nil {
 		h.Logger.Error("Failed to get reward status", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get reward status")
 		return
 	}
 
+	claimableActivities, totalClaimable := services.GetAllClaimableRewards(activities, activitySeconds)
+
 	utils.SuccessResponse(w, map[string]interface{}{
 		"total_claimable": totalClaimable,
-		"activities":      activities,
+		"activities":      claimableActivities,
+		"pity":            pityStatus(pity),
+	})
+}
+
+// GetEssence returns the user's essence balance, latest ledger entries and the crafting prices
+func (h *RewardHandler) GetEssence(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	balance, err := services.GetEssenceBalance(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch essence balance", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch essence")
+		return
+	}
+
+	transactions, err := services.ListEssenceTransactions(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to fetch essence ledger", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch essence")
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]interface{}{
+		"balance":           balance,
+		"transactions":      transactions,
+		"disenchant_values": models.DisenchantValues,
+		"craft_costs":       models.CraftCosts,
 	})
 }
+
+// DisenchantReward turns a duplicate reward into essence
+func (h *RewardHandler) DisenchantReward(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	idStr := chi.URLParam(r, "id")
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid reward ID")
+		return
+	}
+
+	transaction, err := services.DisenchantReward(h.Repos.DB, userID, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRewardNotFound):
+			utils.ErrorResponse(w, http.StatusNotFound, "Reward not found")
+		case errors.Is(err, services.ErrRewardNotDuplicate):
+			utils.ErrorResponse(w, http.StatusConflict, "Only duplicate rewards can be disenchanted")
+		default:
+			h.Logger.Error("Failed to disenchant reward", zap.Error(err))
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to disenchant reward")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"reward_id":   uint(id),
+		"essence":     transaction.Amount,
+		"balance":     transaction.Balance,
+		"transaction": transaction,
+	}
+	h.Events.Publish(userID, services.EventRewardDisenchanted, response)
+
+	utils.SuccessResponse(w, response)
+}
+
+// CraftReward spends essence on a chosen reward of the user's catalog
+func (h *RewardHandler) CraftReward(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input struct {
+		RewardType models.RewardType `json:"reward_type"`
+		ExternalID string            `json:"external_id"`
+	}
+
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if input.RewardType == "" || input.ExternalID == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "reward_type and external_id are required")
+		return
+	}
+
+	user, err := h.Repos.Users.FindByID(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	reward, transaction, err := services.CraftReward(h.Repos.DB, h.Catalogs.ForUser(user.RewardCatalog), userID, input.RewardType, input.ExternalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRewardNotInCatalog), errors.Is(err, services.ErrRewardNotCraftable):
+			utils.ErrorResponse(w, http.StatusNotFound, "Reward not found in your catalog")
+		case errors.Is(err, services.ErrCatalogUnavailable):
+			utils.ErrorResponse(w, http.StatusServiceUnavailable, "Rewards are not available yet, try again later")
+		case errors.Is(err, services.ErrRewardAlreadyOwned):
+			utils.ErrorResponse(w, http.StatusConflict, "Reward is already in your collection")
+		case errors.Is(err, services.ErrInsufficientEssence):
+			utils.ErrorResponse(w, http.StatusBadRequest, "Not enough essence")
+		default:
+			h.Logger.Error("Failed to craft reward", zap.Error(err))
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to craft reward")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"reward":      reward,
+		"essence":     transaction.Amount,
+		"balance":     transaction.Balance,
+		"transaction": transaction,
+	}
+	h.Events.Publish(userID, services.EventRewardCrafted, response)
+
+	utils.CreatedResponse(w, response)
+}
+
+// pityStatus describes the user's progress towards a guaranteed rare
+func pityStatus(pity *models.RewardPity) map[string]interface{} {
+	return map[string]interface{}{
+		"claims_since_rare":       pity.ClaimsSinceRare,
+		"claims_until_guaranteed": pity.ClaimsUntilGuaranteed(),
+		"rare_bonus":              pity.RareBonus(),
+		"guarantee_after":         models.PityGuaranteeClaims,
+		"last_rare_at":            pity.LastRareAt,
+	}
+}
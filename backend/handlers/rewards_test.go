@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+func TestGetRewardStatusCountsTrackedIntervals(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &RewardHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Practice")
+	createTestActivity(t, repos, user.ID, "Untracked")
+
+	// 40 minutes with 5 paused: two full intervals and 5 minutes towards the next
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-40*time.Minute), end, 5*60)
+
+	body := decodeResponse(t, serve(t, h.GetRewardStatus, http.MethodGet, "/rewards/status", "/rewards/status", nil, user.ID), http.StatusOK)
+	if body["total_claimable"] != float64(2) {
+		t.Errorf("total_claimable = %v, want 2", body["total_claimable"])
+	}
+
+	activities := body["activities"].([]interface{})
+	if len(activities) != 1 {
+		t.Fatalf("got %d activities, want only the tracked one", len(activities))
+	}
+	status := activities[0].(map[string]interface{})
+	if status["total_minutes"] != float64(35) {
+		t.Errorf("total_minutes = %v, want 35", status["total_minutes"])
+	}
+	wantProgress := float64(5*60) / float64(services.RewardIntervalSeconds)
+	if status["progress_to_next"] != wantProgress {
+		t.Errorf("progress_to_next = %v, want %v", status["progress_to_next"], wantProgress)
+	}
+}
+
+func TestClaimRewardNeedsAFullInterval(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &RewardHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Practice")
+
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-10*time.Minute), end, 0)
+
+	body := decodeResponse(t, serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": activity.ID}, user.ID), http.StatusBadRequest)
+	if body["next_reward_progress"] == nil {
+		t.Errorf("expected progress towards the next reward in %v", body)
+	}
+
+	decodeResponse(t, serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": activity.ID + 100}, user.ID), http.StatusNotFound)
+}
+
+func TestClaimRewardPityGuaranteesRare(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Practice")
+
+	catalog := services.NewGenericCatalog("mixed", "Mixed", "", []models.RewardCatalogItem{
+		{ExternalID: "pebble", RewardType: "stone", Name: "Pebble", Rarity: models.RarityCommon},
+		{ExternalID: "ruby", RewardType: "gem", Name: "Ruby", Rarity: models.RarityRare},
+	})
+	catalogs := services.NewRewardCatalogRegistry(catalog)
+	if err := repos.Users.SetRewardCatalog(user.ID, "mixed"); err != nil {
+		t.Fatalf("select catalog: %v", err)
+	}
+	h := &RewardHandler{Logger: zap.NewNop(), Catalogs: catalogs, Repos: repos}
+
+	// One claim short of the guarantee
+	if err := repos.DB.Create(&models.RewardPity{UserID: user.ID, ClaimsSinceRare: models.PityGuaranteeClaims - 1}).Error; err != nil {
+		t.Fatalf("create pity: %v", err)
+	}
+
+	body := decodeResponse(t, serve(t, h.GetRewardStatus, http.MethodGet, "/rewards/status", "/rewards/status", nil, user.ID), http.StatusOK)
+	pity := body["pity"].(map[string]interface{})
+	if pity["claims_until_guaranteed"] != float64(1) || pity["rare_bonus"] != float64(100) {
+		t.Errorf("pity = %v, want the next claim guaranteed", pity)
+	}
+
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-30*time.Minute), end, 0)
+
+	body = decodeResponse(t, serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": activity.ID}, user.ID), http.StatusOK)
+	reward := body["reward"].(map[string]interface{})
+	if reward["rarity"] != string(models.RarityRare) {
+		t.Errorf("reward rarity = %v, want rare from the pity guarantee", reward["rarity"])
+	}
+	pity = body["pity"].(map[string]interface{})
+	if pity["claims_since_rare"] != float64(0) || pity["claims_until_guaranteed"] != float64(models.PityGuaranteeClaims) {
+		t.Errorf("pity after rare = %v, want the counter reset", pity)
+	}
+
+	// Without a rare, the counter keeps growing
+	if err := repos.Users.SetRewardCatalog(user.ID, "commons"); err != nil {
+		t.Fatalf("select catalog: %v", err)
+	}
+	catalogs.Register(services.NewGenericCatalog("commons", "Commons", "", []models.RewardCatalogItem{
+		{ExternalID: "pebble", RewardType: "stone", Name: "Pebble", Rarity: models.RarityCommon},
+	}))
+	body = decodeResponse(t, serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": activity.ID}, user.ID), http.StatusOK)
+	pity = body["pity"].(map[string]interface{})
+	if pity["claims_since_rare"] != float64(1) {
+		t.Errorf("claims_since_rare = %v, want 1", pity["claims_since_rare"])
+	}
+}
+
+func TestDisenchantDuplicatesAndCraft(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+
+	catalog := services.NewGenericCatalog("gems", "Gems", "", []models.RewardCatalogItem{
+		{ExternalID: "pebble", RewardType: "stone", Name: "Pebble", Rarity: models.RarityCommon},
+		{ExternalID: "ruby", RewardType: "gem", Name: "Ruby", Rarity: models.RarityRare},
+		{ExternalID: "diamond", RewardType: "gem", Name: "Diamond", Rarity: models.RarityEpic},
+	})
+	if err := repos.Users.SetRewardCatalog(user.ID, "gems"); err != nil {
+		t.Fatalf("select catalog: %v", err)
+	}
+	h := &RewardHandler{Logger: zap.NewNop(), Catalogs: services.NewRewardCatalogRegistry(catalog), Repos: repos}
+
+	giveReward := func(externalID string, rarity models.Rarity) uint {
+		reward := models.UserReward{UserID: user.ID, Catalog: "gems", RewardType: "gem", ExternalID: externalID, Name: externalID, Rarity: rarity}
+		if err := repos.Rewards.Create(&reward); err != nil {
+			t.Fatalf("create reward: %v", err)
+		}
+		return reward.ID
+	}
+	disenchant := func(id uint, status int) map[string]interface{} {
+		target := "/rewards/" + strconv.FormatUint(uint64(id), 10) + "/disenchant"
+		return decodeResponse(t, serve(t, h.DisenchantReward, http.MethodPost, "/rewards/{id}/disenchant", target, nil, user.ID), status)
+	}
+	craft := func(rewardType, externalID string, status int) map[string]interface{} {
+		return decodeResponse(t, serve(t, h.CraftReward, http.MethodPost, "/rewards/craft", "/rewards/craft",
+			map[string]string{"reward_type": rewardType, "external_id": externalID}, user.ID), status)
+	}
+
+	first := giveReward("ruby", models.RarityRare)
+	second := giveReward("ruby", models.RarityRare)
+
+	body := disenchant(first, http.StatusOK)
+	if body["balance"] != float64(models.DisenchantValues[models.RarityRare]) {
+		t.Errorf("balance = %v, want %d", body["balance"], models.DisenchantValues[models.RarityRare])
+	}
+
+	// The last copy is kept
+	disenchant(second, http.StatusConflict)
+	disenchant(first, http.StatusNotFound)
+
+	// Not enough essence leaves the balance untouched
+	craft("stone", "pebble", http.StatusBadRequest)
+
+	giveReward("diamond", models.RarityEpic)
+	disenchant(giveReward("diamond", models.RarityEpic), http.StatusOK)
+
+	body = craft("stone", "pebble", http.StatusCreated)
+	want := models.DisenchantValues[models.RarityRare] + models.DisenchantValues[models.RarityEpic] - models.CraftCosts[models.RarityCommon]
+	if body["balance"] != float64(want) {
+		t.Errorf("balance after craft = %v, want %d", body["balance"], want)
+	}
+
+	craft("stone", "pebble", http.StatusConflict)
+	craft("stone", "granite", http.StatusNotFound)
+
+	body = decodeResponse(t, serve(t, h.GetEssence, http.MethodGet, "/rewards/essence", "/rewards/essence", nil, user.ID), http.StatusOK)
+	if body["balance"] != float64(want) {
+		t.Errorf("essence balance = %v, want %d", body["balance"], want)
+	}
+	if got := len(body["transactions"].([]interface{})); got != 3 {
+		t.Errorf("got %d ledger entries, want 3", got)
+	}
+}
+
+func TestClaimAllRewardsClaimsEveryInterval(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+	other := createTestUser(t, repos, "other@example.com")
+	practice := createTestActivity(t, repos, user.ID, "Practice")
+	reading := createTestActivity(t, repos, user.ID, "Reading")
+
+	// Champions are counted per user, so both users can own Ahri
+	catalog := services.NewGenericCatalog(services.DataDragonCatalogKey, "Champions", "", []models.RewardCatalogItem{
+		{ExternalID: "Ahri", RewardType: models.RewardTypeChampion, Name: "Ahri", Rarity: models.RarityCommon},
+	})
+	h := &RewardHandler{Logger: zap.NewNop(), Catalogs: services.NewRewardCatalogRegistry(catalog), Repos: repos}
+
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, practice, end.Add(-50*time.Minute), end, 5*60)
+	createTestEntry(t, repos, reading, end.Add(-20*time.Minute), end, 0)
+	createTestEntry(t, repos, createTestActivity(t, repos, other.ID, "Other"), end.Add(-15*time.Minute), end, 0)
+
+	decodeResponse(t, serve(t, h.ClaimAllRewards, http.MethodPost, "/rewards/claim-all", "/rewards/claim-all", nil, other.ID), http.StatusOK)
+
+	body := decodeResponse(t, serve(t, h.ClaimAllRewards, http.MethodPost, "/rewards/claim-all", "/rewards/claim-all", nil, user.ID), http.StatusOK)
+	if body["claimed"] != float64(4) {
+		t.Fatalf("claimed = %v, want 3 intervals of practice and 1 of reading", body["claimed"])
+	}
+	byActivity := body["claimed_by_activity"].(map[string]interface{})
+	if byActivity[strconv.Itoa(int(practice.ID))] != float64(3) || byActivity[strconv.Itoa(int(reading.ID))] != float64(1) {
+		t.Errorf("claimed_by_activity = %v, want 3 and 1", byActivity)
+	}
+	rewards := body["rewards"].([]interface{})
+	if last := rewards[len(rewards)-1].(map[string]interface{}); last["is_duplicate"] != true || last["mastery_level"] != float64(4) {
+		t.Errorf("last reward = %v, want the fourth Ahri", last)
+	}
+
+	var mastery []models.ChampionMastery
+	repos.DB.Order("user_id").Find(&mastery)
+	if len(mastery) != 2 || mastery[0].TimesObtained != 4 || mastery[1].TimesObtained != 1 {
+		t.Errorf("mastery = %+v, want Ahri 4 times for the user and once for the other", mastery)
+	}
+
+	// Every interval is spent
+	decodeResponse(t, serve(t, h.ClaimAllRewards, http.MethodPost, "/rewards/claim-all", "/rewards/claim-all", nil, user.ID), http.StatusBadRequest)
+	decodeResponse(t, serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": practice.ID}, user.ID), http.StatusBadRequest)
+}
+
+func TestClaimCountsTrackedTimeLikeTheStatus(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Practice")
+	catalog := services.NewGenericCatalog(services.DataDragonCatalogKey, "Champions", "", []models.RewardCatalogItem{
+		{ExternalID: "Ahri", RewardType: models.RewardTypeChampion, Name: "Ahri", Rarity: models.RarityCommon},
+	})
+	h := &RewardHandler{Logger: zap.NewNop(), Catalogs: services.NewRewardCatalogRegistry(catalog), Repos: repos}
+
+	// Rounded to whole seconds this is a full interval, cut off it is a second short
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-15*time.Minute+400*time.Millisecond), end, 0)
+
+	body := decodeResponse(t, serve(t, h.GetRewardStatus, http.MethodGet, "/rewards/status", "/rewards/status", nil, user.ID), http.StatusOK)
+	if body["total_claimable"] != float64(1) {
+		t.Fatalf("total_claimable = %v, want 1", body["total_claimable"])
+	}
+	decodeResponse(t, serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+		map[string]uint{"activity_id": activity.ID}, user.ID), http.StatusOK)
+}
+
+func TestClaimRewardConcurrentClaimsSpendEachIntervalOnce(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Practice")
+	catalog := services.NewGenericCatalog("stones", "Stones", "", []models.RewardCatalogItem{
+		{ExternalID: "pebble", RewardType: "stone", Name: "Pebble", Rarity: models.RarityCommon},
+	})
+	if err := repos.Users.SetRewardCatalog(user.ID, "stones"); err != nil {
+		t.Fatalf("select catalog: %v", err)
+	}
+	h := &RewardHandler{Logger: zap.NewNop(), Catalogs: services.NewRewardCatalogRegistry(catalog), Repos: repos}
+
+	end := time.Now().Add(-time.Hour)
+	createTestEntry(t, repos, activity, end.Add(-30*time.Minute), end, 0)
+
+	statuses := make(chan int, 5)
+	for i := 0; i < cap(statuses); i++ {
+		go func() {
+			rec := serve(t, h.ClaimReward, http.MethodPost, "/rewards/claim", "/rewards/claim",
+				map[string]uint{"activity_id": activity.ID}, user.ID)
+			statuses <- rec.Code
+		}()
+	}
+
+	ok := 0
+	for i := 0; i < cap(statuses); i++ {
+		if <-statuses == http.StatusOK {
+			ok++
+		}
+	}
+	if ok != 2 {
+		t.Errorf("%d claims succeeded, want one per interval", ok)
+	}
+
+	var rewards int64
+	repos.DB.Model(&models.UserReward{}).Where("user_id = ?", user.ID).Count(&rewards)
+	if rewards != 2 {
+		t.Errorf("got %d rewards, want 2", rewards)
+	}
+}
+
+func TestCollectionCountsCopiesAndSkinIDs(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+
+	// A cached Data Dragon catalog with a skin number past 9
+	cache, _ := json.Marshal(services.DataDragonCatalog{
+		Version: "14.1.1",
+		Skins:   []services.SkinData{{ChampionID: "Ahri", ChampionName: "Ahri", SkinNum: 14, Name: "Arcana Ahri"}},
+	})
+	dd := &services.DataDragonService{CachePath: filepath.Join(t.TempDir(), "catalog.json")}
+	if err := os.WriteFile(dd.CachePath, cache, 0o644); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+	if err := dd.LoadCache(); err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	h := &RewardHandler{Logger: zap.NewNop(), Catalogs: services.NewRewardCatalogRegistry(dd), Repos: repos}
+
+	if err := repos.DB.Create(&models.EssenceBalance{UserID: user.ID, Balance: 1000}).Error; err != nil {
+		t.Fatalf("create essence: %v", err)
+	}
+	decodeResponse(t, serve(t, h.CraftReward, http.MethodPost, "/rewards/craft", "/rewards/craft",
+		map[string]string{"reward_type": "skin", "external_id": "Ahri_14"}, user.ID), http.StatusCreated)
+
+	var drops []uint
+	for i := 0; i < 2; i++ {
+		reward := models.UserReward{UserID: user.ID, Catalog: "gems", RewardType: "gem", ExternalID: "ruby", Name: "Ruby", Rarity: models.RarityRare}
+		if err := repos.Rewards.Create(&reward); err != nil {
+			t.Fatalf("create reward: %v", err)
+		}
+		drops = append(drops, reward.ID)
+	}
+
+	collection := func() map[string]float64 {
+		body := decodeResponse(t, serve(t, h.GetCollection, http.MethodGet, "/rewards/collection", "/rewards/collection", nil, user.ID), http.StatusOK)
+		counts := make(map[string]float64)
+		for _, item := range body["collection"].([]interface{}) {
+			owned := item.(map[string]interface{})
+			counts[owned["external_id"].(string)] = owned["count"].(float64)
+		}
+		return counts
+	}
+
+	if counts := collection(); len(counts) != 2 || counts["Ahri_14"] != 1 || counts["ruby"] != 2 {
+		t.Errorf("collection = %v, want Ahri_14 once and ruby twice", counts)
+	}
+
+	target := "/rewards/" + strconv.FormatUint(uint64(drops[0]), 10) + "/disenchant"
+	decodeResponse(t, serve(t, h.DisenchantReward, http.MethodPost, "/rewards/{id}/disenchant", target, nil, user.ID), http.StatusOK)
+	if counts := collection(); counts["ruby"] != 1 {
+		t.Errorf("ruby count after disenchant = %v, want 1", counts["ruby"])
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type SettingsHandler struct {
+	Logger *zap.Logger
+	Repos  *repository.Repositories
+}
+
+// GetSettings returns the user's timezone, week start and date format
+func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	settings, err := h.Repos.Users.FindSettings(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get settings")
+		return
+	}
+
+	utils.SuccessResponse(w, settings)
+}
+
+// UpdateSettings changes the user's settings
+// Fields left out of the body keep their current value; an empty timezone uses the server's zone
+func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input struct {
+		Timezone   *string            `json:"timezone"`
+		WeekStart  *time.Weekday      `json:"week_start"`
+		DateFormat *models.DateFormat `json:"date_format"`
+	}
+
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	settings, err := h.Repos.Users.FindSettings(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	if input.Timezone != nil {
+		settings.Timezone = *input.Timezone
+	}
+	if input.WeekStart != nil {
+		settings.WeekStart = *input.WeekStart
+	}
+	if input.DateFormat != nil {
+		settings.DateFormat = *input.DateFormat
+	}
+
+	if err := settings.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Repos.Users.SaveSettings(settings); err != nil {
+		h.Logger.Error("Failed to save user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	utils.SuccessResponse(w, settings)
+}
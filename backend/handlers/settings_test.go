@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+func TestSettingsDefaultsAndPartialUpdates(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &SettingsHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+
+	body := decodeResponse(t, serve(t, h.GetSettings, http.MethodGet, "/settings", "/settings", nil, user.ID), http.StatusOK)
+	if body["timezone"] != "" || body["week_start"] != float64(time.Monday) || body["date_format"] != "YYYY-MM-DD" {
+		t.Errorf("defaults = %v, want the server's zone, Monday and ISO dates", body)
+	}
+
+	body = decodeResponse(t, serve(t, h.UpdateSettings, http.MethodPut, "/settings", "/settings",
+		map[string]interface{}{"timezone": "America/New_York", "week_start": 0}, user.ID), http.StatusOK)
+	if body["timezone"] != "America/New_York" || body["week_start"] != float64(time.Sunday) || body["date_format"] != "YYYY-MM-DD" {
+		t.Errorf("settings = %v, want New York and Sunday with the date format unchanged", body)
+	}
+
+	for _, input := range []map[string]interface{}{
+		{"timezone": "Mars/Olympus_Mons"},
+		{"week_start": 7},
+		{"date_format": "YYYY/MM/DD"},
+	} {
+		decodeResponse(t, serve(t, h.UpdateSettings, http.MethodPut, "/settings", "/settings", input, user.ID), http.StatusBadRequest)
+	}
+
+	body = decodeResponse(t, serve(t, h.UpdateSettings, http.MethodPut, "/settings", "/settings",
+		map[string]interface{}{"date_format": "DD/MM/YYYY"}, user.ID), http.StatusOK)
+	if body["timezone"] != "America/New_York" || body["week_start"] != float64(time.Sunday) || body["date_format"] != "DD/MM/YYYY" {
+		t.Errorf("settings = %v, want only the date format changed", body)
+	}
+}
+
+func TestResumeWeekBeginsOnTheUsersWeekStart(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &ResumeHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Writing")
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	createTestDayEntry(t, repos, activity, tokyo, 6, 30)
+
+	weekTotal := func(weekStart time.Weekday) float64 {
+		t.Helper()
+		settings, err := repos.Users.FindSettings(user.ID)
+		if err != nil {
+			t.Fatalf("find settings: %v", err)
+		}
+		settings.Timezone = "Asia/Tokyo"
+		settings.WeekStart = weekStart
+		if err := repos.Users.SaveSettings(settings); err != nil {
+			t.Fatalf("save settings: %v", err)
+		}
+		body := decodeResponse(t, serve(t, h.GetResume, http.MethodGet, "/resume", "/resume?period=week", nil, user.ID), http.StatusOK)
+		return body["total_seconds"].(float64)
+	}
+
+	// The entry is six days ago in Tokyo: inside a week that began then, outside one that began today
+	today := time.Now().In(tokyo).Weekday()
+	if got := weekTotal((today + 1) % 7); got != 30*60 {
+		t.Errorf("week starting six days ago = %v seconds, want %v", got, 30*60)
+	}
+	if got := weekTotal(today); got != 0 {
+		t.Errorf("week starting today = %v seconds, want 0", got)
+	}
+}
+
+func TestReportWeeksFollowWeekStartAcrossDST(t *testing.T) {
+	repos := newTestRepos(t)
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Writing")
+
+	// Clocks in New York go forward at 2am on Sunday 10 March 2024
+	newYork, _ := time.LoadLocation("America/New_York")
+	saturday := time.Date(2024, time.March, 9, 23, 30, 0, 0, newYork)
+	sunday := time.Date(2024, time.March, 10, 0, 30, 0, 0, newYork)
+	createTestEntry(t, repos, activity, saturday, saturday.Add(20*time.Minute), 0)
+	createTestEntry(t, repos, activity, sunday, sunday.Add(20*time.Minute), 0)
+
+	report, err := services.BuildReport(repos.DB, user.ID, services.ReportFilter{
+		From:      time.Date(2024, time.March, 1, 0, 0, 0, 0, newYork),
+		To:        time.Date(2024, time.March, 31, 0, 0, 0, 0, newYork),
+		Location:  newYork,
+		WeekStart: time.Sunday,
+		GroupBy:   []services.ReportDimension{services.GroupByWeek},
+	})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+
+	if len(report.Groups) != 2 || report.Groups[0].Key != "2024-03-03" || report.Groups[1].Key != "2024-03-10" {
+		t.Fatalf("groups = %+v, want the weeks beginning Sunday 3 and Sunday 10 March", report.Groups)
+	}
+	if report.Groups[1].Label != "Week of 2024-03-10" {
+		t.Errorf("label = %q, want %q", report.Groups[1].Label, "Week of 2024-03-10")
+	}
+}
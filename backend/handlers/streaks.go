@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+)
+
+type StreakHandler struct {
+	Logger   *zap.Logger
+	Catalogs *services.RewardCatalogRegistry
+	Events   *services.EventHub
+	Repos    *repository.Repositories
+}
+
+// GetStreak returns the user's current and longest daily streak, counted in their time zone
+func (h *StreakHandler) GetStreak(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	settings, err := h.Repos.Users.FindSettings(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute streak")
+		return
+	}
+
+	status, err := services.ComputeStreak(h.Repos.DB, userID, settings.Location(), time.Now())
+	if err != nil {
+		h.Logger.Error("Failed to compute streak", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute streak")
+		return
+	}
+
+	utils.SuccessResponse(w, status)
+}
+
+// UpdateStreakSettings changes how many minutes a day must be tracked to count for the streak
+// Days are counted in the timezone of the user's settings
+func (h *StreakHandler) UpdateStreakSettings(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input struct {
+		MinMinutes *int `json:"min_minutes"`
+	}
+
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if input.MinMinutes != nil {
+		if *input.MinMinutes < 1 || *input.MinMinutes > 24*60 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "min_minutes must be between 1 and 1440")
+			return
+		}
+		if err := services.UpdateStreakMinMinutes(h.Repos.DB, userID, *input.MinMinutes); err != nil {
+			h.Logger.Error("Failed to update streak minimum", zap.Error(err))
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update streak settings")
+			return
+		}
+	}
+
+	h.GetStreak(w, r)
+}
+
+// FreezeStreakDay spends a freeze token on a missed day, yesterday unless a date is given
+func (h *StreakHandler) FreezeStreakDay(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input struct {
+		Date string `json:"date"` // YYYY-MM-DD
+	}
+
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	settings, err := h.Repos.Users.FindSettings(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to freeze streak day")
+		return
+	}
+	loc := settings.Location()
+	now := time.Now()
+
+	day := now.In(loc).AddDate(0, 0, -1)
+	if input.Date != "" {
+		day, err = time.ParseInLocation("2006-01-02", input.Date, loc)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	err = services.FreezeStreakDay(h.Repos.DB, userID, day, loc, now)
+	if errors.Is(err, services.ErrNoFreezeTokens) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "No freeze tokens left")
+		return
+	}
+	if errors.Is(err, services.ErrStreakDayNotFreezable) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Only a missed day of the last week can be frozen")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to freeze streak day", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to freeze streak day")
+		return
+	}
+
+	h.GetStreak(w, r)
+}
+
+// ClaimStreakBonus claims the bonus reward of the lowest unclaimed milestone of the current streak
+func (h *StreakHandler) ClaimStreakBonus(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	user, err := h.Repos.Users.FindByID(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	settings, err := h.Repos.Users.FindSettings(userID)
+	if err != nil {
+		h.Logger.Error("Failed to get user settings", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to claim streak bonus")
+		return
+	}
+	loc := settings.Location()
+
+	catalog := h.Catalogs.ForUser(user.RewardCatalog)
+	reward, result, milestone, err := services.ClaimStreakBonus(h.Repos.DB, catalog, userID, loc, time.Now())
+	if errors.Is(err, services.ErrNoStreakBonus) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "No streak bonus to claim. Keep your streak going!")
+		return
+	}
+	if errors.Is(err, services.ErrCatalogUnavailable) {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, "Rewards are not available yet, try again later")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to claim streak bonus", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to claim streak bonus")
+		return
+	}
+
+	status, err := services.ComputeStreak(h.Repos.DB, userID, loc, time.Now())
+	if err != nil {
+		h.Logger.Error("Failed to compute streak", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute streak")
+		return
+	}
+
+	response := map[string]interface{}{
+		"reward": map[string]interface{}{
+			"id":            reward.ID,
+			"catalog":       reward.Catalog,
+			"reward_type":   reward.RewardType,
+			"external_id":   reward.ExternalID,
+			"name":          reward.Name,
+			"image_url":     reward.ImageURL,
+			"rarity":        reward.Rarity,
+			"is_duplicate":  result.IsDuplicate,
+			"mastery_level": result.MasteryLevel,
+		},
+		"milestone": milestone,
+		"pity":      pityStatus(result.Pity),
+		"streak":    status,
+	}
+	h.Events.Publish(userID, services.EventStreakBonusClaimed, response)
+
+	utils.SuccessResponse(w, response)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
+	"go.uber.org/zap"
+)
+
+// createTestDayEntry stores minutes of tracked time at noon, daysAgo days before today in loc
+func createTestDayEntry(t *testing.T, repos *repository.Repositories, activity *models.Activity, loc *time.Location, daysAgo, minutes int) {
+	t.Helper()
+
+	now := time.Now().In(loc)
+	noon := time.Date(now.Year(), now.Month(), now.Day()-daysAgo, 12, 0, 0, 0, loc)
+	createTestEntry(t, repos, activity, noon, noon.Add(time.Duration(minutes)*time.Minute), 0)
+}
+
+func TestStreakCountsDaysAndClaimsMilestoneBonus(t *testing.T) {
+	repos := newTestRepos(t)
+	catalogs := newTestCatalogs()
+	catalogs.Register(services.NewGenericCatalog("books", "Books", "", []models.RewardCatalogItem{
+		{ExternalID: "dune", RewardType: "novel", Name: "Dune", Rarity: models.RarityEpic},
+	}))
+	h := &StreakHandler{Logger: zap.NewNop(), Catalogs: catalogs, Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Reading")
+	if err := repos.Users.SetRewardCatalog(user.ID, "books"); err != nil {
+		t.Fatalf("select catalog: %v", err)
+	}
+
+	settings := &SettingsHandler{Logger: zap.NewNop(), Repos: repos}
+	decodeResponse(t, serve(t, settings.UpdateSettings, http.MethodPut, "/settings", "/settings",
+		map[string]interface{}{"timezone": "Asia/Tokyo"}, user.ID), http.StatusOK)
+
+	body := decodeResponse(t, serve(t, h.UpdateStreakSettings, http.MethodPut, "/streaks/settings", "/streaks/settings",
+		map[string]interface{}{"min_minutes": 30}, user.ID), http.StatusOK)
+	if body["min_minutes"] != float64(30) || body["timezone"] != "Asia/Tokyo" {
+		t.Fatalf("settings = %v, want 30 minutes in Asia/Tokyo", body)
+	}
+
+	// Three qualifying days up to yesterday, a short day before them and a qualifying day before that
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	for daysAgo := 1; daysAgo <= 3; daysAgo++ {
+		createTestDayEntry(t, repos, activity, tokyo, daysAgo, 45)
+	}
+	createTestDayEntry(t, repos, activity, tokyo, 4, 20)
+	createTestDayEntry(t, repos, activity, tokyo, 5, 30)
+
+	body = decodeResponse(t, serve(t, h.GetStreak, http.MethodGet, "/streaks", "/streaks", nil, user.ID), http.StatusOK)
+	if body["current"] != float64(3) || body["longest"] != float64(3) || body["today_completed"] != false {
+		t.Errorf("streak = %v, want a current and longest streak of 3 with today still open", body)
+	}
+	if body["next_milestone"] != float64(7) || len(body["claimable_milestones"].([]interface{})) != 1 {
+		t.Errorf("milestones = %v / %v, want 3 claimable and 7 next", body["claimable_milestones"], body["next_milestone"])
+	}
+
+	body = decodeResponse(t, serve(t, h.ClaimStreakBonus, http.MethodPost, "/streaks/claim", "/streaks/claim", nil, user.ID), http.StatusOK)
+	if body["milestone"] != float64(3) || body["reward"].(map[string]interface{})["external_id"] != "dune" {
+		t.Errorf("claim = %v, want the milestone 3 bonus from the user's catalog", body)
+	}
+	streak := body["streak"].(map[string]interface{})
+	if streak["freeze_tokens"] != float64(1) || len(streak["claimable_milestones"].([]interface{})) != 0 {
+		t.Errorf("streak after claim = %v, want a freeze token and nothing left to claim", streak)
+	}
+
+	// A milestone is claimed once per streak
+	decodeResponse(t, serve(t, h.ClaimStreakBonus, http.MethodPost, "/streaks/claim", "/streaks/claim", nil, user.ID), http.StatusBadRequest)
+
+	// Freezing the short day moves the streak start back, but the streak's milestone 3 stays claimed
+	shortDay := time.Now().In(tokyo).AddDate(0, 0, -4).Format("2006-01-02")
+	body = decodeResponse(t, serve(t, h.FreezeStreakDay, http.MethodPost, "/streaks/freeze", "/streaks/freeze",
+		map[string]string{"date": shortDay}, user.ID), http.StatusOK)
+	if body["current"] != float64(5) || len(body["claimable_milestones"].([]interface{})) != 0 {
+		t.Errorf("streak after freeze = %v, want 5 days and nothing left to claim", body)
+	}
+	_, _, _, err := services.ClaimStreakBonus(repos.DB, catalogs.ForUser("books"), user.ID, tokyo, time.Now())
+	if !errors.Is(err, services.ErrNoStreakBonus) {
+		t.Errorf("claim after freeze = %v, want %v", err, services.ErrNoStreakBonus)
+	}
+
+	var rewards int64
+	repos.DB.Model(&models.UserReward{}).Where("user_id = ?", user.ID).Count(&rewards)
+	if rewards != 1 {
+		t.Errorf("got %d rewards, want 1", rewards)
+	}
+}
+
+func TestStreakMilestoneStaysClaimedWhenTheStartMovesForward(t *testing.T) {
+	repos := newTestRepos(t)
+	catalogs := newTestCatalogs()
+	catalogs.Register(services.NewGenericCatalog("books", "Books", "", []models.RewardCatalogItem{
+		{ExternalID: "dune", RewardType: "novel", Name: "Dune", Rarity: models.RarityEpic},
+	}))
+	h := &StreakHandler{Logger: zap.NewNop(), Catalogs: catalogs, Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Reading")
+	if err := repos.Users.SetRewardCatalog(user.ID, "books"); err != nil {
+		t.Fatalf("select catalog: %v", err)
+	}
+
+	// Four days up to yesterday at the default 15 minutes, the first of them a short one
+	createTestDayEntry(t, repos, activity, time.Local, 4, 20)
+	for daysAgo := 1; daysAgo <= 3; daysAgo++ {
+		createTestDayEntry(t, repos, activity, time.Local, daysAgo, 45)
+	}
+
+	body := decodeResponse(t, serve(t, h.ClaimStreakBonus, http.MethodPost, "/streaks/claim", "/streaks/claim", nil, user.ID), http.StatusOK)
+	if body["milestone"] != float64(3) {
+		t.Fatalf("claim = %v, want the milestone 3 bonus", body)
+	}
+
+	// The short day stops counting, so the streak starts a day later and reaches 3 days only yesterday,
+	// but milestone 3 was already claimed on one of its days
+	body = decodeResponse(t, serve(t, h.UpdateStreakSettings, http.MethodPut, "/streaks/settings", "/streaks/settings",
+		map[string]interface{}{"min_minutes": 30}, user.ID), http.StatusOK)
+	if body["current"] != float64(3) || len(body["claimable_milestones"].([]interface{})) != 0 {
+		t.Errorf("streak = %v, want 3 days and nothing left to claim", body)
+	}
+	decodeResponse(t, serve(t, h.ClaimStreakBonus, http.MethodPost, "/streaks/claim", "/streaks/claim", nil, user.ID), http.StatusBadRequest)
+}
+
+func TestFreezeStreakDayBridgesMissedDay(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &StreakHandler{Logger: zap.NewNop(), Catalogs: newTestCatalogs(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Reading")
+
+	createTestDayEntry(t, repos, activity, time.Local, 1, 20)
+	createTestDayEntry(t, repos, activity, time.Local, 3, 20)
+	missed := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
+
+	decodeResponse(t, serve(t, h.FreezeStreakDay, http.MethodPost, "/streaks/freeze", "/streaks/freeze",
+		map[string]string{"date": missed}, user.ID), http.StatusBadRequest)
+
+	if err := repos.DB.Create(&models.StreakState{UserID: user.ID, MinMinutes: models.DefaultStreakMinMinutes, FreezeTokens: 1}).Error; err != nil {
+		t.Fatalf("grant freeze token: %v", err)
+	}
+
+	// Days that already count cannot be frozen
+	decodeResponse(t, serve(t, h.FreezeStreakDay, http.MethodPost, "/streaks/freeze", "/streaks/freeze",
+		map[string]string{}, user.ID), http.StatusBadRequest)
+
+	body := decodeResponse(t, serve(t, h.FreezeStreakDay, http.MethodPost, "/streaks/freeze", "/streaks/freeze",
+		map[string]string{"date": missed}, user.ID), http.StatusOK)
+	if body["current"] != float64(3) || body["freeze_tokens"] != float64(0) {
+		t.Errorf("streak = %v, want 3 days and no tokens left", body)
+	}
+	if frozen := body["frozen_days"].([]interface{}); len(frozen) != 1 || frozen[0] != missed {
+		t.Errorf("frozen_days = %v, want [%s]", frozen, missed)
+	}
+}
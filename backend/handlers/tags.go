@@ -4,8 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/Felipalds/go-pomodoro/database"
+	"github.com/Felipalds/go-pomodoro/middleware"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/utils"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -13,14 +14,16 @@ import (
 
 type TagHandler struct {
 	Logger *zap.Logger
+	Repos  *repository.Repositories
 }
 
 // GetTags returns all active tags
 func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	var tags []models.Tag
 
 	// Get all tags that are not deleted
-	if err := database.DB.Where("deleted_at IS NULL").Find(&tags).Error; err != nil {
+	if err := h.Repos.DB.Where("user_id = ? AND deleted_at IS NULL", userID).Find(&tags).Error; err != nil {
 		h.Logger.Error("Failed to fetch tags", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch tags")
 		return
@@ -33,6 +36,7 @@ func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
 
 // GetTag returns a single tag by ID
 func (h *TagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -41,7 +45,7 @@ func (h *TagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var tag models.Tag
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&tag).Error; err != nil {
+	if err := h.Repos.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&tag).Error; err != nil {
 		h.Logger.Error("Tag not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Tag not found")
 		return
@@ -52,6 +56,7 @@ func (h *TagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
 
 // UpdateTag updates a tag name
 func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -75,15 +80,25 @@ func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var tag models.Tag
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&tag).Error; err != nil {
+	if err := h.Repos.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&tag).Error; err != nil {
 		h.Logger.Error("Tag not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Tag not found")
 		return
 	}
 
+	// Names are unique per user (case-insensitive)
+	var existing int64
+	h.Repos.DB.Model(&models.Tag{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, input.Name, tag.ID).
+		Count(&existing)
+	if existing > 0 {
+		utils.ErrorResponse(w, http.StatusConflict, "Tag with this name already exists")
+		return
+	}
+
 	// Update the tag
 	tag.Name = input.Name
-	if err := database.DB.Save(&tag).Error; err != nil {
+	if err := h.Repos.DB.Save(&tag).Error; err != nil {
 		h.Logger.Error("Failed to update tag", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update tag")
 		return
@@ -94,6 +109,7 @@ func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
 
 // DeleteTag soft deletes a tag
 func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -102,14 +118,14 @@ func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var tag models.Tag
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&tag).Error; err != nil {
+	if err := h.Repos.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&tag).Error; err != nil {
 		h.Logger.Error("Tag not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Tag not found")
 		return
 	}
 
 	// Soft delete by setting deleted_at
-	if err := database.DB.Delete(&tag).Error; err != nil {
+	if err := h.Repos.DB.Delete(&tag).Error; err != nil {
 		h.Logger.Error("Failed to delete tag", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete tag")
 		return
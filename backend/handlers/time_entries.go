@@ -1,13 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/Felipalds/go-pomodoro/database"
 	"github.com/Felipalds/go-pomodoro/middleware"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/Felipalds/go-pomodoro/utils"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -15,6 +17,18 @@ import (
 
 type TimeEntryHandler struct {
 	Logger *zap.Logger
+	Events *services.EventHub
+	Repos  *repository.Repositories
+}
+
+// TimeEntryInput represents the input for creating or editing a time entry
+// All fields are optional on update; only provided fields are changed
+type TimeEntryInput struct {
+	ActivityID *uint      `json:"activity_id"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+	Notes      *string    `json:"notes"`
+	Billable   *bool      `json:"billable"`
 }
 
 // StartTimer starts a new timer for an activity (auto-stops any running timer)
@@ -31,33 +45,31 @@ func (h *TimeEntryHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate activity exists and belongs to user
-	var activity models.Activity
-	if err := database.DB.Where("id = ? AND user_id = ? AND deleted_at IS NULL", input.ActivityID, userID).First(&activity).Error; err != nil {
+	activity, err := h.Repos.Activities.Find(userID, input.ActivityID)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
 		return
 	}
 
+	h.syncPomodoro(userID)
+
 	// Check for active timer for this user
-	var activeTimer models.TimeEntry
-	err := database.DB.Where("user_id = ? AND end_time IS NULL", userID).First(&activeTimer).Error
+	activeTimer, err := h.Repos.TimeEntries.FindActive(userID)
 
 	var stoppedPrevious *map[string]interface{}
 
 	// If there's an active timer, stop it
 	if err == nil {
 		now := time.Now()
-		activeTimer.EndTime = &now
-		database.DB.Save(&activeTimer)
-
-		// Load activity name for response
-		var prevActivity models.Activity
-		database.DB.First(&prevActivity, activeTimer.ActivityID)
+		if err := services.CloseTimeEntry(h.Repos.DB, activeTimer, now); err != nil {
+			h.Logger.Error("Failed to stop previous timer", zap.Error(err))
+		}
 
-		duration := utils.CalculateDuration(activeTimer.StartTime, *activeTimer.EndTime)
+		duration := activeTimer.DurationSeconds(now)
 		stoppedPrevious = &map[string]interface{}{
 			"id":               activeTimer.ID,
 			"activity_id":      activeTimer.ActivityID,
-			"activity_name":    prevActivity.Name,
+			"activity_name":    activeTimer.Activity.Name,
 			"start_time":       activeTimer.StartTime,
 			"end_time":         activeTimer.EndTime,
 			"duration_seconds": duration,
@@ -66,34 +78,44 @@ func (h *TimeEntryHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
 		h.Logger.Info("Auto-stopped previous timer", zap.Uint("entry_id", activeTimer.ID))
 	}
 
+	// The plain timer takes over from any pomodoro cycle
+	if err := services.AbortActivePomodoro(h.Repos.DB, userID); err != nil {
+		h.Logger.Error("Failed to abort pomodoro session", zap.Error(err))
+	}
+
 	// Create new time entry
 	newEntry := models.TimeEntry{
 		UserID:     userID,
 		ActivityID: input.ActivityID,
 		StartTime:  time.Now(),
 		EndTime:    nil,
+		Billable:   true,
 	}
 
-	if err := database.DB.Create(&newEntry).Error; err != nil {
+	if err := h.Repos.TimeEntries.Create(&newEntry); err != nil {
 		h.Logger.Error("Failed to start timer", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to start timer")
 		return
 	}
 
+	startedNew := map[string]interface{}{
+		"id":            newEntry.ID,
+		"activity_id":   newEntry.ActivityID,
+		"activity_name": activity.Name,
+		"start_time":    newEntry.StartTime,
+		"end_time":      nil,
+		"status":        "running",
+	}
 	response := map[string]interface{}{
-		"started_new": map[string]interface{}{
-			"id":            newEntry.ID,
-			"activity_id":   newEntry.ActivityID,
-			"activity_name": activity.Name,
-			"start_time":    newEntry.StartTime,
-			"end_time":      nil,
-			"status":        "running",
-		},
+		"started_new": startedNew,
 	}
 
 	if stoppedPrevious != nil {
 		response["stopped_previous"] = *stoppedPrevious
+		response["achievements_unlocked"] = unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+		h.Events.Publish(userID, services.EventTimerAutoStopped, *stoppedPrevious)
 	}
+	h.Events.Publish(userID, services.EventTimerStarted, startedNew)
 
 	utils.CreatedResponse(w, response)
 }
@@ -102,49 +124,70 @@ func (h *TimeEntryHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
 func (h *TimeEntryHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r)
 
-	var activeTimer models.TimeEntry
-	err := database.DB.Where("user_id = ? AND end_time IS NULL", userID).First(&activeTimer).Error
+	h.syncPomodoro(userID)
 
+	activeTimer, err := h.Repos.TimeEntries.FindActive(userID)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "No active timer found")
 		return
 	}
 
-	// Stop the timer
+	// Stop the timer (ends an open pause as well)
 	now := time.Now()
-	activeTimer.EndTime = &now
 
-	if err := database.DB.Save(&activeTimer).Error; err != nil {
+	if err := services.CloseTimeEntry(h.Repos.DB, activeTimer, now); err != nil {
 		h.Logger.Error("Failed to stop timer", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to stop timer")
 		return
 	}
 
-	// Load activity name
-	var activity models.Activity
-	database.DB.First(&activity, activeTimer.ActivityID)
+	// Stopping the timer also ends a running pomodoro cycle
+	if activeTimer.PomodoroSessionID != nil {
+		if err := services.AbortActivePomodoro(h.Repos.DB, userID); err != nil {
+			h.Logger.Error("Failed to abort pomodoro session", zap.Error(err))
+		}
+	}
 
-	duration := utils.CalculateDuration(activeTimer.StartTime, *activeTimer.EndTime)
+	duration := activeTimer.DurationSeconds(now)
 
-	utils.SuccessResponse(w, map[string]interface{}{
+	stopped := map[string]interface{}{
 		"id":               activeTimer.ID,
 		"activity_id":      activeTimer.ActivityID,
-		"activity_name":    activity.Name,
+		"activity_name":    activeTimer.Activity.Name,
 		"start_time":       activeTimer.StartTime,
 		"end_time":         activeTimer.EndTime,
 		"duration_seconds": duration,
 		"duration":         utils.FormatDuration(duration),
 		"status":           "stopped",
-	})
+	}
+	stopped["achievements_unlocked"] = unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+	// The last minutes of the timer may cross a goal before the watcher's next check
+	announceGoalCrossings(h.Logger, h.Events, h.Repos, userID)
+	h.Events.Publish(userID, services.EventTimerStopped, stopped)
+
+	utils.SuccessResponse(w, stopped)
+}
+
+// syncPomodoro brings the pomodoro session up to date so finished work phases are already closed,
+// and evaluates achievements for the entries that closed
+func (h *TimeEntryHandler) syncPomodoro(userID uint) {
+	closed, err := services.SyncActivePomodoro(h.Repos.DB, userID)
+	if err != nil {
+		h.Logger.Error("Failed to sync pomodoro session", zap.Error(err))
+		return
+	}
+	if closed {
+		unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+	}
 }
 
 // GetActiveTimer returns the currently running timer if any
 func (h *TimeEntryHandler) GetActiveTimer(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r)
 
-	var activeTimer models.TimeEntry
-	err := database.DB.Preload("Activity").Where("user_id = ? AND end_time IS NULL", userID).First(&activeTimer).Error
+	h.syncPomodoro(userID)
 
+	activeTimer, err := h.Repos.TimeEntries.FindActive(userID)
 	if err != nil {
 		utils.SuccessResponse(w, map[string]interface{}{
 			"active_timer": nil,
@@ -152,19 +195,74 @@ func (h *TimeEntryHandler) GetActiveTimer(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Calculate elapsed time
-	elapsed := utils.CalculateDuration(activeTimer.StartTime, time.Now())
+	utils.SuccessResponse(w, map[string]interface{}{
+		"active_timer": formatActiveTimer(activeTimer, activeTimer.Activity.Name),
+	})
+}
+
+// PauseTimer pauses the currently running timer
+func (h *TimeEntryHandler) PauseTimer(w http.ResponseWriter, r *http.Request) {
+	h.toggleTimerPause(w, r, true)
+}
+
+// ResumeTimer resumes the currently paused timer
+func (h *TimeEntryHandler) ResumeTimer(w http.ResponseWriter, r *http.Request) {
+	h.toggleTimerPause(w, r, false)
+}
+
+// toggleTimerPause pauses or resumes the active timer
+// Pomodoro entries go through the pomodoro engine so the phase countdown freezes too
+func (h *TimeEntryHandler) toggleTimerPause(w http.ResponseWriter, r *http.Request, pause bool) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	h.syncPomodoro(userID)
+
+	activeTimer, err := h.Repos.TimeEntries.FindActive(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "No active timer found")
+		return
+	}
+
+	switch {
+	case activeTimer.PomodoroSessionID != nil && pause:
+		_, err = services.PausePomodoro(h.Repos.DB, userID)
+	case activeTimer.PomodoroSessionID != nil:
+		_, err = services.ResumePomodoro(h.Repos.DB, userID)
+	case pause:
+		err = services.PauseTimeEntry(h.Repos.DB, activeTimer, time.Now())
+	default:
+		err = services.ResumeTimeEntry(h.Repos.DB, activeTimer, time.Now())
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTimerAlreadyPaused):
+			utils.ErrorResponse(w, http.StatusConflict, "Timer is already paused")
+		case errors.Is(err, services.ErrTimerNotPaused):
+			utils.ErrorResponse(w, http.StatusConflict, "Timer is not paused")
+		case errors.Is(err, services.ErrInvalidPomodoroAction):
+			utils.ErrorResponse(w, http.StatusConflict, "Action not allowed in the current pomodoro state")
+		default:
+			h.Logger.Error("Failed to update timer pause", zap.Error(err))
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update timer")
+		}
+		return
+	}
+
+	// Reload to pick up changes made by the pomodoro engine
+	if reloaded, err := h.Repos.TimeEntries.FindActive(userID); err == nil {
+		activeTimer = reloaded
+	}
+	timer := formatActiveTimer(activeTimer, activeTimer.Activity.Name)
+
+	if pause {
+		h.Events.Publish(userID, services.EventTimerPaused, timer)
+	} else {
+		h.Events.Publish(userID, services.EventTimerResumed, timer)
+	}
 
 	utils.SuccessResponse(w, map[string]interface{}{
-		"active_timer": map[string]interface{}{
-			"id":              activeTimer.ID,
-			"activity_id":     activeTimer.ActivityID,
-			"activity_name":   activeTimer.Activity.Name,
-			"start_time":      activeTimer.StartTime,
-			"elapsed_seconds": elapsed,
-			"elapsed":         utils.FormatDuration(elapsed),
-			"status":          "running",
-		},
+		"active_timer": timer,
 	})
 }
 
@@ -178,15 +276,15 @@ func (h *TimeEntryHandler) DeleteTimeEntry(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var entry models.TimeEntry
-	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&entry).Error; err != nil {
+	entry, err := h.Repos.TimeEntries.Find(userID, uint(id))
+	if err != nil {
 		h.Logger.Error("Time entry not found", zap.Uint64("id", id), zap.Error(err))
 		utils.ErrorResponse(w, http.StatusNotFound, "Time entry not found")
 		return
 	}
 
 	// Hard delete (not soft delete for time entries)
-	if err := database.DB.Unscoped().Delete(&entry).Error; err != nil {
+	if err := h.Repos.TimeEntries.Delete(entry); err != nil {
 		h.Logger.Error("Failed to delete time entry", zap.Error(err))
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete time entry")
 		return
@@ -196,3 +294,217 @@ func (h *TimeEntryHandler) DeleteTimeEntry(w http.ResponseWriter, r *http.Reques
 		"message": "Time entry deleted successfully",
 	})
 }
+
+// CreateTimeEntry logs a closed time entry after the fact
+func (h *TimeEntryHandler) CreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+
+	var input TimeEntryInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if input.ActivityID == nil || input.StartTime == nil || input.EndTime == nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "activity_id, start_time and end_time are required")
+		return
+	}
+
+	// Validate activity exists and belongs to user
+	activity, err := h.Repos.Activities.Find(userID, *input.ActivityID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
+		return
+	}
+
+	entry := models.TimeEntry{
+		UserID:     userID,
+		ActivityID: activity.ID,
+		StartTime:  *input.StartTime,
+		EndTime:    input.EndTime,
+		Notes:      input.Notes,
+		Billable:   input.Billable == nil || *input.Billable,
+	}
+
+	if !h.validateEntryTimes(w, &entry) {
+		return
+	}
+
+	if err := h.Repos.TimeEntries.Create(&entry); err != nil {
+		h.Logger.Error("Failed to create time entry", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create time entry")
+		return
+	}
+
+	if entry.EndTime != nil {
+		unlockAchievements(h.Logger, h.Events, h.Repos, userID)
+	}
+
+	utils.CreatedResponse(w, formatTimeEntry(&entry, activity.Name))
+}
+
+// UpdateTimeEntry edits the times, notes or activity of a time entry
+func (h *TimeEntryHandler) UpdateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r)
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid time entry ID")
+		return
+	}
+
+	var input TimeEntryInput
+	if err := utils.DecodeJSON(r, &input); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.Repos.TimeEntries.Find(userID, uint(id))
+	if err != nil {
+		h.Logger.Error("Time entry not found", zap.Uint64("id", id), zap.Error(err))
+		utils.ErrorResponse(w, http.StatusNotFound, "Time entry not found")
+		return
+	}
+
+	// Change the linked activity (must belong to the same user)
+	activityID := entry.ActivityID
+	if input.ActivityID != nil {
+		activityID = *input.ActivityID
+	}
+	activity, err := h.Repos.Activities.Find(userID, activityID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Activity not found")
+		return
+	}
+	entry.ActivityID = activity.ID
+
+	if input.StartTime != nil {
+		entry.StartTime = *input.StartTime
+	}
+
+	if input.EndTime != nil {
+		// Running timers are ended through /stop so pomodoro state stays consistent
+		if entry.EndTime == nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Cannot set end_time on a running timer, stop it first")
+			return
+		}
+		entry.EndTime = input.EndTime
+	}
+
+	if input.Notes != nil {
+		entry.Notes = input.Notes
+	}
+
+	if input.Billable != nil {
+		entry.Billable = *input.Billable
+	}
+
+	if !h.validateEntryTimes(w, entry) {
+		return
+	}
+
+	if err := h.Repos.TimeEntries.Save(entry); err != nil {
+		h.Logger.Error("Failed to update time entry", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update time entry")
+		return
+	}
+
+	utils.SuccessResponse(w, formatTimeEntry(entry, activity.Name))
+}
+
+// validateEntryTimes checks ordering, future times and overlaps for an entry
+// Writes the error response and returns false when the entry is invalid
+func (h *TimeEntryHandler) validateEntryTimes(w http.ResponseWriter, entry *models.TimeEntry) bool {
+	now := time.Now()
+
+	// Running entries occupy time up to now
+	end := now
+	if entry.EndTime != nil {
+		end = *entry.EndTime
+	}
+
+	if !end.After(entry.StartTime) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "end_time must be after start_time")
+		return false
+	}
+
+	if utils.CalculateDuration(entry.StartTime, end) < entry.PausedSeconds {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Time entry would be shorter than its paused time")
+		return false
+	}
+
+	if end.After(now) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Time entries cannot be in the future")
+		return false
+	}
+
+	overlapping, err := services.FindOverlappingEntry(h.Repos.DB, entry.UserID, entry.StartTime, end, entry.ID)
+	if err != nil {
+		h.Logger.Error("Failed to check overlapping entries", zap.Error(err))
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to validate time entry")
+		return false
+	}
+
+	if overlapping != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		utils.EncodeJSON(w, map[string]interface{}{
+			"error":                "Time entry overlaps another entry",
+			"overlapping_entry_id": overlapping.ID,
+		})
+		return false
+	}
+
+	return true
+}
+
+// formatActiveTimer builds the response payload for the running timer
+// Elapsed time excludes every pause, including the current one
+func formatActiveTimer(entry *models.TimeEntry, activityName string) map[string]interface{} {
+	elapsed := entry.DurationSeconds(time.Now())
+
+	status := "running"
+	if entry.IsPaused() {
+		status = "paused"
+	}
+
+	return map[string]interface{}{
+		"id":                  entry.ID,
+		"activity_id":         entry.ActivityID,
+		"activity_name":       activityName,
+		"start_time":          entry.StartTime,
+		"elapsed_seconds":     elapsed,
+		"elapsed":             utils.FormatDuration(elapsed),
+		"paused_at":           entry.PausedAt,
+		"paused_seconds":      entry.PausedSeconds,
+		"status":              status,
+		"pomodoro_session_id": entry.PomodoroSessionID,
+	}
+}
+
+// formatTimeEntry builds the response payload for a single time entry
+func formatTimeEntry(entry *models.TimeEntry, activityName string) map[string]interface{} {
+	response := map[string]interface{}{
+		"id":            entry.ID,
+		"activity_id":   entry.ActivityID,
+		"activity_name": activityName,
+		"start_time":    entry.StartTime,
+		"end_time":      entry.EndTime,
+		"notes":         entry.Notes,
+		"billable":      entry.Billable,
+		"status":        "stopped",
+	}
+
+	if entry.EndTime == nil {
+		response["status"] = "running"
+		if entry.IsPaused() {
+			response["status"] = "paused"
+		}
+		return response
+	}
+
+	duration := entry.DurationSeconds(*entry.EndTime)
+	response["duration_seconds"] = duration
+	response["duration"] = utils.FormatDuration(duration)
+	return response
+}
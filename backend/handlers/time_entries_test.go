@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestStartTimerStopsTheRunningTimer(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	first := createTestActivity(t, repos, user.ID, "First")
+	second := createTestActivity(t, repos, user.ID, "Second")
+
+	body := decodeResponse(t, serve(t, h.StartTimer, http.MethodPost, "/time-entries/start", "/time-entries/start",
+		map[string]uint{"activity_id": first.ID}, user.ID), http.StatusCreated)
+	if _, ok := body["stopped_previous"]; ok {
+		t.Errorf("nothing was running, but a previous timer was reported stopped")
+	}
+
+	body = decodeResponse(t, serve(t, h.StartTimer, http.MethodPost, "/time-entries/start", "/time-entries/start",
+		map[string]uint{"activity_id": second.ID}, user.ID), http.StatusCreated)
+	stopped, ok := body["stopped_previous"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the first timer to be stopped, got %v", body)
+	}
+	if stopped["activity_name"] != "First" {
+		t.Errorf("stopped activity = %v, want First", stopped["activity_name"])
+	}
+
+	body = decodeResponse(t, serve(t, h.GetActiveTimer, http.MethodGet, "/time-entries/active", "/time-entries/active", nil, user.ID), http.StatusOK)
+	if active := body["active_timer"].(map[string]interface{}); active["activity_name"] != "Second" {
+		t.Errorf("active timer = %v, want Second", active["activity_name"])
+	}
+
+	body = decodeResponse(t, serve(t, h.StopTimer, http.MethodPost, "/time-entries/stop", "/time-entries/stop", nil, user.ID), http.StatusOK)
+	if body["status"] != "stopped" {
+		t.Errorf("status = %v, want stopped", body["status"])
+	}
+	decodeResponse(t, serve(t, h.StopTimer, http.MethodPost, "/time-entries/stop", "/time-entries/stop", nil, user.ID), http.StatusNotFound)
+}
+
+func TestStartTimerRejectsUnknownActivity(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	owner := createTestUser(t, repos, "owner@example.com")
+	other := createTestUser(t, repos, "other@example.com")
+	activity := createTestActivity(t, repos, owner.ID, "Private")
+
+	decodeResponse(t, serve(t, h.StartTimer, http.MethodPost, "/time-entries/start", "/time-entries/start",
+		map[string]uint{"activity_id": activity.ID}, other.ID), http.StatusNotFound)
+}
+
+func TestPauseAndResumeTimer(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Focus")
+
+	decodeResponse(t, serve(t, h.StartTimer, http.MethodPost, "/time-entries/start", "/time-entries/start",
+		map[string]uint{"activity_id": activity.ID}, user.ID), http.StatusCreated)
+
+	body := decodeResponse(t, serve(t, h.PauseTimer, http.MethodPost, "/time-entries/pause", "/time-entries/pause", nil, user.ID), http.StatusOK)
+	if status := body["active_timer"].(map[string]interface{})["status"]; status != "paused" {
+		t.Errorf("status = %v, want paused", status)
+	}
+	decodeResponse(t, serve(t, h.PauseTimer, http.MethodPost, "/time-entries/pause", "/time-entries/pause", nil, user.ID), http.StatusConflict)
+
+	body = decodeResponse(t, serve(t, h.ResumeTimer, http.MethodPost, "/time-entries/resume", "/time-entries/resume", nil, user.ID), http.StatusOK)
+	if status := body["active_timer"].(map[string]interface{})["status"]; status != "running" {
+		t.Errorf("status = %v, want running", status)
+	}
+	decodeResponse(t, serve(t, h.ResumeTimer, http.MethodPost, "/time-entries/resume", "/time-entries/resume", nil, user.ID), http.StatusConflict)
+}
+
+func TestCreateTimeEntryValidatesTimes(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Meeting")
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	end := start.Add(time.Hour)
+	create := func(start, end time.Time) map[string]interface{} {
+		return map[string]interface{}{"activity_id": activity.ID, "start_time": start, "end_time": end}
+	}
+
+	body := decodeResponse(t, serve(t, h.CreateTimeEntry, http.MethodPost, "/time-entries", "/time-entries",
+		create(start, end), user.ID), http.StatusCreated)
+	if body["duration_seconds"] != float64(3600) {
+		t.Errorf("duration_seconds = %v, want 3600", body["duration_seconds"])
+	}
+
+	decodeResponse(t, serve(t, h.CreateTimeEntry, http.MethodPost, "/time-entries", "/time-entries",
+		create(start.Add(30*time.Minute), end.Add(30*time.Minute)), user.ID), http.StatusConflict)
+	decodeResponse(t, serve(t, h.CreateTimeEntry, http.MethodPost, "/time-entries", "/time-entries",
+		create(end.Add(time.Hour), end.Add(30*time.Minute)), user.ID), http.StatusBadRequest)
+	decodeResponse(t, serve(t, h.CreateTimeEntry, http.MethodPost, "/time-entries", "/time-entries",
+		create(time.Now().Add(-time.Minute), time.Now().Add(time.Hour)), user.ID), http.StatusBadRequest)
+}
+
+func TestUpdateAndDeleteTimeEntry(t *testing.T) {
+	repos := newTestRepos(t)
+	h := &TimeEntryHandler{Logger: zap.NewNop(), Repos: repos}
+	user := createTestUser(t, repos, "user@example.com")
+	activity := createTestActivity(t, repos, user.ID, "Writing")
+	other := createTestActivity(t, repos, user.ID, "Editing")
+
+	end := time.Now().Add(-time.Hour)
+	entry := createTestEntry(t, repos, activity, end.Add(-time.Hour), end, 0)
+	target := fmt.Sprintf("/time-entries/%d", entry.ID)
+
+	notes := "moved"
+	body := decodeResponse(t, serve(t, h.UpdateTimeEntry, http.MethodPut, "/time-entries/{id}", target, TimeEntryInput{
+		ActivityID: &other.ID,
+		Notes:      &notes,
+	}, user.ID), http.StatusOK)
+	if body["activity_name"] != "Editing" || body["notes"] != "moved" {
+		t.Errorf("entry was not updated: %v", body)
+	}
+
+	saved, err := repos.TimeEntries.Find(user.ID, entry.ID)
+	if err != nil {
+		t.Fatalf("find entry: %v", err)
+	}
+	if saved.ActivityID != other.ID {
+		t.Errorf("stored activity = %d, want %d", saved.ActivityID, other.ID)
+	}
+
+	decodeResponse(t, serve(t, h.DeleteTimeEntry, http.MethodDelete, "/time-entries/{id}", target, nil, user.ID), http.StatusOK)
+	decodeResponse(t, serve(t, h.DeleteTimeEntry, http.MethodDelete, "/time-entries/{id}", target, nil, user.ID), http.StatusNotFound)
+}
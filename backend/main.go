@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,7 +9,9 @@ import (
 	"syscall"
 
 	"github.com/Felipalds/go-pomodoro/database"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/routes"
+	"github.com/Felipalds/go-pomodoro/services"
 	"go.uber.org/zap"
 )
 
@@ -20,6 +23,14 @@ func main() {
 	}
 	defer logger.Sync()
 
+	// Schema management: go run . migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(logger, os.Args[2:]); err != nil {
+			logger.Fatal("Migrate command failed", zap.Error(err))
+		}
+		return
+	}
+
 	logger.Info("Starting Time Tracker API...")
 
 	// Initialize database
@@ -28,8 +39,45 @@ func main() {
 	}
 	defer database.Close(logger)
 
+	// Data access for the handlers, matching the configured driver
+	repos, err := repository.New(database.DB)
+	if err != nil {
+		logger.Fatal("Failed to set up repositories", zap.Error(err))
+	}
+
+	// Reward catalog: served from the local cache right away and refreshed in the background
+	ddService := services.NewDataDragonServiceFromEnv(logger)
+	if err := ddService.LoadCache(); err != nil {
+		logger.Error("Failed to load Data Dragon catalog cache", zap.Error(err))
+	}
+	stats := ddService.GetStats()
+	logger.Info("Data Dragon catalog loaded",
+		zap.String("version", ddService.GetVersion()),
+		zap.Int("champions", stats["champions"]),
+		zap.Int("items", stats["items"]),
+		zap.Int("skins", stats["skins"]),
+		zap.Int("icons", stats["icons"]),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ddService.Start(ctx)
+
+	// Reward catalogs users can choose from
+	catalogs := setupRewardCatalogs(logger, repos, ddService)
+
+	// Live updates, including goals crossed by running timers
+	eventHub := services.NewEventHub()
+	services.NewGoalWatcher(database.DB, eventHub, logger).Start(ctx)
+
+	// Password reset emails; refuse to start rather than silently fall back to logging them
+	mailer, err := services.NewMailerFromEnv(logger)
+	if err != nil {
+		logger.Fatal("Failed to set up mailer", zap.Error(err))
+	}
+
 	// Setup routes
-	router := routes.SetupRoutes(logger)
+	router := routes.SetupRoutes(logger, repos, catalogs, eventHub, mailer)
 
 	// Start HTTP server
 	port := "8085"
@@ -63,3 +111,41 @@ func main() {
 
 	logger.Info("Server stopped gracefully")
 }
+
+// setupRewardCatalogs registers the League of Legends catalog, the catalog files in
+// REWARD_CATALOG_DIR (default ./catalogs) and the catalogs managed through the admin API
+// A catalog never replaces one registered before it with the same key
+func setupRewardCatalogs(logger *zap.Logger, repos *repository.Repositories, ddService *services.DataDragonService) *services.RewardCatalogRegistry {
+	registry := services.NewRewardCatalogRegistry(ddService)
+
+	register := func(catalog services.RewardCatalog, source string) {
+		if _, exists := registry.Get(catalog.Key()); exists {
+			logger.Error("Skipping reward catalog with a duplicate key", zap.String("key", catalog.Key()), zap.String("source", source))
+			return
+		}
+		registry.Register(catalog)
+		logger.Info("Reward catalog loaded", zap.String("key", catalog.Key()), zap.String("source", source), zap.Int("rewards", catalog.Size()))
+	}
+
+	dir := os.Getenv("REWARD_CATALOG_DIR")
+	if dir == "" {
+		dir = "catalogs"
+	}
+	fileCatalogs, err := services.LoadGenericCatalogDir(dir)
+	if err != nil {
+		logger.Error("Failed to load reward catalog files", zap.String("dir", dir), zap.Error(err))
+	}
+	for _, catalog := range fileCatalogs {
+		register(catalog, catalog.Path())
+	}
+
+	records, err := repos.Catalogs.List()
+	if err != nil {
+		logger.Error("Failed to load stored reward catalogs", zap.Error(err))
+	}
+	for i := range records {
+		register(services.NewGenericCatalogFromRecord(&records[i]), "database")
+	}
+
+	return registry
+}
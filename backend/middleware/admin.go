@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Felipalds/go-pomodoro/repository"
+	"github.com/Felipalds/go-pomodoro/utils"
+)
+
+// RequireAdmin only lets through users whose email is listed in ADMIN_EMAILS (comma-separated)
+// who logged in with a password; personal API tokens are never accepted
+// It must run after AuthMiddleware
+func RequireAdmin(users repository.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetAPITokenIDFromContext(r) != 0 {
+				utils.ErrorResponse(w, http.StatusForbidden, "Admin access requires logging in with a password")
+				return
+			}
+
+			user, err := users.FindByID(GetUserIDFromContext(r))
+			if err != nil || !isAdminEmail(user.Email) {
+				utils.ErrorResponse(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isAdminEmail reports whether the email is listed in ADMIN_EMAILS
+func isAdminEmail(email string) bool {
+	for _, admin := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		admin = strings.TrimSpace(admin)
+		if admin != "" && strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	return false
+}
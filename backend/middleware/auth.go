@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/Felipalds/go-pomodoro/database"
 	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/Felipalds/go-pomodoro/utils"
 )
@@ -27,6 +29,30 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		tokenString := parts[1]
 
+		// Personal API tokens are opaque and checked against the database
+		if services.IsAPIToken(tokenString) {
+			apiToken, err := services.AuthenticateAPIToken(database.DB, tokenString)
+			if err != nil {
+				if err == services.ErrInvalidAPIToken {
+					utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or revoked API token")
+					return
+				}
+				utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to verify API token")
+				return
+			}
+
+			if !tokenScopesAllow(apiToken.ScopeList(), r) {
+				utils.ErrorResponse(w, http.StatusForbidden, "API token scope does not allow this request")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "user_id", apiToken.UserID)
+			ctx = context.WithValue(ctx, "api_token_id", apiToken.ID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		// Validate token
 		claims, err := services.ValidateToken(tokenString)
 		if err != nil {
@@ -38,14 +64,65 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Reject tokens whose session was logged out
+		active, err := services.IsSessionActive(database.DB, claims.UserID, claims.SessionID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to verify session")
+			return
+		}
+		if !active {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Session has been revoked")
+			return
+		}
+
 		// Add user info to context
 		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
 		ctx = context.WithValue(ctx, "user_email", claims.Email)
+		ctx = context.WithValue(ctx, "session_id", claims.SessionID)
+		if claims.ExpiresAt != nil {
+			ctx = context.WithValue(ctx, "token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// StreamTicketAuth authenticates the event stream with a single-use ticket passed as the ticket query parameter,
+// since the browser EventSource cannot set headers; tokens are never accepted in the query string
+// Requests without a ticket are authenticated from the Authorization header by AuthMiddleware
+func StreamTicketAuth(tickets *services.StreamTicketStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withHeader := AuthMiddleware(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.URL.Query().Get("ticket")
+			if value == "" {
+				withHeader.ServeHTTP(w, r)
+				return
+			}
+
+			ticket, ok := tickets.Redeem(value)
+			if !ok {
+				utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired stream ticket")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "user_id", ticket.UserID)
+			if ticket.SessionID != 0 {
+				ctx = context.WithValue(ctx, "session_id", ticket.SessionID)
+			}
+			if ticket.APITokenID != 0 {
+				ctx = context.WithValue(ctx, "api_token_id", ticket.APITokenID)
+			}
+			if !ticket.ExpiresAt.IsZero() {
+				ctx = context.WithValue(ctx, "token_expires_at", ticket.ExpiresAt)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // GetUserIDFromContext extracts user ID from request context
 func GetUserIDFromContext(r *http.Request) uint {
 	userID, ok := r.Context().Value("user_id").(uint)
@@ -54,3 +131,30 @@ func GetUserIDFromContext(r *http.Request) uint {
 	}
 	return userID
 }
+
+// GetSessionIDFromContext extracts the session ID of the access token from request context
+func GetSessionIDFromContext(r *http.Request) uint {
+	sessionID, ok := r.Context().Value("session_id").(uint)
+	if !ok {
+		return 0
+	}
+	return sessionID
+}
+
+// GetAPITokenIDFromContext extracts the ID of the personal API token the request was made with, 0 for access tokens
+func GetAPITokenIDFromContext(r *http.Request) uint {
+	apiTokenID, ok := r.Context().Value("api_token_id").(uint)
+	if !ok {
+		return 0
+	}
+	return apiTokenID
+}
+
+// GetTokenExpiryFromContext extracts when the access token of the request expires, zero for API tokens
+func GetTokenExpiryFromContext(r *http.Request) time.Time {
+	expiresAt, ok := r.Context().Value("token_expires_at").(time.Time)
+	if !ok {
+		return time.Time{}
+	}
+	return expiresAt
+}
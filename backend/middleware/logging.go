@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestLogger logs each request like chi's Logger but without its query string,
+// which can carry secrets such as stream tickets
+var RequestLogger = chimiddleware.RequestLogger(&redactedLogFormatter{
+	LogFormatter: &chimiddleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+})
+
+// redactedLogFormatter hides the query of the request URI from the wrapped formatter
+type redactedLogFormatter struct {
+	chimiddleware.LogFormatter
+}
+
+// NewLogEntry starts the log entry of a copy of the request with its query replaced
+func (f *redactedLogFormatter) NewLogEntry(r *http.Request) chimiddleware.LogEntry {
+	redacted := *r
+	redacted.RequestURI = r.URL.EscapedPath()
+	if r.URL.RawQuery != "" {
+		redacted.RequestURI += "?[redacted]"
+	}
+	return f.LogFormatter.NewLogEntry(&redacted)
+}
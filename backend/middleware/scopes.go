@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Felipalds/go-pomodoro/models"
+)
+
+// timerScopeRoutes are the endpoints a timer-scoped token may call, by method and path
+var timerScopeRoutes = map[string]bool{
+	"GET /api/activities":           true,
+	"GET /api/events":               true,
+	"POST /api/events/ticket":       true,
+	"GET /api/time-entries/active":  true,
+	"POST /api/time-entries/start":  true,
+	"POST /api/time-entries/stop":   true,
+	"POST /api/time-entries/pause":  true,
+	"POST /api/time-entries/resume": true,
+	"GET /api/pomodoro":             true,
+	"POST /api/pomodoro/start":      true,
+	"POST /api/pomodoro/pause":      true,
+	"POST /api/pomodoro/resume":     true,
+	"POST /api/pomodoro/skip":       true,
+	"POST /api/pomodoro/abort":      true,
+}
+
+// readOnlyPostRoutes are POST endpoints that change nothing, so a read-scoped token may call them
+var readOnlyPostRoutes = map[string]bool{
+	"POST /api/events/ticket": true,
+}
+
+// tokenScopesAllow reports whether a personal API token with the given scopes may make the request
+// Account management (sessions, password, tokens) and admin routes always require logging in with a password
+func tokenScopesAllow(scopes []models.TokenScope, r *http.Request) bool {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	if strings.HasPrefix(path, "/api/auth/") && !(r.Method == http.MethodGet && path == "/api/auth/me") {
+		return false
+	}
+	if path == "/api/admin" || strings.HasPrefix(path, "/api/admin/") {
+		return false
+	}
+
+	for _, scope := range scopes {
+		switch scope {
+		case models.TokenScopeWrite:
+			return true
+		case models.TokenScopeRead:
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || readOnlyPostRoutes[r.Method+" "+path] {
+				return true
+			}
+		case models.TokenScopeTimer:
+			if timerScopeRoutes[r.Method+" "+path] {
+				return true
+			}
+		}
+	}
+	return false
+}
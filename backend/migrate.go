@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Felipalds/go-pomodoro/database"
+	"go.uber.org/zap"
+)
+
+const migrateUsage = `Usage: go run . migrate <command>
+
+Commands:
+  up            apply all pending migrations and seed default data
+  down [steps]  roll back the latest migration, or the latest <steps> migrations
+  status        list migrations and whether they are applied`
+
+// runMigrateCommand runs the migrate subcommand with the arguments that follow it
+func runMigrateCommand(logger *zap.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n\n%s", migrateUsage)
+	}
+
+	if err := database.Connect(logger); err != nil {
+		return err
+	}
+	defer database.Close(logger)
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(database.DB, logger)
+		if err != nil {
+			return err
+		}
+		if err := database.PrepareData(logger); err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migrations\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+		}
+		rolledBack, err := database.MigrateDown(database.DB, logger, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %d migrations\n", rolledBack)
+
+	case "status":
+		statuses, err := database.MigrationStatuses(database.DB)
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		writer.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	}
+
+	return nil
+}
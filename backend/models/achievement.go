@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserAchievement records when a user unlocked an achievement
+// AchievementKey refers to one of the achievement definitions in the services package
+type UserAchievement struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_user_achievements_user_key" json:"user_id"`
+	AchievementKey string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_user_achievements_user_key" json:"achievement_key"`
+	UnlockedAt     time.Time `gorm:"not null" json:"unlocked_at"`
+}
@@ -13,7 +13,11 @@ type Activity struct {
 	SubCategoryID     *uint       `gorm:"index" json:"sub_category_id,omitempty"`
 	SubCategory       *Category   `gorm:"foreignKey:SubCategoryID" json:"sub_category,omitempty"`
 	IntervalsRewarded int         `gorm:"default:0" json:"intervals_rewarded"` // 15-min intervals already rewarded for LoL rewards
-	DeletedAt         *time.Time  `gorm:"index" json:"deleted_at,omitempty"`   // Soft delete
+	ProjectID         *uint       `gorm:"index" json:"project_id,omitempty"`   // Project the activity's time is billed to
+	Project           *Project    `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	HourlyRateCents   *int64      `json:"hourly_rate_cents,omitempty"`                                   // Overrides the project and client rate
+	Currency          string      `gorm:"type:varchar(3);not null;default:''" json:"currency,omitempty"` // Empty uses the project's or client's
+	DeletedAt         *time.Time  `gorm:"index" json:"deleted_at,omitempty"`                             // Soft delete
 	CreatedAt         time.Time   `json:"created_at"`
 	UpdatedAt         time.Time   `json:"updated_at"`
 	TimeEntries       []TimeEntry `gorm:"constraint:OnDelete:CASCADE;" json:"time_entries,omitempty"`
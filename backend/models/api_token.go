@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TokenScope limits what a personal API token can do
+type TokenScope string
+
+const (
+	TokenScopeRead  TokenScope = "read"  // any GET request
+	TokenScopeTimer TokenScope = "timer" // start, stop, pause and resume timers and pomodoros
+	TokenScopeWrite TokenScope = "write" // everything except account management
+)
+
+// ValidTokenScopes lists the scopes a token can be created with
+var ValidTokenScopes = []TokenScope{TokenScopeRead, TokenScopeTimer, TokenScopeWrite}
+
+// APIToken is a long-lived personal access token for scripts and integrations
+// Only the SHA-256 hash of the token is stored; Prefix is kept to tell tokens apart in listings
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	Scopes     string     `gorm:"not null" json:"-"` // comma-separated TokenScope values
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the token's scopes
+func (t *APIToken) ScopeList() []TokenScope {
+	scopes := []TokenScope{}
+	for _, scope := range strings.Split(t.Scopes, ",") {
+		if scope != "" {
+			scopes = append(scopes, TokenScope(scope))
+		}
+	}
+	return scopes
+}
+
+// IsActive reports whether the token can still be used to authenticate
+func (t *APIToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// DefaultCurrency is the currency of a client created without one
+const DefaultCurrency = "USD"
+
+// currencyPattern matches an ISO 4217 currency code such as USD or EUR
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// RoundingMode says which way billed time is rounded to the client's rounding interval
+type RoundingMode string
+
+const (
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+	RoundNearest RoundingMode = "nearest"
+)
+
+// Client is a customer the user bills tracked time to, through its projects
+// Rates are in cents of the client's currency unless a project or activity sets its own
+// Supports soft delete via DeletedAt field
+type Client struct {
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	UserID          uint   `gorm:"not null;index" json:"user_id"`
+	Name            string `gorm:"type:varchar(200);not null" json:"name"`
+	HourlyRateCents *int64 `json:"hourly_rate_cents"`
+	Currency        string `gorm:"type:varchar(3);not null;default:USD" json:"currency"`
+	// RoundingMinutes is the interval each billed entry is rounded to, 0 bills the exact time
+	RoundingMinutes int          `gorm:"not null;default:0" json:"rounding_minutes"`
+	RoundingMode    RoundingMode `gorm:"type:varchar(8);not null;default:up" json:"rounding_mode"`
+	DeletedAt       *time.Time   `gorm:"index" json:"deleted_at,omitempty"` // Soft delete
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// Project is a piece of work for a client that activities belong to
+// An empty currency uses the client's
+// Supports soft delete via DeletedAt field
+type Project struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"not null;index" json:"user_id"`
+	ClientID        uint       `gorm:"not null;index" json:"client_id"`
+	Client          *Client    `gorm:"foreignKey:ClientID" json:"client,omitempty"`
+	Name            string     `gorm:"type:varchar(200);not null" json:"name"`
+	HourlyRateCents *int64     `json:"hourly_rate_cents"`
+	Currency        string     `gorm:"type:varchar(3);not null;default:''" json:"currency"`
+	DeletedAt       *time.Time `gorm:"index" json:"deleted_at,omitempty"` // Soft delete
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Validate checks the client can be stored
+func (c *Client) Validate() error {
+	if len(c.Name) == 0 || len(c.Name) > 200 {
+		return errors.New("name must be 1-200 characters")
+	}
+	if err := validateRate(c.HourlyRateCents, c.Currency); err != nil {
+		return err
+	}
+	if c.Currency == "" {
+		return errors.New("currency is required")
+	}
+	if c.RoundingMinutes < 0 || c.RoundingMinutes > 24*60 {
+		return errors.New("rounding_minutes must be between 0 and 1440")
+	}
+	if c.RoundingMode != RoundUp && c.RoundingMode != RoundDown && c.RoundingMode != RoundNearest {
+		return errors.New("rounding_mode must be up, down or nearest")
+	}
+	return nil
+}
+
+// Validate checks the project can be stored
+func (p *Project) Validate() error {
+	if len(p.Name) == 0 || len(p.Name) > 200 {
+		return errors.New("name must be 1-200 characters")
+	}
+	if p.ClientID == 0 {
+		return errors.New("client_id is required")
+	}
+	return validateRate(p.HourlyRateCents, p.Currency)
+}
+
+// ValidateBilling checks the activity's own rate and currency
+func (a *Activity) ValidateBilling() error {
+	return validateRate(a.HourlyRateCents, a.Currency)
+}
+
+// validateRate checks an optional hourly rate and currency code
+func validateRate(hourlyRateCents *int64, currency string) error {
+	if hourlyRateCents != nil && *hourlyRateCents < 0 {
+		return errors.New("hourly_rate_cents cannot be negative")
+	}
+	if currency != "" && !currencyPattern.MatchString(currency) {
+		return errors.New("currency must be a 3 letter ISO 4217 code such as USD")
+	}
+	return nil
+}
@@ -4,10 +4,12 @@ import "time"
 
 // Category represents a category for organizing activities
 // Categories can be used as both main categories and subcategories
+// Categories are owned per user; rows with UserID 0 are the default set copied on registration
 // Supports soft delete via DeletedAt field
 type Category struct {
 	ID        uint       `gorm:"primaryKey" json:"id"`
-	Name      string     `gorm:"not null;unique" json:"name"`
+	UserID    uint       `gorm:"not null;default:0;uniqueIndex:idx_categories_user_name" json:"user_id"`
+	Name      string     `gorm:"not null;uniqueIndex:idx_categories_user_name" json:"name"`
 	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"` // Soft delete
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// EssenceReason says why a user's essence balance changed
+type EssenceReason string
+
+const (
+	EssenceReasonDisenchant EssenceReason = "disenchant" // A duplicate reward was turned into essence
+	EssenceReasonCraft      EssenceReason = "craft"      // Essence was spent on a chosen reward
+)
+
+// DisenchantValues is the essence a duplicate reward is worth, by rarity
+var DisenchantValues = map[Rarity]int{
+	RarityCommon: 20,
+	RarityRare:   80,
+	RarityEpic:   200,
+}
+
+// CraftCosts is the essence needed to craft a reward, by rarity
+var CraftCosts = map[Rarity]int{
+	RarityCommon: 100,
+	RarityRare:   400,
+	RarityEpic:   1000,
+}
+
+// EssenceBalance is a user's current amount of crafting essence
+// The database rejects negative balances
+type EssenceBalance struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Balance   int       `gorm:"not null;default:0" json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EssenceTransaction is one change of a user's essence balance
+// Amount is positive for credits and negative for debits; Balance is the balance right after it
+type EssenceTransaction struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	UserID     uint          `gorm:"not null;index" json:"user_id"`
+	Amount     int           `gorm:"not null" json:"amount"`
+	Balance    int           `gorm:"not null" json:"balance"`
+	Reason     EssenceReason `gorm:"type:varchar(20);not null" json:"reason"`
+	Catalog    string        `gorm:"type:varchar(64);not null" json:"catalog"`
+	RewardType RewardType    `gorm:"not null" json:"reward_type"`
+	ExternalID string        `gorm:"not null" json:"external_id"`
+	Name       string        `gorm:"not null" json:"name"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
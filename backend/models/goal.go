@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// GoalKind says whether a goal's target is a minimum to reach or a budget not to exceed
+type GoalKind string
+
+const (
+	GoalKindMinimum GoalKind = "minimum"
+	GoalKindMaximum GoalKind = "maximum"
+)
+
+// GoalPeriod is how often a goal's progress starts over
+type GoalPeriod string
+
+const (
+	GoalPeriodDay   GoalPeriod = "day"
+	GoalPeriodWeek  GoalPeriod = "week"
+	GoalPeriodMonth GoalPeriod = "month"
+)
+
+// GoalScope is what kind of record a goal counts the tracked time of
+type GoalScope string
+
+const (
+	GoalScopeActivity GoalScope = "activity"
+	GoalScopeCategory GoalScope = "category" // Matches the main or sub category of an activity
+	GoalScopeTag      GoalScope = "tag"
+)
+
+// MaxGoalTargetMinutes is the largest target a goal can have, a month of 31 days
+const MaxGoalTargetMinutes = 31 * 24 * 60
+
+// Goal is a target for the time tracked on an activity, category or tag in each day, week or month
+// Periods are counted in the user's timezone and weeks begin on their week start
+type Goal struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	Name          string     `gorm:"type:varchar(100);not null;default:''" json:"name"`
+	Kind          GoalKind   `gorm:"type:varchar(16);not null" json:"kind"`
+	Period        GoalPeriod `gorm:"type:varchar(16);not null" json:"period"`
+	TargetMinutes int        `gorm:"not null" json:"target_minutes"`
+	ScopeType     GoalScope  `gorm:"type:varchar(16);not null" json:"scope_type"`
+	ScopeID       uint       `gorm:"not null" json:"scope_id"` // ID of the activity, category or tag
+	// CrossedPeriod is the start of the last period the target was crossed in, as 2006-01-02,
+	// so crossing it is only announced once per period
+	CrossedPeriod string    `gorm:"type:varchar(10);not null;default:''" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Validate checks the goal can be stored
+func (g *Goal) Validate() error {
+	if g.Kind != GoalKindMinimum && g.Kind != GoalKindMaximum {
+		return errors.New("kind must be minimum or maximum")
+	}
+	if g.Period != GoalPeriodDay && g.Period != GoalPeriodWeek && g.Period != GoalPeriodMonth {
+		return errors.New("period must be day, week or month")
+	}
+	if g.TargetMinutes < 1 || g.TargetMinutes > MaxGoalTargetMinutes {
+		return errors.New("target_minutes must be between 1 and 44640")
+	}
+	if g.ScopeType != GoalScopeActivity && g.ScopeType != GoalScopeCategory && g.ScopeType != GoalScopeTag {
+		return errors.New("scope_type must be activity, category or tag")
+	}
+	if g.ScopeID == 0 {
+		return errors.New("scope_id is required")
+	}
+	if len(g.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	return nil
+}
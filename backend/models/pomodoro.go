@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// PomodoroPhase represents the current phase of a pomodoro cycle
+type PomodoroPhase string
+
+const (
+	PomodoroPhaseWork       PomodoroPhase = "work"
+	PomodoroPhaseShortBreak PomodoroPhase = "short_break"
+	PomodoroPhaseLongBreak  PomodoroPhase = "long_break"
+)
+
+// PomodoroStatus represents the lifecycle state of a pomodoro session
+type PomodoroStatus string
+
+const (
+	PomodoroStatusRunning PomodoroStatus = "running" // Current phase is counting down
+	PomodoroStatusPaused  PomodoroStatus = "paused"  // Current phase is frozen with RemainingSeconds left
+	PomodoroStatusWaiting PomodoroStatus = "waiting" // Break finished, waiting for the user to start the next work phase
+	PomodoroStatusAborted PomodoroStatus = "aborted" // Session ended by the user
+)
+
+// PomodoroSession holds the server-side state of a pomodoro cycle
+// Work phases are tracked as regular TimeEntry rows linked back to the session
+type PomodoroSession struct {
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	UserID             uint           `gorm:"not null;index" json:"user_id"`
+	ActivityID         uint           `gorm:"not null;index" json:"activity_id"`
+	Activity           Activity       `gorm:"foreignKey:ActivityID" json:"activity,omitempty"`
+	WorkMinutes        int            `gorm:"not null;default:25" json:"work_minutes"`
+	ShortBreakMinutes  int            `gorm:"not null;default:5" json:"short_break_minutes"`
+	LongBreakMinutes   int            `gorm:"not null;default:15" json:"long_break_minutes"`
+	LongBreakEvery     int            `gorm:"not null;default:4" json:"long_break_every"` // Long break after every N pomodoros
+	Phase              PomodoroPhase  `gorm:"not null" json:"phase"`
+	Status             PomodoroStatus `gorm:"not null;index" json:"status"`
+	CompletedPomodoros int            `gorm:"default:0" json:"completed_pomodoros"`
+	PhaseStartedAt     time.Time      `gorm:"not null" json:"phase_started_at"`
+	PhaseEndsAt        *time.Time     `json:"phase_ends_at,omitempty"`            // NULL while paused or waiting
+	RemainingSeconds   int64          `gorm:"default:0" json:"remaining_seconds"` // Frozen remaining time while paused
+	TimeEntryID        *uint          `json:"time_entry_id,omitempty"`            // Running entry of the current work phase
+	EndedAt            *time.Time     `json:"ended_at,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+
+	// ClosedWorkEntry is set when loading or changing the session stopped the entry of a work phase,
+	// so the caller knows achievements need evaluating; it is not stored
+	ClosedWorkEntry bool `gorm:"-" json:"-"`
+}
+
+// PhaseDuration returns the configured length of a phase
+func (s *PomodoroSession) PhaseDuration(phase PomodoroPhase) time.Duration {
+	switch phase {
+	case PomodoroPhaseShortBreak:
+		return time.Duration(s.ShortBreakMinutes) * time.Minute
+	case PomodoroPhaseLongBreak:
+		return time.Duration(s.LongBreakMinutes) * time.Minute
+	default:
+		return time.Duration(s.WorkMinutes) * time.Minute
+	}
+}
+
+// IsActive reports whether the session has not been ended
+func (s *PomodoroSession) IsActive() bool {
+	return s.Status != PomodoroStatusAborted
+}
@@ -2,7 +2,8 @@ package models
 
 import "time"
 
-// RewardType represents the type of LoL reward
+// RewardType represents the type of reward
+// The constants are the League of Legends types; custom catalogs define their own
 type RewardType string
 
 const (
@@ -21,10 +22,12 @@ const (
 	RarityEpic   Rarity = "epic"
 )
 
-// UserReward stores all rewards earned by the user
+// UserReward is one reward drop, the history of everything the user was handed out
+// What they own is summed up in OwnedReward
 type UserReward struct {
 	ID         uint       `gorm:"primaryKey" json:"id"`
 	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Catalog    string     `gorm:"type:varchar(64);not null;default:lol" json:"catalog"` // Key of the catalog it was drawn from
 	RewardType RewardType `gorm:"not null" json:"reward_type"`
 	ExternalID string     `gorm:"not null" json:"external_id"` // e.g., 'Ahri', '3031', 'Ahri_1'
 	Name       string     `gorm:"not null" json:"name"`
@@ -33,11 +36,54 @@ type UserReward struct {
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
+// OwnedReward is a reward in the user's collection, with how many copies of it they hold
+// There is one per user and reward; Count goes up with every drop and down when a copy is disenchanted
+type OwnedReward struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"not null;uniqueIndex:idx_owned_rewards_user_reward" json:"user_id"`
+	Catalog         string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_owned_rewards_user_reward" json:"catalog"`
+	RewardType      RewardType `gorm:"not null;uniqueIndex:idx_owned_rewards_user_reward" json:"reward_type"`
+	ExternalID      string     `gorm:"not null;uniqueIndex:idx_owned_rewards_user_reward" json:"external_id"`
+	Name            string     `gorm:"not null" json:"name"`
+	ImageURL        string     `gorm:"not null" json:"image_url"`
+	Rarity          Rarity     `gorm:"not null" json:"rarity"`
+	Count           int        `gorm:"not null;default:1" json:"count"`
+	FirstObtainedAt time.Time  `gorm:"not null" json:"first_obtained_at"`
+	LastObtainedAt  time.Time  `gorm:"not null" json:"last_obtained_at"`
+}
+
+// RewardCatalogRecord is a custom reward catalog managed through the admin API
+// Catalogs loaded from files are not stored in the database
+type RewardCatalogRecord struct {
+	ID        uint                `gorm:"primaryKey" json:"id"`
+	Key       string              `gorm:"type:varchar(64);not null;uniqueIndex" json:"key"`
+	Name      string              `gorm:"type:varchar(100);not null" json:"name"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Items     []RewardCatalogItem `gorm:"foreignKey:CatalogID;constraint:OnDelete:CASCADE;" json:"items"`
+}
+
+// TableName keeps the table named after the catalogs rather than the record
+func (RewardCatalogRecord) TableName() string {
+	return "reward_catalogs"
+}
+
+// RewardCatalogItem is one reward of a custom catalog
+type RewardCatalogItem struct {
+	ID         uint       `gorm:"primaryKey" json:"-"`
+	CatalogID  uint       `gorm:"not null;uniqueIndex:idx_reward_catalog_items_catalog_external" json:"-"`
+	ExternalID string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_reward_catalog_items_catalog_external" json:"id"`
+	RewardType RewardType `gorm:"type:varchar(64);not null" json:"type"`
+	Name       string     `gorm:"not null" json:"name"`
+	ImageURL   string     `gorm:"not null;default:''" json:"image_url"`
+	Rarity     Rarity     `gorm:"not null" json:"rarity"`
+}
+
 // ChampionMastery tracks mastery level for each champion
 type ChampionMastery struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
-	UserID        uint      `gorm:"not null;index" json:"user_id"`
-	ChampionID    string    `gorm:"not null;index:idx_user_champion,unique" json:"champion_id"` // e.g., 'Ahri'
+	UserID        uint      `gorm:"not null;index;uniqueIndex:idx_user_champion" json:"user_id"`
+	ChampionID    string    `gorm:"not null;uniqueIndex:idx_user_champion" json:"champion_id"` // e.g., 'Ahri'
 	ChampionName  string    `gorm:"not null" json:"champion_name"`
 	ImageURL      string    `gorm:"not null" json:"image_url"`
 	MasteryLevel  int       `gorm:"default:1" json:"mastery_level"` // 1-7
@@ -53,3 +99,58 @@ func (cm *ChampionMastery) GetMasteryLevel() int {
 	}
 	return cm.TimesObtained
 }
+
+const (
+	// PityGuaranteeClaims is the claim that is guaranteed to be rare or better after a streak of common drops
+	PityGuaranteeClaims = 10
+	// PitySoftStart is how many common claims in a row are allowed before the odds of a rare start rising
+	PitySoftStart = 5
+	// PityStepPercent is how many percentage points the odds of a rare rise with each claim past PitySoftStart
+	PityStepPercent = 10
+)
+
+// RewardPity counts a user's claims since their last rare or epic drop
+// The longer the streak of common drops, the better the odds of the next claim
+type RewardPity struct {
+	UserID          uint       `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	ClaimsSinceRare int        `gorm:"not null;default:0" json:"claims_since_rare"`
+	LastRareAt      *time.Time `json:"last_rare_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName keeps a single pity row per user in reward_pity
+func (RewardPity) TableName() string {
+	return "reward_pity"
+}
+
+// RareBonus returns the percentage points added to the odds of a rare on the next claim
+// 100 means the next claim is guaranteed to be rare or better
+func (p *RewardPity) RareBonus() int {
+	next := p.ClaimsSinceRare + 1
+	switch {
+	case next >= PityGuaranteeClaims:
+		return 100
+	case next > PitySoftStart:
+		return (next - PitySoftStart) * PityStepPercent
+	default:
+		return 0
+	}
+}
+
+// ClaimsUntilGuaranteed returns how many claims it takes to reach the guaranteed rare, counting that claim
+func (p *RewardPity) ClaimsUntilGuaranteed() int {
+	if p.ClaimsSinceRare >= PityGuaranteeClaims-1 {
+		return 1
+	}
+	return PityGuaranteeClaims - p.ClaimsSinceRare
+}
+
+// Record updates the counter with the rarity of a claimed reward
+func (p *RewardPity) Record(rarity Rarity, at time.Time) {
+	if rarity == RarityRare || rarity == RarityEpic {
+		p.ClaimsSinceRare = 0
+		p.LastRareAt = &at
+		return
+	}
+	p.ClaimsSinceRare++
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Session is a login of a user on one device
+// Access tokens carry the session ID so revoking the session invalidates them immediately
+// Only the SHA-256 hash of the current refresh token is stored; it is replaced on every refresh
+type Session struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	UserID           uint       `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	UserAgent        string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IPAddress        string     `gorm:"type:varchar(64)" json:"ip_address"`
+	ExpiresAt        time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt       time.Time  `gorm:"not null" json:"last_used_at"`
+	RevokedAt        *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the session can still be used to authenticate
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// DefaultStreakMinMinutes is how many minutes a day needs to count towards a streak unless the user changes it
+const DefaultStreakMinMinutes = 15
+
+// MaxStreakFreezeTokens is how many freeze tokens a user can hold at once
+const MaxStreakFreezeTokens = 3
+
+// StreakState holds a user's streak settings and unused freeze tokens
+type StreakState struct {
+	UserID       uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	MinMinutes   int       `gorm:"not null;default:15" json:"min_minutes"` // Tracked minutes a day needs to count
+	FreezeTokens int       `gorm:"not null;default:0" json:"freeze_tokens"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// StreakFreeze is a missed day a freeze token was spent on, so it does not break the streak
+// Day is the calendar date in the user's time zone, formatted as 2006-01-02
+type StreakFreeze struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_streak_freezes_user_day" json:"user_id"`
+	Day       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_streak_freezes_user_day" json:"day"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StreakBonusClaim records a bonus reward claimed for reaching a streak milestone
+// The start of a streak moves back when earlier days count and forward when the minimum is raised
+// or a day is deleted, so a milestone is claimed for the current streak if the day it was reached
+// on, ReachedOn, is part of it
+type StreakBonusClaim struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_streak_bonus_claims_user_streak_milestone" json:"user_id"`
+	StreakStart  string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_streak_bonus_claims_user_streak_milestone" json:"streak_start"`
+	Milestone    int       `gorm:"not null;uniqueIndex:idx_streak_bonus_claims_user_streak_milestone" json:"milestone"`
+	ReachedOn    string    `gorm:"type:varchar(10);not null" json:"reached_on"` // StreakStart plus Milestone-1 days
+	UserRewardID *uint     `json:"user_reward_id,omitempty"`
+	ClaimedAt    time.Time `gorm:"not null" json:"claimed_at"`
+}
@@ -4,10 +4,12 @@ import "time"
 
 // Tag represents a tag for flexible activity organization
 // Tags have many-to-many relationship with activities
+// Tags are owned per user and unique by name within that user
 // Supports soft delete via DeletedAt field
 type Tag struct {
 	ID         uint       `gorm:"primaryKey" json:"id"`
-	Name       string     `gorm:"not null;unique" json:"name"`
+	UserID     uint       `gorm:"not null;default:0;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name       string     `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"name"`
 	DeletedAt  *time.Time `gorm:"index" json:"deleted_at,omitempty"` // Soft delete
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
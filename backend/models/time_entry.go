@@ -1,16 +1,69 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/utils"
+)
 
 // TimeEntry represents a time tracking session for an activity
 // EndTime is NULL when timer is still running
+// PausedAt is set while the timer is paused; PausedSeconds sums all finished pauses
+// New entries must set Billable, usually to true like the column default
 type TimeEntry struct {
-	ID         uint       `gorm:"primaryKey" json:"id"`
-	UserID     uint       `gorm:"not null;index" json:"user_id"`
-	ActivityID uint       `gorm:"not null;index" json:"activity_id"`
-	Activity   Activity   `gorm:"foreignKey:ActivityID" json:"activity,omitempty"`
-	StartTime  time.Time  `gorm:"not null;index" json:"start_time"`
-	EndTime    *time.Time `gorm:"index" json:"end_time,omitempty"`
-	Notes      *string    `gorm:"type:text" json:"notes,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	UserID        uint        `gorm:"not null;index" json:"user_id"`
+	ActivityID    uint        `gorm:"not null;index" json:"activity_id"`
+	Activity      Activity    `gorm:"foreignKey:ActivityID" json:"activity,omitempty"`
+	StartTime     time.Time   `gorm:"not null;index" json:"start_time"`
+	EndTime       *time.Time  `gorm:"index" json:"end_time,omitempty"`
+	PausedAt      *time.Time  `json:"paused_at,omitempty"`
+	PausedSeconds int64       `gorm:"not null;default:0" json:"paused_seconds"`
+	Notes         *string     `gorm:"type:text" json:"notes,omitempty"`
+	Billable      bool        `gorm:"not null" json:"billable"` // Billed when the activity belongs to a project; no gorm default, which would replace an explicit false
+	CreatedAt     time.Time   `json:"created_at"`
+	Pauses        []TimePause `gorm:"constraint:OnDelete:CASCADE;" json:"pauses,omitempty"`
+
+	// Pomodoro tracking (NULL for entries created by the plain stopwatch)
+	PomodoroSessionID *uint `gorm:"index" json:"pomodoro_session_id,omitempty"`
+	PomodoroCompleted bool  `gorm:"default:false" json:"pomodoro_completed"` // Work phase ran its full length
+
+	// Import tracking (NULL for entries tracked in this app)
+	ImportSource *string `json:"import_source,omitempty"` // e.g. 'toggl', 'clockify'
+	ImportKey    *string `gorm:"index" json:"-"`          // Fingerprint used to skip already imported rows
+}
+
+// TimePause represents an interval during which a time entry was paused
+// EndTime is NULL while the pause is still ongoing
+type TimePause struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TimeEntryID uint       `gorm:"not null;index" json:"time_entry_id"`
+	StartTime   time.Time  `gorm:"not null" json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// IsPaused reports whether the entry is currently paused
+func (e *TimeEntry) IsPaused() bool {
+	return e.PausedAt != nil
+}
+
+// DurationSeconds returns the tracked time of the entry excluding paused time
+// Running entries are measured up to now
+func (e *TimeEntry) DurationSeconds(now time.Time) int64 {
+	end := now
+	if e.EndTime != nil {
+		end = *e.EndTime
+	}
+
+	paused := e.PausedSeconds
+	if e.PausedAt != nil {
+		paused += utils.CalculateDuration(*e.PausedAt, end)
+	}
+
+	duration := utils.CalculateDuration(e.StartTime, end) - paused
+	if duration < 0 {
+		return 0
+	}
+	return duration
 }
@@ -8,13 +8,15 @@ import (
 )
 
 type User struct {
-	ID           uint           `gorm:"primarykey" json:"id"`
-	Name         string         `gorm:"type:varchar(100);not null" json:"name"`
-	Email        string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
-	PasswordHash string         `gorm:"type:varchar(255);not null" json:"-"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint   `gorm:"primarykey" json:"id"`
+	Name         string `gorm:"type:varchar(100);not null" json:"name"`
+	Email        string `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	PasswordHash string `gorm:"type:varchar(255);not null" json:"-"`
+	// RewardCatalog is the key of the catalog the user's rewards are drawn from
+	RewardCatalog string         `gorm:"type:varchar(64);not null;default:lol" json:"reward_catalog"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	Activities      []Activity        `gorm:"foreignKey:UserID" json:"-"`
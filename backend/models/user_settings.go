@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DateFormat is how dates are displayed to the user
+type DateFormat string
+
+const (
+	DateFormatISO DateFormat = "YYYY-MM-DD"
+	DateFormatDMY DateFormat = "DD/MM/YYYY"
+	DateFormatMDY DateFormat = "MM/DD/YYYY"
+)
+
+// IsValid reports whether the format is supported
+func (f DateFormat) IsValid() bool {
+	switch f {
+	case DateFormatISO, DateFormatDMY, DateFormatMDY:
+		return true
+	}
+	return false
+}
+
+// UserSettings holds a user's preferences for how days, weeks and dates are counted and shown
+// Users without a row use DefaultUserSettings
+type UserSettings struct {
+	UserID uint `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	// Timezone is the IANA name of the zone the user's days are counted in; empty uses the server's zone
+	Timezone string `gorm:"type:varchar(64);not null;default:''" json:"timezone"`
+	// WeekStart is the first day of the user's weeks, 0 is Sunday
+	// It has no gorm default, which would replace Sunday when inserting
+	WeekStart  time.Weekday `gorm:"not null" json:"week_start"`
+	DateFormat DateFormat   `gorm:"type:varchar(16);not null;default:'YYYY-MM-DD'" json:"date_format"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// DefaultUserSettings returns the settings of a user who never changed them
+func DefaultUserSettings(userID uint) UserSettings {
+	return UserSettings{UserID: userID, WeekStart: time.Monday, DateFormat: DateFormatISO}
+}
+
+// Location returns the user's time zone, or the server's zone if it is unset or unknown
+func (s *UserSettings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// Validate checks the settings can be stored
+func (s *UserSettings) Validate() error {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return errors.New("timezone must be an IANA time zone such as Europe/Berlin")
+		}
+	}
+	if s.WeekStart < time.Sunday || s.WeekStart > time.Saturday {
+		return errors.New("week_start must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if !s.DateFormat.IsValid() {
+		return errors.New("date_format must be YYYY-MM-DD, DD/MM/YYYY or MM/DD/YYYY")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+// ActivityRepository stores a user's activities and their tag links
+// Deleted activities are kept with deleted_at set and are never returned
+type ActivityRepository interface {
+	// List returns the user's activities with categories and tags
+	List(userID uint) ([]models.Activity, error)
+	// Find returns one of the user's activities without relationships, or gorm.ErrRecordNotFound
+	Find(userID, id uint) (*models.Activity, error)
+	// FindWithDetails is Find with categories, tags and project loaded
+	FindWithDetails(userID, id uint) (*models.Activity, error)
+	// Create stores the activity with its tags and reloads it with relationships
+	Create(activity *models.Activity, tags []models.Tag) error
+	// Update saves the activity and reloads it; a nil tags slice keeps the current tags
+	Update(activity *models.Activity, tags []models.Tag) error
+	// Delete soft deletes the activity so its tracked time stays in the history
+	Delete(activity *models.Activity) error
+}
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+func (r *activityRepository) List(userID uint) ([]models.Activity, error) {
+	var activities []models.Activity
+	err := r.withDetails(r.db).
+		Where("user_id = ? AND deleted_at IS NULL", userID).
+		Find(&activities).Error
+	return activities, err
+}
+
+func (r *activityRepository) Find(userID, id uint) (*models.Activity, error) {
+	var activity models.Activity
+	if err := r.db.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).First(&activity).Error; err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+func (r *activityRepository) FindWithDetails(userID, id uint) (*models.Activity, error) {
+	var activity models.Activity
+	err := r.withDetails(r.db).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).
+		First(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+func (r *activityRepository) Create(activity *models.Activity, tags []models.Tag) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(activity).Error; err != nil {
+			return err
+		}
+		if len(tags) > 0 {
+			return tx.Model(activity).Association("Tags").Append(&tags)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.reload(activity)
+}
+
+func (r *activityRepository) Update(activity *models.Activity, tags []models.Tag) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("MainCategory", "SubCategory", "Tags", "Project").Save(activity).Error; err != nil {
+			return err
+		}
+		if tags == nil {
+			return nil
+		}
+		return tx.Model(activity).Association("Tags").Replace(&tags)
+	})
+	if err != nil {
+		return err
+	}
+	return r.reload(activity)
+}
+
+func (r *activityRepository) Delete(activity *models.Activity) error {
+	now := time.Now()
+	if err := r.db.Model(activity).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	activity.DeletedAt = &now
+	return nil
+}
+
+// reload refreshes the activity with its categories, tags and project
+// It loads into a fresh value so relationships that were unset don't linger
+func (r *activityRepository) reload(activity *models.Activity) error {
+	var fresh models.Activity
+	if err := r.withDetails(r.db).First(&fresh, activity.ID).Error; err != nil {
+		return err
+	}
+	*activity = fresh
+	return nil
+}
+
+// withDetails preloads the relationships returned to clients
+func (r *activityRepository) withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("MainCategory").Preload("SubCategory").Preload("Tags").Preload("Project")
+}
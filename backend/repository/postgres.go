@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// postgresDialect measures durations with EXTRACT on the interval between two timestamps
+var postgresDialect = dialect{
+	durationSeconds: func(start, end string) string {
+		return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s))::INTEGER", end, start)
+	},
+}
+
+// NewPostgres returns the repositories for a PostgreSQL connection
+func NewPostgres(db *gorm.DB) *Repositories {
+	return newRepositories(db, postgresDialect)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Repositories bundles the data access injected into the handlers
+// DB is the same connection, for service functions that take a *gorm.DB
+type Repositories struct {
+	DB          *gorm.DB
+	Users       UserRepository
+	Activities  ActivityRepository
+	TimeEntries TimeEntryRepository
+	Rewards     RewardRepository
+	Catalogs    RewardCatalogRepository
+}
+
+// dialect holds the SQL that differs between database backends
+type dialect struct {
+	// durationSeconds returns an expression for the whole seconds between two timestamp columns
+	durationSeconds func(start, end string) string
+}
+
+// New picks the implementation matching the driver the connection was opened with
+func New(db *gorm.DB) (*Repositories, error) {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return NewPostgres(db), nil
+	case "sqlite":
+		return NewSQLite(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported database dialect %s", db.Dialector.Name())
+	}
+}
+
+// newRepositories wires every repository to the same connection and dialect
+func newRepositories(db *gorm.DB, d dialect) *Repositories {
+	return &Repositories{
+		DB:          db,
+		Users:       &userRepository{db: db},
+		Activities:  &activityRepository{db: db},
+		TimeEntries: &timeEntryRepository{db: db, dialect: d},
+		Rewards:     &rewardRepository{db: db},
+		Catalogs:    &rewardCatalogRepository{db: db},
+	}
+}
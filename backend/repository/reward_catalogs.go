@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+// RewardCatalogRepository stores the custom reward catalogs managed through the admin API
+type RewardCatalogRepository interface {
+	// List returns every stored catalog with its items
+	List() ([]models.RewardCatalogRecord, error)
+	// Save creates the catalog with catalog.Key, or replaces its name and items if it exists
+	Save(catalog *models.RewardCatalogRecord) error
+	// Delete removes the catalog and moves the users who selected it back to the default catalog
+	// Returns gorm.ErrRecordNotFound if there is no catalog with the key
+	Delete(key, defaultKey string) error
+}
+
+type rewardCatalogRepository struct {
+	db *gorm.DB
+}
+
+func (r *rewardCatalogRepository) List() ([]models.RewardCatalogRecord, error) {
+	var catalogs []models.RewardCatalogRecord
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Order("key").Find(&catalogs).Error
+	return catalogs, err
+}
+
+func (r *rewardCatalogRepository) Save(catalog *models.RewardCatalogRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.RewardCatalogRecord
+		err := tx.Where("key = ?", catalog.Key).First(&existing).Error
+		switch err {
+		case nil:
+			catalog.ID = existing.ID
+			catalog.CreatedAt = existing.CreatedAt
+			if err := tx.Where("catalog_id = ?", existing.ID).Delete(&models.RewardCatalogItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Omit("Items").Save(catalog).Error; err != nil {
+				return err
+			}
+		case gorm.ErrRecordNotFound:
+			if err := tx.Omit("Items").Create(catalog).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		for i := range catalog.Items {
+			catalog.Items[i].ID = 0
+			catalog.Items[i].CatalogID = catalog.ID
+		}
+		if len(catalog.Items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(catalog.Items, 500).Error
+	})
+}
+
+func (r *rewardCatalogRepository) Delete(key, defaultKey string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var catalog models.RewardCatalogRecord
+		if err := tx.Where("key = ?", key).First(&catalog).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("catalog_id = ?", catalog.ID).Delete(&models.RewardCatalogItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&catalog).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("reward_catalog = ?", key).Update("reward_catalog", defaultKey).Error
+	})
+}
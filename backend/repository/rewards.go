@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"gorm.io/gorm"
+)
+
+// RewardRepository stores claimed rewards and champion mastery
+type RewardRepository interface {
+	// Create stores a reward drop and adds a copy of it to the user's collection
+	Create(reward *models.UserReward) error
+	// List returns the user's reward drops, newest first
+	List(userID uint) ([]models.UserReward, error)
+	// ListOwned returns the user's collection, most recently obtained first
+	ListOwned(userID uint) ([]models.OwnedReward, error)
+	// ListMastery returns the user's champion mastery, highest first
+	ListMastery(userID uint) ([]models.ChampionMastery, error)
+	// FindPity returns the user's pity counter, a fresh one if they have never claimed a reward
+	FindPity(userID uint) (*models.RewardPity, error)
+}
+
+type rewardRepository struct {
+	db *gorm.DB
+}
+
+func (r *rewardRepository) Create(reward *models.UserReward) error {
+	return services.AddUserReward(r.db, reward)
+}
+
+func (r *rewardRepository) List(userID uint) ([]models.UserReward, error) {
+	var rewards []models.UserReward
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&rewards).Error
+	return rewards, err
+}
+
+func (r *rewardRepository) ListOwned(userID uint) ([]models.OwnedReward, error) {
+	return services.ListCollection(r.db, userID)
+}
+
+func (r *rewardRepository) ListMastery(userID uint) ([]models.ChampionMastery, error) {
+	var mastery []models.ChampionMastery
+	err := r.db.Where("user_id = ?", userID).Order("mastery_level DESC, times_obtained DESC").Find(&mastery).Error
+	return mastery, err
+}
+
+func (r *rewardRepository) FindPity(userID uint) (*models.RewardPity, error) {
+	return services.GetRewardPity(r.db, userID)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// sqliteDialect measures durations with julianday, which works on the text timestamps SQLite stores
+// The difference is in days, so it is scaled to seconds and rounded like the Postgres cast
+var sqliteDialect = dialect{
+	durationSeconds: func(start, end string) string {
+		return fmt.Sprintf("CAST(ROUND((julianday(%s) - julianday(%s)) * 86400) AS INTEGER)", end, start)
+	},
+}
+
+// NewSQLite returns the repositories for a SQLite connection
+func NewSQLite(db *gorm.DB) *Repositories {
+	return newRepositories(db, sqliteDialect)
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+// ActivityTotal is the tracked time of one activity within a date range
+type ActivityTotal struct {
+	ActivityID   uint
+	ActivityName string
+	TotalSeconds int64
+	EntryCount   int
+}
+
+// TimeEntryRepository stores time entries and computes tracked time in the database
+// Durations always exclude paused time and only count stopped entries
+type TimeEntryRepository interface {
+	// FindActive returns the user's running entry with its activity, or gorm.ErrRecordNotFound
+	FindActive(userID uint) (*models.TimeEntry, error)
+	// Find returns one of the user's entries without relationships, or gorm.ErrRecordNotFound
+	Find(userID, id uint) (*models.TimeEntry, error)
+	// ListByActivity returns an activity's entries, newest first
+	ListByActivity(activityID uint) ([]models.TimeEntry, error)
+	// LastStopped returns the activity's most recently stopped entry, or gorm.ErrRecordNotFound
+	LastStopped(activityID uint) (*models.TimeEntry, error)
+	Create(entry *models.TimeEntry) error
+	Save(entry *models.TimeEntry) error
+	// Delete removes the entry permanently
+	Delete(entry *models.TimeEntry) error
+	// ActivitySeconds returns the tracked seconds of an activity
+	ActivitySeconds(activityID uint) (int64, error)
+	// SecondsByActivity returns the tracked seconds of each of the user's activities that has time
+	SecondsByActivity(userID uint) (map[uint]int64, error)
+	// TopActivities returns the activities with the most time started within [from, to], at most limit
+	// Deleted activities are left out
+	TopActivities(userID uint, from, to time.Time, limit int) ([]ActivityTotal, error)
+	// TotalSeconds returns the time of all entries started within [from, to], skipping deleted activities
+	TotalSeconds(userID uint, from, to time.Time) (int64, error)
+	// WithTx returns the repository running its queries inside tx
+	WithTx(tx *gorm.DB) TimeEntryRepository
+}
+
+type timeEntryRepository struct {
+	db      *gorm.DB
+	dialect dialect
+}
+
+func (r *timeEntryRepository) FindActive(userID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := r.db.Preload("Activity").Where("user_id = ? AND end_time IS NULL", userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepository) Find(userID, id uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepository) ListByActivity(activityID uint) ([]models.TimeEntry, error) {
+	var entries []models.TimeEntry
+	err := r.db.Where("activity_id = ?", activityID).Order("start_time DESC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *timeEntryRepository) LastStopped(activityID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := r.db.Where("activity_id = ? AND end_time IS NOT NULL", activityID).
+		Order("end_time DESC").
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepository) Create(entry *models.TimeEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *timeEntryRepository) Save(entry *models.TimeEntry) error {
+	return r.db.Omit("Activity", "Pauses").Save(entry).Error
+}
+
+func (r *timeEntryRepository) Delete(entry *models.TimeEntry) error {
+	return r.db.Delete(entry).Error
+}
+
+func (r *timeEntryRepository) ActivitySeconds(activityID uint) (int64, error) {
+	var totalSeconds int64
+	err := r.db.Table("time_entries").
+		Select("COALESCE(SUM("+r.trackedSeconds()+"), 0)").
+		Where("activity_id = ? AND end_time IS NOT NULL", activityID).
+		Scan(&totalSeconds).Error
+	return totalSeconds, err
+}
+
+func (r *timeEntryRepository) SecondsByActivity(userID uint) (map[uint]int64, error) {
+	var rows []struct {
+		ActivityID   uint
+		TotalSeconds int64
+	}
+	err := r.db.Table("time_entries").
+		Select("activity_id, SUM("+r.trackedSeconds()+") AS total_seconds").
+		Where("user_id = ? AND end_time IS NOT NULL", userID).
+		Group("activity_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		totals[row.ActivityID] = row.TotalSeconds
+	}
+	return totals, nil
+}
+
+func (r *timeEntryRepository) TopActivities(userID uint, from, to time.Time, limit int) ([]ActivityTotal, error) {
+	var totals []ActivityTotal
+	err := r.inRange(userID, from, to).
+		Select(`
+			time_entries.activity_id,
+			activities.name as activity_name,
+			SUM(` + r.trackedSeconds() + `) as total_seconds,
+			COUNT(time_entries.id) as entry_count
+		`).
+		Group("time_entries.activity_id, activities.name").
+		Order("total_seconds DESC").
+		Limit(limit).
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *timeEntryRepository) TotalSeconds(userID uint, from, to time.Time) (int64, error) {
+	var total int64
+	err := r.inRange(userID, from, to).
+		Select("COALESCE(SUM(" + r.trackedSeconds() + "), 0) as total").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *timeEntryRepository) WithTx(tx *gorm.DB) TimeEntryRepository {
+	return &timeEntryRepository{db: tx, dialect: r.dialect}
+}
+
+// inRange selects the user's stopped entries started within [from, to] on activities that aren't deleted
+func (r *timeEntryRepository) inRange(userID uint, from, to time.Time) *gorm.DB {
+	return r.db.Table("time_entries").
+		Joins("JOIN activities ON activities.id = time_entries.activity_id").
+		Where("time_entries.user_id = ?", userID).
+		Where("time_entries.start_time >= ? AND time_entries.start_time <= ?", from, to).
+		Where("time_entries.end_time IS NOT NULL").
+		Where("activities.deleted_at IS NULL")
+}
+
+// trackedSeconds is the SQL for a stopped entry's duration minus its paused time
+func (r *timeEntryRepository) trackedSeconds() string {
+	return r.dialect.durationSeconds("time_entries.start_time", "time_entries.end_time") + " - time_entries.paused_seconds"
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
+	"gorm.io/gorm"
+)
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	// FindByID returns the user or gorm.ErrRecordNotFound
+	FindByID(id uint) (*models.User, error)
+	// FindByEmail returns the user with the normalized email or gorm.ErrRecordNotFound
+	FindByEmail(email string) (*models.User, error)
+	// Create stores a new user together with their own copy of the default categories
+	Create(user *models.User) error
+	// SetRewardCatalog changes the catalog the user's rewards are drawn from
+	SetRewardCatalog(userID uint, key string) error
+	// FindSettings returns the user's settings, the defaults if they never changed them
+	FindSettings(userID uint) (*models.UserSettings, error)
+	// SaveSettings stores the user's settings, which must have been validated
+	SaveSettings(settings *models.UserSettings) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func (r *userRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Create(user *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return services.CopyDefaultCategories(tx, user.ID)
+	})
+}
+
+func (r *userRepository) SetRewardCatalog(userID uint, key string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("reward_catalog", key).Error
+}
+
+func (r *userRepository) FindSettings(userID uint) (*models.UserSettings, error) {
+	return services.GetUserSettings(r.db, userID)
+}
+
+func (r *userRepository) SaveSettings(settings *models.UserSettings) error {
+	return services.SaveUserSettings(r.db, settings)
+}
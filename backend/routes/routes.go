@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/Felipalds/go-pomodoro/handlers"
 	"github.com/Felipalds/go-pomodoro/middleware"
+	"github.com/Felipalds/go-pomodoro/repository"
 	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -11,11 +12,13 @@ import (
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(logger *zap.Logger) *chi.Mux {
+// repos is the data access handed to the handlers that take it; catalogs are the reward catalogs users choose from;
+// eventHub carries the live updates handlers publish; mailer delivers password reset emails
+func SetupRoutes(logger *zap.Logger, repos *repository.Repositories, catalogs *services.RewardCatalogRegistry, eventHub *services.EventHub, mailer services.Mailer) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middleware
-	r.Use(chimiddleware.Logger)
+	r.Use(middleware.RequestLogger)
 	r.Use(chimiddleware.Recoverer)
 	r.Use(chimiddleware.RequestID)
 	r.Use(chimiddleware.RealIP)
@@ -29,40 +32,66 @@ func SetupRoutes(logger *zap.Logger) *chi.Mux {
 		MaxAge:           300,
 	}))
 
-	// Initialize Data Dragon service
-	ddService := services.GetDataDragonService()
-	if err := ddService.Initialize(); err != nil {
-		logger.Error("Failed to initialize Data Dragon service", zap.Error(err))
-	} else {
-		stats := ddService.GetStats()
-		logger.Info("Data Dragon service initialized",
-			zap.Int("champions", stats["champions"]),
-			zap.Int("items", stats["items"]),
-			zap.Int("skins", stats["skins"]),
-			zap.Int("icons", stats["icons"]),
-		)
-	}
-
 	// Initialize handlers
-	categoryHandler := &handlers.CategoryHandler{Logger: logger}
-	tagHandler := &handlers.TagHandler{Logger: logger}
-	activityHandler := &handlers.ActivityHandler{Logger: logger}
-	timeEntryHandler := &handlers.TimeEntryHandler{Logger: logger}
-	resumeHandler := &handlers.ResumeHandler{Logger: logger}
-	rewardHandler := &handlers.RewardHandler{Logger: logger, DDService: ddService}
+	authHandler := &handlers.AuthHandler{Logger: logger, Repos: repos}
+	categoryHandler := &handlers.CategoryHandler{Logger: logger, Repos: repos}
+	tagHandler := &handlers.TagHandler{Logger: logger, Repos: repos}
+	activityHandler := &handlers.ActivityHandler{Logger: logger, Events: eventHub, Repos: repos}
+	timeEntryHandler := &handlers.TimeEntryHandler{Logger: logger, Events: eventHub, Repos: repos}
+	pomodoroHandler := &handlers.PomodoroHandler{Logger: logger, Events: eventHub, Repos: repos}
+	exportHandler := &handlers.ExportHandler{Logger: logger, Repos: repos}
+	importHandler := &handlers.ImportHandler{Logger: logger, Repos: repos}
+	resumeHandler := &handlers.ResumeHandler{Logger: logger, Repos: repos}
+	reportHandler := &handlers.ReportHandler{Logger: logger, Repos: repos}
+	analyticsHandler := &handlers.AnalyticsHandler{Logger: logger, Repos: repos}
+	rewardHandler := &handlers.RewardHandler{Logger: logger, Catalogs: catalogs, Events: eventHub, Repos: repos}
+	achievementHandler := &handlers.AchievementHandler{Logger: logger, Events: eventHub, Repos: repos}
+	streakHandler := &handlers.StreakHandler{Logger: logger, Catalogs: catalogs, Events: eventHub, Repos: repos}
+	rewardCatalogHandler := &handlers.RewardCatalogHandler{Logger: logger, Catalogs: catalogs, Repos: repos}
+	clientHandler := &handlers.ClientHandler{Logger: logger, Repos: repos}
+	projectHandler := &handlers.ProjectHandler{Logger: logger, Repos: repos}
+	billingHandler := &handlers.BillingHandler{Logger: logger, Repos: repos}
+	goalHandler := &handlers.GoalHandler{Logger: logger, Events: eventHub, Repos: repos}
+	settingsHandler := &handlers.SettingsHandler{Logger: logger, Repos: repos}
+	eventHandler := &handlers.EventHandler{Logger: logger, Hub: eventHub, Tickets: services.NewStreamTicketStore(), Repos: repos}
+	apiTokenHandler := &handlers.APITokenHandler{Logger: logger, Repos: repos}
+	passwordHandler := &handlers.PasswordHandler{Logger: logger, Mailer: mailer, Repos: repos}
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		// Public routes (no auth required)
-		r.Post("/auth/register", handlers.Register)
-		r.Post("/auth/login", handlers.Login)
+		r.Post("/auth/register", authHandler.Register)
+		r.Post("/auth/login", authHandler.Login)
+		r.Post("/auth/refresh", authHandler.Refresh)
+		r.Post("/auth/forgot-password", passwordHandler.ForgotPassword)
+		r.Post("/auth/reset-password", passwordHandler.ResetPassword)
+
+		// Live updates; browsers open the stream with a ticket from /events/ticket since EventSource cannot set headers
+		r.With(middleware.StreamTicketAuth(eventHandler.Tickets)).Get("/events", eventHandler.StreamEvents)
 
 		// Protected routes (auth required)
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.AuthMiddleware)
 
 			// Auth
-			r.Get("/auth/me", handlers.GetMe)
+			r.Get("/auth/me", authHandler.GetMe)
+			r.Post("/auth/logout", authHandler.Logout)
+			r.Post("/auth/logout-all", authHandler.LogoutAll)
+			r.Post("/auth/password", passwordHandler.ChangePassword)
+
+			// Tickets for opening the live updates stream
+			r.Post("/events/ticket", eventHandler.CreateStreamTicket)
+
+			// Settings
+			r.Get("/settings", settingsHandler.GetSettings)
+			r.Put("/settings", settingsHandler.UpdateSettings)
+
+			// Personal API tokens
+			r.Route("/auth/tokens", func(r chi.Router) {
+				r.Get("/", apiTokenHandler.GetAPITokens)
+				r.Post("/", apiTokenHandler.CreateAPIToken)
+				r.Delete("/{id}", apiTokenHandler.RevokeAPIToken)
+			})
 
 			// Categories
 			r.Route("/categories", func(r chi.Router) {
@@ -89,24 +118,96 @@ func SetupRoutes(logger *zap.Logger) *chi.Mux {
 				r.Put("/{id}", activityHandler.UpdateActivity)
 				r.Delete("/{id}", activityHandler.DeleteActivity)
 				r.Get("/{id}/time", activityHandler.GetActivityTime)
+				r.Put("/{id}/billing", activityHandler.UpdateActivityBilling)
 			})
 
 			// Time Entries
 			r.Route("/time-entries", func(r chi.Router) {
+				r.Post("/", timeEntryHandler.CreateTimeEntry)
 				r.Post("/start", timeEntryHandler.StartTimer)
 				r.Post("/stop", timeEntryHandler.StopTimer)
+				r.Post("/pause", timeEntryHandler.PauseTimer)
+				r.Post("/resume", timeEntryHandler.ResumeTimer)
 				r.Get("/active", timeEntryHandler.GetActiveTimer)
+				r.Get("/export", exportHandler.ExportTimeEntries)
+				r.Post("/import", importHandler.ImportTimeEntries)
+				r.Put("/{id}", timeEntryHandler.UpdateTimeEntry)
 				r.Delete("/{id}", timeEntryHandler.DeleteTimeEntry)
 			})
 
+			// Pomodoro
+			r.Route("/pomodoro", func(r chi.Router) {
+				r.Get("/", pomodoroHandler.GetPomodoro)
+				r.Post("/start", pomodoroHandler.StartPomodoro)
+				r.Post("/pause", pomodoroHandler.PausePomodoro)
+				r.Post("/resume", pomodoroHandler.ResumePomodoro)
+				r.Post("/skip", pomodoroHandler.SkipPomodoro)
+				r.Post("/abort", pomodoroHandler.AbortPomodoro)
+			})
+
 			// Resume
 			r.Get("/resume", resumeHandler.GetResume)
 
+			// Reports
+			r.Get("/reports", reportHandler.GetReport)
+			r.Get("/analytics", analyticsHandler.GetAnalytics)
+
+			// Clients and projects for billing
+			r.Route("/clients", func(r chi.Router) {
+				r.Get("/", clientHandler.GetClients)
+				r.Post("/", clientHandler.CreateClient)
+				r.Put("/{id}", clientHandler.UpdateClient)
+				r.Delete("/{id}", clientHandler.DeleteClient)
+			})
+			r.Route("/projects", func(r chi.Router) {
+				r.Get("/", projectHandler.GetProjects)
+				r.Post("/", projectHandler.CreateProject)
+				r.Put("/{id}", projectHandler.UpdateProject)
+				r.Delete("/{id}", projectHandler.DeleteProject)
+			})
+			r.Get("/billing/summary", billingHandler.GetBillingSummary)
+
+			// Goals
+			r.Route("/goals", func(r chi.Router) {
+				r.Get("/", goalHandler.GetGoals)
+				r.Post("/", goalHandler.CreateGoal)
+				r.Get("/status", goalHandler.GetGoalStatus)
+				r.Put("/{id}", goalHandler.UpdateGoal)
+				r.Delete("/{id}", goalHandler.DeleteGoal)
+			})
+
 			// Rewards
 			r.Route("/rewards", func(r chi.Router) {
 				r.Get("/", rewardHandler.GetRewards)
 				r.Get("/status", rewardHandler.GetRewardStatus)
+				r.Get("/collection", rewardHandler.GetCollection)
 				r.Post("/claim", rewardHandler.ClaimReward)
+				r.Post("/claim-all", rewardHandler.ClaimAllRewards)
+				r.Get("/essence", rewardHandler.GetEssence)
+				r.Post("/craft", rewardHandler.CraftReward)
+				r.Post("/{id}/disenchant", rewardHandler.DisenchantReward)
+				r.Get("/catalogs", rewardCatalogHandler.GetCatalogs)
+				r.Get("/catalogs/{key}", rewardCatalogHandler.GetCatalog)
+				r.Put("/catalog", rewardCatalogHandler.SelectCatalog)
+			})
+
+			// Achievements
+			r.Get("/achievements", achievementHandler.GetAchievements)
+
+			// Streaks
+			r.Route("/streaks", func(r chi.Router) {
+				r.Get("/", streakHandler.GetStreak)
+				r.Put("/settings", streakHandler.UpdateStreakSettings)
+				r.Post("/freeze", streakHandler.FreezeStreakDay)
+				r.Post("/claim", streakHandler.ClaimStreakBonus)
+			})
+
+			// Admin (users listed in ADMIN_EMAILS)
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(middleware.RequireAdmin(repos.Users))
+
+				r.Put("/reward-catalogs/{key}", rewardCatalogHandler.SaveCatalog)
+				r.Delete("/reward-catalogs/{key}", rewardCatalogHandler.DeleteCatalog)
 			})
 		})
 	})
@@ -3,11 +3,11 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/Felipalds/go-pomodoro/database"
 	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/services"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
@@ -69,37 +69,27 @@ func main() {
 	}
 	fmt.Printf("✓ User found: %s (%s)\n", user.Name, user.Email)
 
-	// Find or create main category
-	var mainCategory models.Category
-	if err := database.DB.Where("LOWER(name) = LOWER(?)", mainCategoryName).First(&mainCategory).Error; err != nil {
-		mainCategory = models.Category{Name: mainCategoryName}
-		if err := database.DB.Create(&mainCategory).Error; err != nil {
-			log.Fatal("Failed to create main category:", err)
-		}
-		fmt.Printf("✓ Created category: %s\n", mainCategoryName)
-	} else {
-		fmt.Printf("✓ Found existing category: %s\n", mainCategoryName)
+	// Find or create main category (categories are owned per user)
+	mainCategory, err := services.FindOrCreateCategory(database.DB, userID, mainCategoryName)
+	if err != nil {
+		log.Fatal("Failed to find/create main category:", err)
 	}
+	fmt.Printf("✓ Using category: %s (ID: %d)\n", mainCategory.Name, mainCategory.ID)
 
 	// Find or create subcategory (if specified)
 	var subCategoryID *uint
 	if subCategoryName != "" {
-		var subCategory models.Category
-		if err := database.DB.Where("LOWER(name) = LOWER(?)", subCategoryName).First(&subCategory).Error; err != nil {
-			subCategory = models.Category{Name: subCategoryName}
-			if err := database.DB.Create(&subCategory).Error; err != nil {
-				log.Fatal("Failed to create subcategory:", err)
-			}
-			fmt.Printf("✓ Created subcategory: %s\n", subCategoryName)
-		} else {
-			fmt.Printf("✓ Found existing subcategory: %s\n", subCategoryName)
+		subCategory, err := services.FindOrCreateCategory(database.DB, userID, subCategoryName)
+		if err != nil {
+			log.Fatal("Failed to find/create subcategory:", err)
 		}
+		fmt.Printf("✓ Using subcategory: %s (ID: %d)\n", subCategory.Name, subCategory.ID)
 		subCategoryID = &subCategory.ID
 	}
 
 	// Find or create activity
 	var activity models.Activity
-	err := database.DB.Where("name = ? AND user_id = ? AND deleted_at IS NULL", activityName, userID).First(&activity).Error
+	err = database.DB.Where("name = ? AND user_id = ? AND deleted_at IS NULL", activityName, userID).First(&activity).Error
 	if err != nil {
 		// Create new activity
 		activity = models.Activity{
@@ -126,6 +116,7 @@ func main() {
 		ActivityID: activity.ID,
 		StartTime:  startTime,
 		EndTime:    &endTime,
+		Billable:   true,
 	}
 
 	if err := database.DB.Create(&timeEntry).Error; err != nil {
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementMetric is a number derived from a user's tracking history that achievements are measured by
+type AchievementMetric string
+
+const (
+	MetricTrackedHours      AchievementMetric = "tracked_hours"      // Whole hours tracked in total
+	MetricLongestDayStreak  AchievementMetric = "longest_day_streak" // Most consecutive days with tracked time
+	MetricPomodorosInDay    AchievementMetric = "pomodoros_in_day"   // Most completed pomodoros on a single day
+	MetricCategoriesTracked AchievementMetric = "categories_tracked" // Main categories with tracked time
+	MetricMaxMastery        AchievementMetric = "max_mastery"        // Highest mastery level of any champion
+)
+
+// AchievementDefinition declares an achievement unlocked once Metric reaches Target
+type AchievementDefinition struct {
+	Key         string            `json:"key"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Metric      AchievementMetric `json:"metric"`
+	Target      int               `json:"target"`
+}
+
+// Achievements lists every achievement, in display order
+// Keys are stored with unlocked achievements and must not change
+var Achievements = []AchievementDefinition{
+	{Key: "first_hour", Name: "Getting Started", Description: "Track your first hour", Metric: MetricTrackedHours, Target: 1},
+	{Key: "hours_100", Name: "Centurion", Description: "Track 100 hours", Metric: MetricTrackedHours, Target: 100},
+	{Key: "hours_1000", Name: "Millennium", Description: "Track 1000 hours", Metric: MetricTrackedHours, Target: 1000},
+	{Key: "streak_7", Name: "Week Warrior", Description: "Track time 7 days in a row", Metric: MetricLongestDayStreak, Target: 7},
+	{Key: "streak_30", Name: "Habit Formed", Description: "Track time 30 days in a row", Metric: MetricLongestDayStreak, Target: 30},
+	{Key: "pomodoros_10", Name: "Tomato Marathon", Description: "Complete 10 pomodoros in a single day", Metric: MetricPomodorosInDay, Target: 10},
+	{Key: "categories_5", Name: "Renaissance", Description: "Track time in 5 different categories", Metric: MetricCategoriesTracked, Target: 5},
+	{Key: "mastery_7", Name: "Mastery Seven", Description: "Reach mastery 7 on a champion", Metric: MetricMaxMastery, Target: 7},
+}
+
+// AchievementProgress is an achievement together with a user's progress towards it
+type AchievementProgress struct {
+	AchievementDefinition
+	Current    int        `json:"current"` // Metric value, capped at Target
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// achievementEntry is the part of a stopped time entry the metrics are computed from
+type achievementEntry struct {
+	StartTime         time.Time
+	EndTime           time.Time
+	PausedSeconds     int64
+	PomodoroCompleted bool
+	MainCategoryID    uint
+}
+
+// ComputeAchievementMetrics derives every achievement metric from the user's history
+// Days are calendar days in the user's time zone
+func ComputeAchievementMetrics(db *gorm.DB, userID uint) (map[AchievementMetric]int, error) {
+	settings, err := GetUserSettings(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := settings.Location()
+
+	var entries []achievementEntry
+	err = db.Model(&models.TimeEntry{}).
+		Select("time_entries.start_time, time_entries.end_time, time_entries.paused_seconds, time_entries.pomodoro_completed, activities.main_category_id").
+		Joins("JOIN activities ON activities.id = time_entries.activity_id").
+		Where("time_entries.user_id = ? AND time_entries.end_time IS NOT NULL", userID).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var maxMastery int
+	err = db.Model(&models.ChampionMastery{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(MAX(mastery_level), 0)").
+		Scan(&maxMastery).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var totalSeconds int64
+	days := make(map[time.Time]bool)
+	pomodorosByDay := make(map[time.Time]int)
+	categories := make(map[uint]bool)
+
+	for _, entry := range entries {
+		seconds := int64(entry.EndTime.Sub(entry.StartTime).Seconds()) - entry.PausedSeconds
+		if seconds <= 0 {
+			continue
+		}
+		totalSeconds += seconds
+
+		start := entry.StartTime.In(loc)
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		days[day] = true
+		if entry.PomodoroCompleted {
+			pomodorosByDay[day]++
+		}
+		categories[entry.MainCategoryID] = true
+	}
+
+	maxPomodoros := 0
+	for _, count := range pomodorosByDay {
+		maxPomodoros = max(maxPomodoros, count)
+	}
+
+	return map[AchievementMetric]int{
+		MetricTrackedHours:      int(totalSeconds / 3600),
+		MetricLongestDayStreak:  longestDayStreak(days),
+		MetricPomodorosInDay:    maxPomodoros,
+		MetricCategoriesTracked: len(categories),
+		MetricMaxMastery:        maxMastery,
+	}, nil
+}
+
+// longestDayStreak returns the most consecutive calendar days in the set
+func longestDayStreak(days map[time.Time]bool) int {
+	sorted := make([]time.Time, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	longest, current := 0, 0
+	for i, day := range sorted {
+		// AddDate rather than 24h so days around DST changes still count as consecutive
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(day) {
+			current++
+		} else {
+			current = 1
+		}
+		longest = max(longest, current)
+	}
+	return longest
+}
+
+// EvaluateAchievements unlocks every achievement the user has reached and returns the newly unlocked ones
+func EvaluateAchievements(db *gorm.DB, userID uint) ([]AchievementDefinition, error) {
+	metrics, err := ComputeAchievementMetrics(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked, err := unlockedAchievements(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var newlyUnlocked []AchievementDefinition
+	for _, achievement := range Achievements {
+		if _, ok := unlocked[achievement.Key]; ok || metrics[achievement.Metric] < achievement.Target {
+			continue
+		}
+
+		// A concurrent evaluation may unlock it first; only the one that inserts reports it
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserAchievement{
+			UserID:         userID,
+			AchievementKey: achievement.Key,
+			UnlockedAt:     now,
+		})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			newlyUnlocked = append(newlyUnlocked, achievement)
+		}
+	}
+
+	return newlyUnlocked, nil
+}
+
+// GetAchievementProgress returns every achievement with the user's progress towards it
+func GetAchievementProgress(db *gorm.DB, userID uint) ([]AchievementProgress, error) {
+	metrics, err := ComputeAchievementMetrics(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked, err := unlockedAchievements(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]AchievementProgress, 0, len(Achievements))
+	for _, achievement := range Achievements {
+		item := AchievementProgress{
+			AchievementDefinition: achievement,
+			Current:               min(metrics[achievement.Metric], achievement.Target),
+		}
+		if unlockedAt, ok := unlocked[achievement.Key]; ok {
+			item.Unlocked = true
+			item.UnlockedAt = &unlockedAt
+			item.Current = achievement.Target
+		}
+		progress = append(progress, item)
+	}
+
+	return progress, nil
+}
+
+// unlockedAchievements returns when the user unlocked each of their achievements, by key
+func unlockedAchievements(db *gorm.DB, userID uint) (map[string]time.Time, error) {
+	var records []models.UserAchievement
+	if err := db.Where("user_id = ?", userID).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	unlocked := make(map[string]time.Time, len(records))
+	for _, record := range records {
+		unlocked[record.AchievementKey] = record.UnlockedAt
+	}
+	return unlocked, nil
+}
@@ -7,8 +7,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// FindOrCreateCategory finds a category by name (case-insensitive) or creates it
-func FindOrCreateCategory(db *gorm.DB, name string) (*models.Category, error) {
+// FindOrCreateCategory finds a user's category by name (case-insensitive) or creates it
+func FindOrCreateCategory(db *gorm.DB, userID uint, name string) (*models.Category, error) {
 	// Trim whitespace
 	name = strings.TrimSpace(name)
 	if name == "" {
@@ -18,7 +18,7 @@ func FindOrCreateCategory(db *gorm.DB, name string) (*models.Category, error) {
 	var category models.Category
 
 	// Try to find existing category (case-insensitive, not deleted)
-	err := db.Where("LOWER(name) = LOWER(?) AND deleted_at IS NULL", name).First(&category).Error
+	err := db.Where("user_id = ? AND LOWER(name) = LOWER(?) AND deleted_at IS NULL", userID, name).First(&category).Error
 
 	if err == nil {
 		// Found existing category
@@ -27,7 +27,7 @@ func FindOrCreateCategory(db *gorm.DB, name string) (*models.Category, error) {
 
 	if err == gorm.ErrRecordNotFound {
 		// Category doesn't exist, create it
-		category = models.Category{Name: name}
+		category = models.Category{UserID: userID, Name: name}
 		if err := db.Create(&category).Error; err != nil {
 			return nil, err
 		}
@@ -38,8 +38,8 @@ func FindOrCreateCategory(db *gorm.DB, name string) (*models.Category, error) {
 	return nil, err
 }
 
-// FindOrCreateTag finds a tag by name (case-insensitive) or creates it
-func FindOrCreateTag(db *gorm.DB, name string) (*models.Tag, error) {
+// FindOrCreateTag finds a user's tag by name (case-insensitive) or creates it
+func FindOrCreateTag(db *gorm.DB, userID uint, name string) (*models.Tag, error) {
 	// Trim whitespace
 	name = strings.TrimSpace(name)
 	if name == "" {
@@ -49,7 +49,7 @@ func FindOrCreateTag(db *gorm.DB, name string) (*models.Tag, error) {
 	var tag models.Tag
 
 	// Try to find existing tag (case-insensitive, not deleted)
-	err := db.Where("LOWER(name) = LOWER(?) AND deleted_at IS NULL", name).First(&tag).Error
+	err := db.Where("user_id = ? AND LOWER(name) = LOWER(?) AND deleted_at IS NULL", userID, name).First(&tag).Error
 
 	if err == nil {
 		// Found existing tag
@@ -58,7 +58,7 @@ func FindOrCreateTag(db *gorm.DB, name string) (*models.Tag, error) {
 
 	if err == gorm.ErrRecordNotFound {
 		// Tag doesn't exist, create it
-		tag = models.Tag{Name: name}
+		tag = models.Tag{UserID: userID, Name: name}
 		if err := db.Create(&tag).Error; err != nil {
 			return nil, err
 		}
@@ -68,3 +68,20 @@ func FindOrCreateTag(db *gorm.DB, name string) (*models.Tag, error) {
 	// Other error occurred
 	return nil, err
 }
+
+// CopyDefaultCategories gives a user their own copy of the default categories
+// Defaults are the seeded rows that belong to no user (UserID 0)
+func CopyDefaultCategories(db *gorm.DB, userID uint) error {
+	var defaults []models.Category
+	if err := db.Where("user_id = 0 AND deleted_at IS NULL").Find(&defaults).Error; err != nil {
+		return err
+	}
+
+	for _, category := range defaults {
+		if _, err := FindOrCreateCategory(db, userID, category.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
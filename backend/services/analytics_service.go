@@ -0,0 +1,166 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"gorm.io/gorm"
+)
+
+// Analytics shows when the user tracks time within a date range
+type Analytics struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Timezone string    `json:"timezone"`
+	// Heatmap holds the tracked seconds by weekday (0 is Sunday) and hour of the day
+	Heatmap      [7][24]int64 `json:"heatmap"`
+	Weekdays     []string     `json:"weekdays"`   // Names of the heatmap rows, in order
+	WeekStart    time.Weekday `json:"week_start"` // Row the user's week begins with, for display
+	TotalSeconds int64        `json:"total_seconds"`
+	TotalTime    string       `json:"total_time"`
+
+	SessionCount          int               `json:"session_count"`
+	AverageSessionSeconds int64             `json:"average_session_seconds"`
+	LongestSession        *AnalyticsSession `json:"longest_session"`
+
+	ActiveDays         int   `json:"active_days"`          // Days with tracked time
+	MedianDailySeconds int64 `json:"median_daily_seconds"` // Median total of the active days
+}
+
+// AnalyticsSession identifies a single time entry
+type AnalyticsSession struct {
+	EntryID      uint      `json:"entry_id"`
+	ActivityID   uint      `json:"activity_id"`
+	ActivityName string    `json:"activity_name"`
+	StartTime    time.Time `json:"start_time"`
+	Seconds      int64     `json:"seconds"`
+}
+
+// BuildAnalytics aggregates the user's completed entries that overlap [From, To)
+// The heatmap and daily totals only count the part of an entry inside the range, split at
+// every hour in filter.Location; session lengths use the whole entry
+// Entries only store their total paused time, so it is spread evenly over the entry
+func BuildAnalytics(db *gorm.DB, userID uint, filter ReportFilter) (*Analytics, error) {
+	if filter.Location == nil {
+		filter.Location = time.Local
+	}
+
+	query := db.
+		Preload("Activity").
+		Joins("JOIN activities ON activities.id = time_entries.activity_id").
+		Where("time_entries.user_id = ?", userID).
+		Where("time_entries.start_time < ? AND time_entries.end_time > ?", filter.To, filter.From).
+		Where("activities.deleted_at IS NULL")
+	query = applyEntryFilters(query, filter)
+
+	var entries []models.TimeEntry
+	if err := query.Order("time_entries.start_time ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	analytics := Analytics{
+		From:      filter.From,
+		To:        filter.To,
+		Timezone:  filter.Location.String(),
+		WeekStart: filter.WeekStart,
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		analytics.Weekdays = append(analytics.Weekdays, day.String())
+	}
+
+	daily := make(map[string]int64)
+	var sessionSeconds int64
+
+	for _, entry := range entries {
+		seconds := entry.DurationSeconds(*entry.EndTime)
+		if seconds <= 0 {
+			continue
+		}
+
+		analytics.SessionCount++
+		sessionSeconds += seconds
+		if analytics.LongestSession == nil || seconds > analytics.LongestSession.Seconds {
+			analytics.LongestSession = &AnalyticsSession{
+				EntryID:      entry.ID,
+				ActivityID:   entry.ActivityID,
+				ActivityName: entry.Activity.Name,
+				StartTime:    entry.StartTime,
+				Seconds:      seconds,
+			}
+		}
+
+		wall := entry.EndTime.Sub(entry.StartTime)
+		start := maxTime(entry.StartTime, filter.From)
+		end := minTime(*entry.EndTime, filter.To)
+
+		for cursor := start; cursor.Before(end); {
+			local := cursor.In(filter.Location)
+			next := minTime(nextLocalHour(cursor, filter.Location), end)
+
+			// Scale wall time down to tracked time, since pauses are not stored per hour
+			tracked := int64(float64(seconds) * float64(next.Sub(cursor)) / float64(wall))
+			analytics.Heatmap[local.Weekday()][local.Hour()] += tracked
+			daily[local.Format("2006-01-02")] += tracked
+			analytics.TotalSeconds += tracked
+
+			cursor = next
+		}
+	}
+
+	analytics.TotalTime = utils.FormatDuration(analytics.TotalSeconds)
+	if analytics.SessionCount > 0 {
+		analytics.AverageSessionSeconds = sessionSeconds / int64(analytics.SessionCount)
+	}
+
+	totals := make([]int64, 0, len(daily))
+	for _, total := range daily {
+		if total > 0 {
+			totals = append(totals, total)
+		}
+	}
+	analytics.ActiveDays = len(totals)
+	analytics.MedianDailySeconds = median(totals)
+
+	return &analytics, nil
+}
+
+// nextLocalHour returns the start of the hour after t on the clock of loc
+// It works for zones offset by a fraction of an hour and across DST changes
+func nextLocalHour(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	intoHour := time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second +
+		time.Duration(local.Nanosecond())
+	return t.Add(time.Hour - intoHour)
+}
+
+// median returns the middle value, or the mean of the two middle ones; 0 for no values
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// minTime returns the earlier of two times
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// maxTime returns the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAPIToken   = errors.New("invalid api token")
+	ErrInvalidTokenScope = errors.New("invalid token scope")
+)
+
+// APITokenPrefix marks bearer tokens that are personal API tokens rather than JWTs
+const APITokenPrefix = "tt_"
+
+// apiTokenLastUsedInterval limits how often last_used_at is written for a busy token
+const apiTokenLastUsedInterval = time.Minute
+
+// IsAPIToken reports whether a bearer token is a personal API token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// CreateAPIToken creates a named token with the given scopes and optional expiry
+// The plain token is only returned here; afterwards only its hash is known
+func CreateAPIToken(db *gorm.DB, userID uint, name string, scopes []models.TokenScope, expiresAt *time.Time) (*models.APIToken, string, error) {
+	scopeNames, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, _, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := APITokenPrefix + secret
+
+	apiToken := models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		Prefix:    token[:len(APITokenPrefix)+6],
+		Scopes:    strings.Join(scopeNames, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := db.Create(&apiToken).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &apiToken, token, nil
+}
+
+// ListAPITokens returns the user's tokens that have not been revoked, newest first
+func ListAPITokens(db *gorm.DB, userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeAPIToken revokes one of the user's tokens
+// Returns gorm.ErrRecordNotFound if the user has no such active token
+func RevokeAPIToken(db *gorm.DB, userID, tokenID uint) error {
+	result := db.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeAllAPITokens revokes every token of the user and returns how many were active
+func RevokeAllAPITokens(db *gorm.DB, userID uint) (int64, error) {
+	result := db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// AuthenticateAPIToken looks up an active token by its plain value and records its use
+func AuthenticateAPIToken(db *gorm.DB, token string) (*models.APIToken, error) {
+	now := time.Now()
+
+	var apiToken models.APIToken
+	if err := db.Where("token_hash = ?", hashToken(token)).First(&apiToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	if !apiToken.IsActive(now) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) > apiTokenLastUsedInterval {
+		if err := db.Model(&apiToken).Update("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &apiToken, nil
+}
+
+// IsAPITokenActive reports whether one of the user's tokens is neither revoked nor expired
+func IsAPITokenActive(db *gorm.DB, userID, tokenID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", tokenID, userID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// normalizeScopes validates scopes and removes duplicates, keeping ValidTokenScopes order
+func normalizeScopes(scopes []models.TokenScope) ([]string, error) {
+	requested := make(map[models.TokenScope]bool, len(scopes))
+	for _, scope := range scopes {
+		valid := false
+		for _, known := range models.ValidTokenScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, ErrInvalidTokenScope
+		}
+		requested[scope] = true
+	}
+
+	if len(requested) == 0 {
+		return nil, ErrInvalidTokenScope
+	}
+
+	names := make([]string, 0, len(requested))
+	for _, scope := range models.ValidTokenScopes {
+		if requested[scope] {
+			names = append(names, string(scope))
+		}
+	}
+	return names, nil
+}
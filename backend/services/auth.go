@@ -13,10 +13,14 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// AccessTokenTTL is how long an access token is valid; clients renew it with their refresh token
+const AccessTokenTTL = 15 * time.Minute
+
 // Claims represents the JWT claims
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID uint   `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -30,13 +34,14 @@ func getJWTSecret() []byte {
 	return []byte(secret)
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(userID uint, email string) (string, error) {
-	expirationTime := time.Now().Add(7 * 24 * time.Hour) // 7 days
+// GenerateToken creates a short-lived access token bound to a session
+func GenerateToken(userID uint, email string, sessionID uint) (string, time.Time, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL)
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -45,7 +50,11 @@ func GenerateToken(userID uint, email string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(getJWTSecret())
+	signed, err := token.SignedString(getJWTSecret())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expirationTime, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -66,7 +75,8 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	if !token.Valid {
+	// Tokens issued before sessions existed cannot be revoked, so they are no longer accepted
+	if !token.Valid || claims.SessionID == 0 {
 		return nil, ErrInvalidToken
 	}
 
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"gorm.io/gorm"
+)
+
+// BillingRate is the hourly rate an activity's time is billed at
+type BillingRate struct {
+	HourlyRateCents int64  `json:"hourly_rate_cents"`
+	Currency        string `json:"currency"`
+	Source          string `json:"source"` // activity, project or client: the most specific one with a rate
+}
+
+// BillingAmount is money owed in one currency
+type BillingAmount struct {
+	Currency    string `json:"currency"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// BillingLine is the billable time of one activity
+type BillingLine struct {
+	ActivityID     uint         `json:"activity_id"`
+	ActivityName   string       `json:"activity_name"`
+	EntryCount     int          `json:"entry_count"`
+	TrackedSeconds int64        `json:"tracked_seconds"`
+	BilledSeconds  int64        `json:"billed_seconds"` // Tracked time after each entry is rounded
+	BilledTime     string       `json:"billed_time"`
+	Rate           *BillingRate `json:"rate"` // nil when no level sets a rate, so the line is not charged
+	AmountCents    int64        `json:"amount_cents"`
+}
+
+// ProjectBilling is the billable time of one project
+type ProjectBilling struct {
+	ProjectID      uint            `json:"project_id"`
+	Name           string          `json:"name"`
+	TrackedSeconds int64           `json:"tracked_seconds"`
+	BilledSeconds  int64           `json:"billed_seconds"`
+	BilledTime     string          `json:"billed_time"`
+	Amounts        []BillingAmount `json:"amounts"`
+	Lines          []BillingLine   `json:"lines"`
+}
+
+// ClientBilling is what one client owes
+type ClientBilling struct {
+	ClientID        uint                `json:"client_id"`
+	Name            string              `json:"name"`
+	RoundingMinutes int                 `json:"rounding_minutes"`
+	RoundingMode    models.RoundingMode `json:"rounding_mode"`
+	TrackedSeconds  int64               `json:"tracked_seconds"`
+	BilledSeconds   int64               `json:"billed_seconds"`
+	BilledTime      string              `json:"billed_time"`
+	Amounts         []BillingAmount     `json:"amounts"`
+	Projects        []ProjectBilling    `json:"projects"`
+}
+
+// BillingSummary is the result of BuildBillingSummary
+type BillingSummary struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Timezone string          `json:"timezone"`
+	Amounts  []BillingAmount `json:"amounts"` // Totals of every client, by currency
+	Clients  []ClientBilling `json:"clients"`
+}
+
+// ResolveBillingRate returns the rate of the most specific of activity, project and client that sets one
+// A level without its own currency bills in its project's or client's
+func ResolveBillingRate(client *models.Client, project *models.Project, activity *models.Activity) *BillingRate {
+	currency := client.Currency
+	if project.Currency != "" {
+		currency = project.Currency
+	}
+
+	switch {
+	case activity.HourlyRateCents != nil:
+		if activity.Currency != "" {
+			currency = activity.Currency
+		}
+		return &BillingRate{HourlyRateCents: *activity.HourlyRateCents, Currency: currency, Source: "activity"}
+	case project.HourlyRateCents != nil:
+		return &BillingRate{HourlyRateCents: *project.HourlyRateCents, Currency: currency, Source: "project"}
+	case client.HourlyRateCents != nil:
+		return &BillingRate{HourlyRateCents: *client.HourlyRateCents, Currency: client.Currency, Source: "client"}
+	}
+	return nil
+}
+
+// RoundBilledSeconds rounds tracked seconds to the client's rounding interval
+// An interval of 0 keeps the exact time, and entries with no tracked time are never rounded up
+func RoundBilledSeconds(seconds int64, minutes int, mode models.RoundingMode) int64 {
+	interval := int64(minutes) * 60
+	if interval <= 0 || seconds <= 0 {
+		return seconds
+	}
+
+	switch mode {
+	case models.RoundDown:
+		return seconds / interval * interval
+	case models.RoundNearest:
+		return (seconds + interval/2) / interval * interval
+	default:
+		return (seconds + interval - 1) / interval * interval
+	}
+}
+
+// BuildBillingSummary totals the user's billable entries by client, project and activity
+// Entries are included when they are completed, billable, start in [From, To) and their activity
+// belongs to a project that, like its client, was not deleted
+// Each entry is rounded by its client's rules before the lines are priced
+func BuildBillingSummary(db *gorm.DB, userID uint, filter ReportFilter, clientIDs []uint) (*BillingSummary, error) {
+	if filter.Location == nil {
+		filter.Location = time.Local
+	}
+
+	query := db.
+		Preload("Activity.Project.Client").
+		Joins("JOIN activities ON activities.id = time_entries.activity_id").
+		Joins("JOIN projects ON projects.id = activities.project_id").
+		Joins("JOIN clients ON clients.id = projects.client_id").
+		Where("time_entries.user_id = ?", userID).
+		Where("time_entries.start_time >= ? AND time_entries.start_time < ?", filter.From, filter.To).
+		Where("time_entries.end_time IS NOT NULL AND time_entries.billable = ?", true).
+		Where("activities.deleted_at IS NULL AND projects.deleted_at IS NULL AND clients.deleted_at IS NULL")
+	if len(clientIDs) > 0 {
+		query = query.Where("clients.id IN ?", clientIDs)
+	}
+	query = applyEntryFilters(query, filter)
+
+	var entries []models.TimeEntry
+	if err := query.Order("time_entries.start_time ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	type projectTotals struct {
+		billing ProjectBilling
+		lines   map[uint]*BillingLine
+	}
+	type clientTotals struct {
+		billing  ClientBilling
+		projects map[uint]*projectTotals
+	}
+	clients := make(map[uint]*clientTotals)
+
+	for _, entry := range entries {
+		activity := &entry.Activity
+		project := activity.Project
+		client := project.Client
+
+		c, ok := clients[client.ID]
+		if !ok {
+			c = &clientTotals{
+				billing: ClientBilling{
+					ClientID:        client.ID,
+					Name:            client.Name,
+					RoundingMinutes: client.RoundingMinutes,
+					RoundingMode:    client.RoundingMode,
+				},
+				projects: make(map[uint]*projectTotals),
+			}
+			clients[client.ID] = c
+		}
+
+		p, ok := c.projects[project.ID]
+		if !ok {
+			p = &projectTotals{
+				billing: ProjectBilling{ProjectID: project.ID, Name: project.Name},
+				lines:   make(map[uint]*BillingLine),
+			}
+			c.projects[project.ID] = p
+		}
+
+		line, ok := p.lines[activity.ID]
+		if !ok {
+			line = &BillingLine{
+				ActivityID:   activity.ID,
+				ActivityName: activity.Name,
+				Rate:         ResolveBillingRate(client, project, activity),
+			}
+			p.lines[activity.ID] = line
+		}
+
+		seconds := entry.DurationSeconds(*entry.EndTime)
+		line.EntryCount++
+		line.TrackedSeconds += seconds
+		line.BilledSeconds += RoundBilledSeconds(seconds, client.RoundingMinutes, client.RoundingMode)
+	}
+
+	summary := &BillingSummary{
+		From:     filter.From,
+		To:       filter.To,
+		Timezone: filter.Location.String(),
+		Amounts:  []BillingAmount{},
+		Clients:  make([]ClientBilling, 0, len(clients)),
+	}
+	overall := make(map[string]int64)
+
+	for _, c := range clients {
+		clientAmounts := make(map[string]int64)
+		for _, p := range c.projects {
+			projectAmounts := make(map[string]int64)
+			for _, line := range p.lines {
+				line.BilledTime = utils.FormatDuration(line.BilledSeconds)
+				if line.Rate != nil {
+					// Cents for the billed hours, rounded half up
+					line.AmountCents = (line.BilledSeconds*line.Rate.HourlyRateCents + 1800) / 3600
+					projectAmounts[line.Rate.Currency] += line.AmountCents
+				}
+				p.billing.TrackedSeconds += line.TrackedSeconds
+				p.billing.BilledSeconds += line.BilledSeconds
+				p.billing.Lines = append(p.billing.Lines, *line)
+			}
+			sort.Slice(p.billing.Lines, func(i, j int) bool { return p.billing.Lines[i].ActivityName < p.billing.Lines[j].ActivityName })
+
+			p.billing.BilledTime = utils.FormatDuration(p.billing.BilledSeconds)
+			p.billing.Amounts = billingAmounts(projectAmounts)
+			for currency, amount := range projectAmounts {
+				clientAmounts[currency] += amount
+			}
+			c.billing.TrackedSeconds += p.billing.TrackedSeconds
+			c.billing.BilledSeconds += p.billing.BilledSeconds
+			c.billing.Projects = append(c.billing.Projects, p.billing)
+		}
+		sort.Slice(c.billing.Projects, func(i, j int) bool { return c.billing.Projects[i].Name < c.billing.Projects[j].Name })
+
+		c.billing.BilledTime = utils.FormatDuration(c.billing.BilledSeconds)
+		c.billing.Amounts = billingAmounts(clientAmounts)
+		for currency, amount := range clientAmounts {
+			overall[currency] += amount
+		}
+		summary.Clients = append(summary.Clients, c.billing)
+	}
+	sort.Slice(summary.Clients, func(i, j int) bool { return summary.Clients[i].Name < summary.Clients[j].Name })
+	summary.Amounts = billingAmounts(overall)
+
+	return summary, nil
+}
+
+// billingAmounts lists amounts by currency code
+func billingAmounts(byCurrency map[string]int64) []BillingAmount {
+	amounts := make([]BillingAmount, 0, len(byCurrency))
+	for currency, cents := range byCurrency {
+		amounts = append(amounts, BillingAmount{Currency: currency, AmountCents: cents})
+	}
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i].Currency < amounts[j].Currency })
+	return amounts
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrActivityNotFound   = errors.New("activity not found")
+	ErrNoRewardsAvailable = errors.New("no rewards available")
+)
+
+// TrackedSeconds counts tracked time like the reward status does, in the database
+// TimeEntryRepository implements it, so claims and the status share one definition of tracked time
+type TrackedSeconds interface {
+	// ActivitySeconds returns the tracked seconds of an activity
+	ActivitySeconds(activityID uint) (int64, error)
+	// SecondsByActivity returns the tracked seconds of each of the user's activities that has time
+	SecondsByActivity(userID uint) (map[uint]int64, error)
+}
+
+// TrackedSecondsIn returns the TrackedSeconds running its queries inside a claim's transaction
+type TrackedSecondsIn func(tx *gorm.DB) TrackedSeconds
+
+// ClaimedReward is a reward handed out for one tracked interval of an activity
+type ClaimedReward struct {
+	Reward       models.UserReward
+	Result       *RewardResult
+	ActivityID   uint
+	TotalMinutes int // Tracked minutes of the activity, which set the drop rates
+}
+
+// ClaimActivityReward claims one reward for the next unrewarded interval of an activity
+// The activity row is locked for the whole claim so concurrent claims cannot spend the same interval
+// Returns the reward and how many intervals of the activity are left to claim
+func ClaimActivityReward(db *gorm.DB, trackedSeconds TrackedSecondsIn, catalog RewardCatalog, userID, activityID uint) (*ClaimedReward, int, error) {
+	var claimed *ClaimedReward
+	var remaining int
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var activity models.Activity
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NULL", activityID, userID).
+			First(&activity).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrActivityNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		seconds, err := trackedSeconds(tx).ActivitySeconds(activity.ID)
+		if err != nil {
+			return err
+		}
+
+		claimable, _, totalMinutes := CalculateClaimableRewards(seconds, activity.IntervalsRewarded)
+		if claimable <= 0 {
+			return ErrNoRewardsAvailable
+		}
+
+		claimed, err = claimInterval(tx, catalog, userID, &activity, totalMinutes)
+		remaining = claimable - 1
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return claimed, remaining, nil
+}
+
+// ClaimAllRewards claims a reward for every unrewarded interval of all the user's activities
+// Either every reward is claimed or none is; the activities stay locked until the claim is done
+func ClaimAllRewards(db *gorm.DB, trackedSeconds TrackedSecondsIn, catalog RewardCatalog, userID uint) ([]ClaimedReward, error) {
+	var claimed []ClaimedReward
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Locking in ID order keeps concurrent claims from deadlocking each other
+		var activities []models.Activity
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND deleted_at IS NULL", userID).
+			Order("id").
+			Find(&activities).Error
+		if err != nil {
+			return err
+		}
+
+		seconds, err := trackedSeconds(tx).SecondsByActivity(userID)
+		if err != nil {
+			return err
+		}
+
+		for i := range activities {
+			activity := &activities[i]
+			claimable, _, totalMinutes := CalculateClaimableRewards(seconds[activity.ID], activity.IntervalsRewarded)
+			for ; claimable > 0; claimable-- {
+				reward, err := claimInterval(tx, catalog, userID, activity, totalMinutes)
+				if err != nil {
+					return err
+				}
+				claimed = append(claimed, *reward)
+			}
+		}
+
+		if len(claimed) == 0 {
+			return ErrNoRewardsAvailable
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// claimInterval draws a reward for one interval of a locked activity and counts the interval as rewarded
+// Must run inside a transaction
+func claimInterval(tx *gorm.DB, catalog RewardCatalog, userID uint, activity *models.Activity, totalMinutes int) (*ClaimedReward, error) {
+	result, err := GenerateReward(tx, catalog, userID, totalMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	reward, err := addDrawnReward(tx, userID, result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Model(activity).
+		UpdateColumn("intervals_rewarded", gorm.Expr("intervals_rewarded + 1")).Error
+	if err != nil {
+		return nil, err
+	}
+	activity.IntervalsRewarded++
+
+	return &ClaimedReward{Reward: *reward, Result: result, ActivityID: activity.ID, TotalMinutes: totalMinutes}, nil
+}
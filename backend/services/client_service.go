@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrClientNotFound  = errors.New("client not found")
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+// ListClients returns the user's clients by name
+func ListClients(db *gorm.DB, userID uint) ([]models.Client, error) {
+	var clients []models.Client
+	err := db.Where("user_id = ? AND deleted_at IS NULL", userID).Order("name, id").Find(&clients).Error
+	return clients, err
+}
+
+// FindClient returns one of the user's clients
+func FindClient(db *gorm.DB, userID, clientID uint) (*models.Client, error) {
+	var client models.Client
+	err := db.Where("id = ? AND user_id = ? AND deleted_at IS NULL", clientID, userID).First(&client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrClientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// SaveClient creates or updates a validated client
+func SaveClient(db *gorm.DB, client *models.Client) error {
+	return db.Save(client).Error
+}
+
+// DeleteClient soft deletes a client with its projects
+// Their activities keep the project but their time is no longer billed
+func DeleteClient(db *gorm.DB, userID, clientID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Client{}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NULL", clientID, userID).
+			Update("deleted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrClientNotFound
+		}
+
+		return tx.Model(&models.Project{}).
+			Where("client_id = ? AND deleted_at IS NULL", clientID).
+			Update("deleted_at", now).Error
+	})
+}
+
+// ListProjects returns the user's projects with their client, only those of one client if clientID is not 0
+func ListProjects(db *gorm.DB, userID, clientID uint) ([]models.Project, error) {
+	query := db.Preload("Client").Where("user_id = ? AND deleted_at IS NULL", userID)
+	if clientID != 0 {
+		query = query.Where("client_id = ?", clientID)
+	}
+
+	var projects []models.Project
+	err := query.Order("name, id").Find(&projects).Error
+	return projects, err
+}
+
+// FindProject returns one of the user's projects with its client
+func FindProject(db *gorm.DB, userID, projectID uint) (*models.Project, error) {
+	var project models.Project
+	err := db.Preload("Client").
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", projectID, userID).
+		First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// SaveProject creates or updates a validated project after checking its client belongs to the user
+// The project is returned with its client loaded
+func SaveProject(db *gorm.DB, project *models.Project) error {
+	client, err := FindClient(db, project.UserID, project.ClientID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Omit("Client").Save(project).Error; err != nil {
+		return err
+	}
+	project.Client = client
+	return nil
+}
+
+// DeleteProject soft deletes a project; its activities keep it but their time is no longer billed
+func DeleteProject(db *gorm.DB, userID, projectID uint) error {
+	result := db.Model(&models.Project{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", projectID, userID).
+		Update("deleted_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// SetActivityBilling stores the project, rate and currency of a validated activity
+// A project must belong to the activity's user; nil leaves the activity unbilled
+func SetActivityBilling(db *gorm.DB, activity *models.Activity) error {
+	if activity.ProjectID != nil {
+		if _, err := FindProject(db, activity.UserID, *activity.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	return db.Model(activity).
+		Select("project_id", "hourly_rate_cents", "currency").
+		Updates(activity).Error
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddUserReward stores a reward drop and adds a copy of it to the user's collection
+func AddUserReward(db *gorm.DB, reward *models.UserReward) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reward).Error; err != nil {
+			return err
+		}
+
+		obtainedAt := reward.CreatedAt
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "catalog"}, {Name: "reward_type"}, {Name: "external_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count":            gorm.Expr("owned_rewards.count + 1"),
+				"name":             reward.Name,
+				"image_url":        reward.ImageURL,
+				"rarity":           reward.Rarity,
+				"last_obtained_at": obtainedAt,
+			}),
+		}).Create(&models.OwnedReward{
+			UserID:          reward.UserID,
+			Catalog:         reward.Catalog,
+			RewardType:      reward.RewardType,
+			ExternalID:      reward.ExternalID,
+			Name:            reward.Name,
+			ImageURL:        reward.ImageURL,
+			Rarity:          reward.Rarity,
+			Count:           1,
+			FirstObtainedAt: obtainedAt,
+			LastObtainedAt:  obtainedAt,
+		}).Error
+	})
+}
+
+// addDrawnReward stores a reward drawn for the user as a drop and adds it to their collection
+func addDrawnReward(db *gorm.DB, userID uint, result *RewardResult) (*models.UserReward, error) {
+	reward := models.UserReward{
+		UserID:     userID,
+		Catalog:    result.Catalog,
+		RewardType: result.RewardType,
+		ExternalID: result.ExternalID,
+		Name:       result.Name,
+		ImageURL:   result.ImageURL,
+		Rarity:     result.Rarity,
+	}
+	if err := AddUserReward(db, &reward); err != nil {
+		return nil, err
+	}
+	return &reward, nil
+}
+
+// FindOwnedReward returns how many copies of a reward the user holds, nil if it is not in their collection
+func FindOwnedReward(db *gorm.DB, userID uint, catalog string, rewardType models.RewardType, externalID string) (*models.OwnedReward, error) {
+	var owned models.OwnedReward
+	result := db.Where("user_id = ? AND catalog = ? AND reward_type = ? AND external_id = ?",
+		userID, catalog, rewardType, externalID).
+		Limit(1).
+		Find(&owned)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &owned, nil
+}
+
+// ListCollection returns every reward the user owns, most recently obtained first
+func ListCollection(db *gorm.DB, userID uint) ([]models.OwnedReward, error) {
+	var owned []models.OwnedReward
+	err := db.Where("user_id = ?", userID).Order("last_obtained_at DESC, id DESC").Find(&owned).Error
+	return owned, err
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRewardNotFound      = errors.New("reward not found")
+	ErrRewardNotDuplicate  = errors.New("only duplicate rewards can be disenchanted")
+	ErrRewardAlreadyOwned  = errors.New("reward is already in the collection")
+	ErrInsufficientEssence = errors.New("not enough essence")
+	ErrRewardNotCraftable  = errors.New("reward cannot be crafted")
+)
+
+// EssenceLedgerLimit is how many of the latest essence transactions are listed
+const EssenceLedgerLimit = 50
+
+// GetEssenceBalance returns the user's essence, 0 if they never had any
+func GetEssenceBalance(db *gorm.DB, userID uint) (int, error) {
+	balance := models.EssenceBalance{UserID: userID}
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance.Balance, nil
+}
+
+// ListEssenceTransactions returns the user's latest essence ledger entries, newest first
+func ListEssenceTransactions(db *gorm.DB, userID uint) ([]models.EssenceTransaction, error) {
+	var transactions []models.EssenceTransaction
+	err := db.Where("user_id = ?", userID).Order("id DESC").Limit(EssenceLedgerLimit).Find(&transactions).Error
+	return transactions, err
+}
+
+// DisenchantReward turns a duplicate reward into essence worth its rarity
+// The reward is removed from the collection; the user keeps at least one copy of it
+func DisenchantReward(db *gorm.DB, userID, rewardID uint) (*models.EssenceTransaction, error) {
+	var transaction *models.EssenceTransaction
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var reward models.UserReward
+		if err := tx.Where("id = ? AND user_id = ?", rewardID, userID).First(&reward).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRewardNotFound
+			}
+			return err
+		}
+
+		// Only the transaction that takes a copy out of the collection gets the essence,
+		// and the last copy never leaves it
+		removed := tx.Model(&models.OwnedReward{}).
+			Where("user_id = ? AND catalog = ? AND reward_type = ? AND external_id = ? AND count >= 2",
+				userID, reward.Catalog, reward.RewardType, reward.ExternalID).
+			UpdateColumn("count", gorm.Expr("count - 1"))
+		if removed.Error != nil {
+			return removed.Error
+		}
+		if removed.RowsAffected != 1 {
+			return ErrRewardNotDuplicate
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", reward.ID, userID).Delete(&models.UserReward{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrRewardNotFound
+		}
+
+		var err error
+		transaction, err = changeEssence(tx, userID, models.DisenchantValues[reward.Rarity], models.EssenceReasonDisenchant, &RewardResult{
+			Catalog:    reward.Catalog,
+			RewardType: reward.RewardType,
+			ExternalID: reward.ExternalID,
+			Name:       reward.Name,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// CraftReward spends essence on a reward the user picked from a catalog and adds it to their collection
+// The reward must not be in the collection yet; the whole craft fails if the balance is too low
+func CraftReward(db *gorm.DB, catalog RewardCatalog, userID uint, rewardType models.RewardType, externalID string) (*models.UserReward, *models.EssenceTransaction, error) {
+	if catalog == nil {
+		return nil, nil, ErrCatalogUnavailable
+	}
+
+	result, err := catalog.Find(rewardType, externalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cost, ok := models.CraftCosts[result.Rarity]
+	if !ok {
+		return nil, nil, ErrRewardNotCraftable
+	}
+
+	var reward *models.UserReward
+	var transaction *models.EssenceTransaction
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Locking the balance first makes concurrent crafts wait, so the second one sees the reward owned
+		// Without a balance row there is no essence to spend and the craft fails below anyway
+		var balance models.EssenceBalance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Limit(1).Find(&balance).Error; err != nil {
+			return err
+		}
+
+		owned, err := FindOwnedReward(tx, userID, result.Catalog, result.RewardType, result.ExternalID)
+		if err != nil {
+			return err
+		}
+		if owned != nil {
+			return ErrRewardAlreadyOwned
+		}
+
+		transaction, err = changeEssence(tx, userID, -cost, models.EssenceReasonCraft, result)
+		if err != nil {
+			return err
+		}
+
+		reward, err = addDrawnReward(tx, userID, result)
+		if err != nil {
+			return err
+		}
+
+		return recordChampionMastery(tx, userID, result)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reward, transaction, nil
+}
+
+// changeEssence adds amount (negative to spend) to the user's balance and records it in the ledger
+// Spending only succeeds if the balance covers it, so the balance never goes negative
+// Must run inside a transaction
+func changeEssence(tx *gorm.DB, userID uint, amount int, reason models.EssenceReason, reward *RewardResult) (*models.EssenceTransaction, error) {
+	now := time.Now()
+
+	if amount >= 0 {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"balance":    gorm.Expr("essence_balances.balance + ?", amount),
+				"updated_at": now,
+			}),
+		}).Create(&models.EssenceBalance{UserID: userID, Balance: amount, UpdatedAt: now}).Error
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		result := tx.Model(&models.EssenceBalance{}).
+			Where("user_id = ? AND balance >= ?", userID, -amount).
+			Updates(map[string]interface{}{
+				"balance":    gorm.Expr("balance + ?", amount),
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil, ErrInsufficientEssence
+		}
+	}
+
+	balance, err := GetEssenceBalance(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := models.EssenceTransaction{
+		UserID:     userID,
+		Amount:     amount,
+		Balance:    balance,
+		Reason:     reason,
+		Catalog:    reward.Catalog,
+		RewardType: reward.RewardType,
+		ExternalID: reward.ExternalID,
+		Name:       reward.Name,
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
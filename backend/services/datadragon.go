@@ -1,28 +1,61 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"math/rand"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"go.uber.org/zap"
 )
 
 const (
 	DataDragonBaseURL = "https://ddragon.leagueoflegends.com"
+
+	// DataDragonCatalogKey is the reward catalog key of the League of Legends catalog
+	DataDragonCatalogKey = "lol"
+
+	// DataDragonRefreshInterval is how often the catalog is checked for a new game version
+	DataDragonRefreshInterval = 24 * time.Hour
+
+	// dataDragonRetryInterval is how soon a failed refresh is retried
+	dataDragonRetryInterval = 5 * time.Minute
+
+	// dataDragonFetchConcurrency bounds the parallel per-champion requests when fetching skins
+	dataDragonFetchConcurrency = 8
 )
 
-// DataDragonService handles fetching and caching LoL data
+// DataDragonService serves the LoL reward catalog from a local cache
+// The catalog is loaded from CachePath on startup and refreshed from BaseURL in the background
 type DataDragonService struct {
-	version    string
-	champions  []ChampionData
-	items      []ItemData
-	icons      []IconData
-	skins      []SkinData
-	mu         sync.RWMutex
-	lastUpdate time.Time
+	BaseURL         string
+	CachePath       string
+	RefreshInterval time.Duration
+	Concurrency     int
+	Client          *http.Client
+	Logger          *zap.Logger
+
+	mu      sync.RWMutex
+	catalog *DataDragonCatalog
+}
+
+// DataDragonCatalog is a snapshot of the Data Dragon data for one game version
+// It is what gets written to the cache file
+type DataDragonCatalog struct {
+	Version   string         `json:"version"`
+	FetchedAt time.Time      `json:"fetched_at"`
+	Champions []ChampionData `json:"champions"`
+	Items     []ItemData     `json:"items"`
+	Icons     []IconData     `json:"icons"`
+	Skins     []SkinData     `json:"skins"`
 }
 
 // ChampionData represents a LoL champion
@@ -51,82 +84,245 @@ type SkinData struct {
 	Name         string `json:"name"`
 }
 
-var ddService *DataDragonService
-var ddOnce sync.Once
+// NewDataDragonServiceFromEnv configures the service from DDRAGON_BASE_URL, DDRAGON_CACHE_PATH
+// and DDRAGON_REFRESH_INTERVAL (a Go duration such as 12h; 0 disables scheduled refreshes)
+func NewDataDragonServiceFromEnv(logger *zap.Logger) *DataDragonService {
+	service := &DataDragonService{
+		BaseURL:         strings.TrimRight(os.Getenv("DDRAGON_BASE_URL"), "/"),
+		CachePath:       os.Getenv("DDRAGON_CACHE_PATH"),
+		RefreshInterval: DataDragonRefreshInterval,
+		Concurrency:     dataDragonFetchConcurrency,
+		Client:          &http.Client{Timeout: 30 * time.Second},
+		Logger:          logger,
+	}
+	if service.BaseURL == "" {
+		service.BaseURL = DataDragonBaseURL
+	}
+	if service.CachePath == "" {
+		service.CachePath = filepath.Join("data", "ddragon_catalog.json")
+	}
+
+	if value := os.Getenv("DDRAGON_REFRESH_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			logger.Warn("Invalid DDRAGON_REFRESH_INTERVAL, using the default", zap.String("value", value), zap.Error(err))
+		} else {
+			service.RefreshInterval = interval
+		}
+	}
 
-// GetDataDragonService returns the singleton Data Dragon service
-func GetDataDragonService() *DataDragonService {
-	ddOnce.Do(func() {
-		ddService = &DataDragonService{}
-		rand.Seed(time.Now().UnixNano())
-	})
-	return ddService
+	return service
 }
 
-// Initialize fetches all data from Data Dragon
-func (s *DataDragonService) Initialize() error {
+// LoadCache reads the catalog saved by the last successful refresh
+// A missing cache file is not an error; the catalog just stays empty until the first refresh
+func (s *DataDragonService) LoadCache() error {
+	data, err := os.ReadFile(s.CachePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var catalog DataDragonCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return fmt.Errorf("invalid catalog cache %s: %w", s.CachePath, err)
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.catalog = &catalog
+	s.mu.Unlock()
+	return nil
+}
 
-	// Fetch latest version
-	if err := s.fetchVersion(); err != nil {
-		return fmt.Errorf("failed to fetch version: %w", err)
+// Start refreshes the catalog in the background until ctx is done
+// The first refresh runs right away when the cache is empty or older than RefreshInterval;
+// failed refreshes are retried sooner, and a RefreshInterval of 0 only fills an empty catalog
+func (s *DataDragonService) Start(ctx context.Context) {
+	go func() {
+		wait := s.firstRefreshIn()
+		for wait >= 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			wait = s.RefreshInterval
+			if wait <= 0 {
+				wait = -1
+			}
+			if err := s.Refresh(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.Logger.Error("Failed to refresh Data Dragon catalog", zap.Error(err))
+				wait = dataDragonRetryInterval
+			}
+		}
+	}()
+}
+
+// firstRefreshIn returns how long the cached catalog is still fresh, or -1 if it never needs a refresh
+func (s *DataDragonService) firstRefreshIn() time.Duration {
+	s.mu.RLock()
+	catalog := s.catalog
+	s.mu.RUnlock()
+
+	if catalog == nil || len(catalog.Champions) == 0 {
+		return 0
+	}
+	if s.RefreshInterval <= 0 {
+		return -1
 	}
 
-	// Fetch champions
-	if err := s.fetchChampions(); err != nil {
-		return fmt.Errorf("failed to fetch champions: %w", err)
+	wait := time.Until(catalog.FetchedAt.Add(s.RefreshInterval))
+	if wait < 0 {
+		return 0
 	}
+	return wait
+}
 
-	// Fetch items
-	if err := s.fetchItems(); err != nil {
-		return fmt.Errorf("failed to fetch items: %w", err)
+// Refresh fetches the latest catalog and saves it to the cache
+// Nothing but the version is fetched when the cached catalog is already current,
+// and a failed refresh keeps serving the previous catalog
+func (s *DataDragonService) Refresh(ctx context.Context) error {
+	version, err := s.fetchVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch version: %w", err)
 	}
 
-	// Fetch icons
-	if err := s.fetchIcons(); err != nil {
-		return fmt.Errorf("failed to fetch icons: %w", err)
+	s.mu.RLock()
+	current := s.catalog
+	s.mu.RUnlock()
+
+	var catalog *DataDragonCatalog
+	if current != nil && current.Version == version && len(current.Champions) > 0 {
+		updated := *current
+		catalog = &updated
+	} else {
+		s.Logger.Info("Fetching Data Dragon catalog", zap.String("version", version))
+		catalog, err = s.fetchCatalog(ctx, version)
+		if err != nil {
+			return err
+		}
 	}
+	catalog.FetchedAt = time.Now()
 
-	// Fetch skins (requires champion data)
-	if err := s.fetchSkins(); err != nil {
-		return fmt.Errorf("failed to fetch skins: %w", err)
+	s.mu.Lock()
+	s.catalog = catalog
+	s.mu.Unlock()
+
+	if err := s.saveCache(catalog); err != nil {
+		s.Logger.Error("Failed to save Data Dragon catalog cache", zap.String("path", s.CachePath), zap.Error(err))
 	}
 
-	s.lastUpdate = time.Now()
+	s.Logger.Info("Data Dragon catalog refreshed",
+		zap.String("version", catalog.Version),
+		zap.Int("champions", len(catalog.Champions)),
+		zap.Int("items", len(catalog.Items)),
+		zap.Int("skins", len(catalog.Skins)),
+		zap.Int("icons", len(catalog.Icons)),
+	)
 	return nil
 }
 
-// fetchVersion gets the latest Data Dragon version
-func (s *DataDragonService) fetchVersion() error {
-	resp, err := http.Get(DataDragonBaseURL + "/api/versions.json")
+// saveCache writes the catalog to a temporary file and renames it so readers never see a partial file
+func (s *DataDragonService) saveCache(catalog *DataDragonCatalog) error {
+	data, err := json.Marshal(catalog)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	var versions []string
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+	if err := os.MkdirAll(filepath.Dir(s.CachePath), 0o755); err != nil {
 		return err
 	}
 
-	if len(versions) == 0 {
-		return fmt.Errorf("no versions found")
+	tmpPath := s.CachePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
 	}
+	return os.Rename(tmpPath, s.CachePath)
+}
 
-	s.version = versions[0]
-	return nil
+// fetchCatalog downloads every list of a version
+// Champions, items and icons are fetched in parallel; skins need the champions first
+func (s *DataDragonService) fetchCatalog(ctx context.Context, version string) (*DataDragonCatalog, error) {
+	catalog := &DataDragonCatalog{Version: version}
+
+	var wg sync.WaitGroup
+	var championsErr, itemsErr, iconsErr error
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		catalog.Champions, championsErr = s.fetchChampions(ctx, version)
+	}()
+	go func() {
+		defer wg.Done()
+		catalog.Items, itemsErr = s.fetchItems(ctx, version)
+	}()
+	go func() {
+		defer wg.Done()
+		catalog.Icons, iconsErr = s.fetchIcons(ctx, version)
+	}()
+	wg.Wait()
+
+	if championsErr != nil {
+		return nil, fmt.Errorf("failed to fetch champions: %w", championsErr)
+	}
+	if itemsErr != nil {
+		return nil, fmt.Errorf("failed to fetch items: %w", itemsErr)
+	}
+	if iconsErr != nil {
+		return nil, fmt.Errorf("failed to fetch icons: %w", iconsErr)
+	}
+
+	skins, err := s.fetchSkins(ctx, version, catalog.Champions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch skins: %w", err)
+	}
+	catalog.Skins = skins
+
+	return catalog, nil
 }
 
-// fetchChampions gets all champions
-func (s *DataDragonService) fetchChampions() error {
-	url := fmt.Sprintf("%s/cdn/%s/data/en_US/champion.json", DataDragonBaseURL, s.version)
-	resp, err := http.Get(url)
+// getJSON fetches a Data Dragon path and decodes the JSON response into v
+func (s *DataDragonService) getJSON(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.Client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// fetchVersion gets the latest Data Dragon version
+func (s *DataDragonService) fetchVersion(ctx context.Context) (string, error) {
+	var versions []string
+	if err := s.getJSON(ctx, "/api/versions.json", &versions); err != nil {
+		return "", err
+	}
+
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no versions found")
+	}
+
+	return versions[0], nil
+}
+
+// fetchChampions gets all champions
+func (s *DataDragonService) fetchChampions(ctx context.Context, version string) ([]ChampionData, error) {
 	var result struct {
 		Data map[string]struct {
 			ID    string `json:"id"`
@@ -135,211 +331,378 @@ func (s *DataDragonService) fetchChampions() error {
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+	if err := s.getJSON(ctx, fmt.Sprintf("/cdn/%s/data/en_US/champion.json", version), &result); err != nil {
+		return nil, err
 	}
 
-	s.champions = make([]ChampionData, 0, len(result.Data))
+	champions := make([]ChampionData, 0, len(result.Data))
 	for _, champ := range result.Data {
-		s.champions = append(s.champions, ChampionData{
+		champions = append(champions, ChampionData{
 			ID:    champ.ID,
 			Name:  champ.Name,
 			Title: champ.Title,
 		})
 	}
 
-	return nil
+	return champions, nil
 }
 
 // fetchItems gets all items
-func (s *DataDragonService) fetchItems() error {
-	url := fmt.Sprintf("%s/cdn/%s/data/en_US/item.json", DataDragonBaseURL, s.version)
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
+func (s *DataDragonService) fetchItems(ctx context.Context, version string) ([]ItemData, error) {
 	var result struct {
 		Data map[string]struct {
 			Name string `json:"name"`
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+	if err := s.getJSON(ctx, fmt.Sprintf("/cdn/%s/data/en_US/item.json", version), &result); err != nil {
+		return nil, err
 	}
 
-	s.items = make([]ItemData, 0, len(result.Data))
+	items := make([]ItemData, 0, len(result.Data))
 	for id, item := range result.Data {
-		s.items = append(s.items, ItemData{
+		items = append(items, ItemData{
 			ID:   id,
 			Name: item.Name,
 		})
 	}
 
-	return nil
+	return items, nil
 }
 
 // fetchIcons gets profile icons
-func (s *DataDragonService) fetchIcons() error {
-	url := fmt.Sprintf("%s/cdn/%s/data/en_US/profileicon.json", DataDragonBaseURL, s.version)
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
+func (s *DataDragonService) fetchIcons(ctx context.Context, version string) ([]IconData, error) {
 	var result struct {
 		Data map[string]interface{} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+	if err := s.getJSON(ctx, fmt.Sprintf("/cdn/%s/data/en_US/profileicon.json", version), &result); err != nil {
+		return nil, err
 	}
 
-	s.icons = make([]IconData, 0, len(result.Data))
+	icons := make([]IconData, 0, len(result.Data))
 	for id := range result.Data {
-		s.icons = append(s.icons, IconData{ID: id})
+		icons = append(icons, IconData{ID: id})
 	}
 
-	return nil
+	return icons, nil
 }
 
-// fetchSkins gets all skins for all champions
-func (s *DataDragonService) fetchSkins() error {
-	s.skins = make([]SkinData, 0)
+// fetchSkins gets all skins for all champions, at most Concurrency requests at a time
+// Any failed champion fails the whole fetch so a partial skin list is never cached
+func (s *DataDragonService) fetchSkins(ctx context.Context, version string, champions []ChampionData) ([]SkinData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	// Fetch detailed data for each champion to get skins
-	for _, champ := range s.champions {
-		url := fmt.Sprintf("%s/cdn/%s/data/en_US/champion/%s.json", DataDragonBaseURL, s.version, champ.ID)
-		resp, err := http.Get(url)
-		if err != nil {
-			continue // Skip on error
-		}
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			continue
-		}
+	skinsByChampion := make([][]SkinData, len(champions))
+	errs := make(chan error, len(champions))
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, champ := range champions {
+		wg.Add(1)
+		go func(i int, champ ChampionData) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
 
-		var result struct {
-			Data map[string]struct {
-				Skins []struct {
-					Num  int    `json:"num"`
-					Name string `json:"name"`
-				} `json:"skins"`
-			} `json:"data"`
-		}
+			skins, err := s.fetchChampionSkins(ctx, version, champ)
+			if err != nil {
+				errs <- fmt.Errorf("champion %s: %w", champ.ID, err)
+				cancel()
+				return
+			}
+			skinsByChampion[i] = skins
+		}(i, champ)
+	}
+	wg.Wait()
+	close(errs)
 
-		if err := json.Unmarshal(body, &result); err != nil {
-			continue
-		}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		for _, champData := range result.Data {
-			for _, skin := range champData.Skins {
-				// Skip default skin (num = 0)
-				if skin.Num == 0 {
-					continue
-				}
-				s.skins = append(s.skins, SkinData{
-					ChampionID:   champ.ID,
-					ChampionName: champ.Name,
-					SkinNum:      skin.Num,
-					Name:         skin.Name,
-				})
+	skins := make([]SkinData, 0)
+	for _, championSkins := range skinsByChampion {
+		skins = append(skins, championSkins...)
+	}
+	return skins, nil
+}
+
+// fetchChampionSkins gets the skins of one champion, without the default skin
+func (s *DataDragonService) fetchChampionSkins(ctx context.Context, version string, champ ChampionData) ([]SkinData, error) {
+	var result struct {
+		Data map[string]struct {
+			Skins []struct {
+				Num  int    `json:"num"`
+				Name string `json:"name"`
+			} `json:"skins"`
+		} `json:"data"`
+	}
+
+	if err := s.getJSON(ctx, fmt.Sprintf("/cdn/%s/data/en_US/champion/%s.json", version, champ.ID), &result); err != nil {
+		return nil, err
+	}
+
+	var skins []SkinData
+	for _, champData := range result.Data {
+		for _, skin := range champData.Skins {
+			// Skip default skin (num = 0)
+			if skin.Num == 0 {
+				continue
 			}
+			skins = append(skins, SkinData{
+				ChampionID:   champ.ID,
+				ChampionName: champ.Name,
+				SkinNum:      skin.Num,
+				Name:         skin.Name,
+			})
 		}
 	}
 
-	return nil
+	return skins, nil
 }
 
-// GetVersion returns the current Data Dragon version
-func (s *DataDragonService) GetVersion() string {
+// current returns the loaded catalog, or an empty one before the first load
+func (s *DataDragonService) current() *DataDragonCatalog {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.version
+
+	if s.catalog == nil {
+		return &DataDragonCatalog{}
+	}
+	return s.catalog
+}
+
+// GetVersion returns the current Data Dragon version
+func (s *DataDragonService) GetVersion() string {
+	return s.current().Version
+}
+
+// IsReady reports whether a catalog with champions has been loaded
+func (s *DataDragonService) IsReady() bool {
+	return len(s.current().Champions) > 0
 }
 
 // GetRandomChampion returns a random champion
 func (s *DataDragonService) GetRandomChampion() *ChampionData {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if len(s.champions) == 0 {
+	champions := s.current().Champions
+	if len(champions) == 0 {
 		return nil
 	}
 
-	return &s.champions[rand.Intn(len(s.champions))]
+	return &champions[rand.Intn(len(champions))]
 }
 
 // GetRandomItem returns a random item
 func (s *DataDragonService) GetRandomItem() *ItemData {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if len(s.items) == 0 {
+	items := s.current().Items
+	if len(items) == 0 {
 		return nil
 	}
 
-	return &s.items[rand.Intn(len(s.items))]
+	return &items[rand.Intn(len(items))]
 }
 
 // GetRandomIcon returns a random profile icon
 func (s *DataDragonService) GetRandomIcon() *IconData {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if len(s.icons) == 0 {
+	icons := s.current().Icons
+	if len(icons) == 0 {
 		return nil
 	}
 
-	return &s.icons[rand.Intn(len(s.icons))]
+	return &icons[rand.Intn(len(icons))]
 }
 
 // GetRandomSkin returns a random skin
 func (s *DataDragonService) GetRandomSkin() *SkinData {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if len(s.skins) == 0 {
+	skins := s.current().Skins
+	if len(skins) == 0 {
 		return nil
 	}
 
-	return &s.skins[rand.Intn(len(s.skins))]
+	return &skins[rand.Intn(len(skins))]
+}
+
+// Key returns the reward catalog key
+func (s *DataDragonService) Key() string {
+	return DataDragonCatalogKey
+}
+
+// Name returns the reward catalog display name
+func (s *DataDragonService) Name() string {
+	return "League of Legends"
+}
+
+// Size returns how many rewards the catalog holds
+func (s *DataDragonService) Size() int {
+	catalog := s.current()
+	return len(catalog.Champions) + len(catalog.Items) + len(catalog.Icons) + len(catalog.Skins)
+}
+
+// Draw spins the roulette for a reward type and picks a random reward of it
+func (s *DataDragonService) Draw(totalMinutes, rareBonus int) (*RewardResult, error) {
+	rewardType := SpinRoulette(totalMinutes, rareBonus)
+
+	result := RewardResult{
+		Catalog:    DataDragonCatalogKey,
+		RewardType: rewardType,
+		Rarity:     GetRarityForType(rewardType),
+	}
+
+	switch rewardType {
+	case models.RewardTypeChampion:
+		champ := s.GetRandomChampion()
+		if champ == nil {
+			return nil, ErrCatalogUnavailable
+		}
+		s.describeChampion(&result, champ)
+
+	case models.RewardTypeItem:
+		item := s.GetRandomItem()
+		if item == nil {
+			return nil, ErrCatalogUnavailable
+		}
+		s.describeItem(&result, item)
+
+	case models.RewardTypeSkin:
+		skin := s.GetRandomSkin()
+		if skin == nil {
+			return nil, ErrCatalogUnavailable
+		}
+		s.describeSkin(&result, skin)
+
+	case models.RewardTypeIcon:
+		icon := s.GetRandomIcon()
+		if icon == nil {
+			return nil, ErrCatalogUnavailable
+		}
+		s.describeIcon(&result, icon)
+	}
+
+	return &result, nil
+}
+
+// Find returns the champion, item, skin or icon with the given ID
+func (s *DataDragonService) Find(rewardType models.RewardType, externalID string) (*RewardResult, error) {
+	catalog := s.current()
+	result := RewardResult{
+		Catalog:    DataDragonCatalogKey,
+		RewardType: rewardType,
+		Rarity:     GetRarityForType(rewardType),
+	}
+
+	switch rewardType {
+	case models.RewardTypeChampion:
+		for i := range catalog.Champions {
+			if catalog.Champions[i].ID == externalID {
+				s.describeChampion(&result, &catalog.Champions[i])
+				return &result, nil
+			}
+		}
+
+	case models.RewardTypeItem:
+		for i := range catalog.Items {
+			if catalog.Items[i].ID == externalID {
+				s.describeItem(&result, &catalog.Items[i])
+				return &result, nil
+			}
+		}
+
+	case models.RewardTypeSkin:
+		for i := range catalog.Skins {
+			if skinExternalID(&catalog.Skins[i]) == externalID {
+				s.describeSkin(&result, &catalog.Skins[i])
+				return &result, nil
+			}
+		}
+
+	case models.RewardTypeIcon:
+		for i := range catalog.Icons {
+			if catalog.Icons[i].ID == externalID {
+				s.describeIcon(&result, &catalog.Icons[i])
+				return &result, nil
+			}
+		}
+	}
+
+	return nil, ErrRewardNotInCatalog
+}
+
+// describeChampion fills in the reward details of a champion
+func (s *DataDragonService) describeChampion(result *RewardResult, champ *ChampionData) {
+	result.ExternalID = champ.ID
+	result.Name = champ.Name
+	result.ImageURL = s.GetChampionImageURL(champ.ID)
+}
+
+// describeItem fills in the reward details of an item
+func (s *DataDragonService) describeItem(result *RewardResult, item *ItemData) {
+	result.ExternalID = item.ID
+	result.Name = item.Name
+	result.ImageURL = s.GetItemImageURL(item.ID)
+}
+
+// describeSkin fills in the reward details of a skin
+func (s *DataDragonService) describeSkin(result *RewardResult, skin *SkinData) {
+	result.ExternalID = skinExternalID(skin)
+	result.Name = skin.Name
+	result.ImageURL = s.GetSkinImageURL(skin.ChampionID, skin.SkinNum)
+}
+
+// describeIcon fills in the reward details of a profile icon
+func (s *DataDragonService) describeIcon(result *RewardResult, icon *IconData) {
+	result.ExternalID = icon.ID
+	result.Name = "Icon #" + icon.ID
+	result.ImageURL = s.GetIconImageURL(icon.ID)
+}
+
+// skinExternalID returns the ID a skin is stored under in user rewards, e.g. Ahri_14
+func skinExternalID(skin *SkinData) string {
+	return fmt.Sprintf("%s_%d", skin.ChampionID, skin.SkinNum)
 }
 
 // GetChampionImageURL returns the image URL for a champion
 func (s *DataDragonService) GetChampionImageURL(championID string) string {
-	return fmt.Sprintf("%s/cdn/%s/img/champion/%s.png", DataDragonBaseURL, s.version, championID)
+	return fmt.Sprintf("%s/cdn/%s/img/champion/%s.png", s.BaseURL, s.GetVersion(), championID)
 }
 
 // GetItemImageURL returns the image URL for an item
 func (s *DataDragonService) GetItemImageURL(itemID string) string {
-	return fmt.Sprintf("%s/cdn/%s/img/item/%s.png", DataDragonBaseURL, s.version, itemID)
+	return fmt.Sprintf("%s/cdn/%s/img/item/%s.png", s.BaseURL, s.GetVersion(), itemID)
 }
 
 // GetIconImageURL returns the image URL for a profile icon
 func (s *DataDragonService) GetIconImageURL(iconID string) string {
-	return fmt.Sprintf("%s/cdn/%s/img/profileicon/%s.png", DataDragonBaseURL, s.version, iconID)
+	return fmt.Sprintf("%s/cdn/%s/img/profileicon/%s.png", s.BaseURL, s.GetVersion(), iconID)
 }
 
 // GetSkinImageURL returns the splash art URL for a skin
 func (s *DataDragonService) GetSkinImageURL(championID string, skinNum int) string {
-	return fmt.Sprintf("%s/cdn/img/champion/splash/%s_%d.jpg", DataDragonBaseURL, championID, skinNum)
+	return fmt.Sprintf("%s/cdn/img/champion/splash/%s_%d.jpg", s.BaseURL, championID, skinNum)
 }
 
 // GetStats returns statistics about cached data
 func (s *DataDragonService) GetStats() map[string]int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	catalog := s.current()
 
 	return map[string]int{
-		"champions": len(s.champions),
-		"items":     len(s.items),
-		"icons":     len(s.icons),
-		"skins":     len(s.skins),
+		"champions": len(catalog.Champions),
+		"items":     len(catalog.Items),
+		"icons":     len(catalog.Icons),
+		"skins":     len(catalog.Skins),
 	}
 }
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies what changed in a live update
+type EventType string
+
+const (
+	EventTimerStarted        EventType = "timer.started"
+	EventTimerStopped        EventType = "timer.stopped"
+	EventTimerAutoStopped    EventType = "timer.auto_stopped" // stopped because another timer was started
+	EventTimerPaused         EventType = "timer.paused"
+	EventTimerResumed        EventType = "timer.resumed"
+	EventRewardClaimed       EventType = "reward.claimed"
+	EventRewardsClaimedAll   EventType = "reward.claimed_all" // every available reward claimed at once
+	EventRewardDisenchanted  EventType = "reward.disenchanted"
+	EventRewardCrafted       EventType = "reward.crafted"
+	EventActivityCreated     EventType = "activity.created"
+	EventActivityUpdated     EventType = "activity.updated"
+	EventActivityDeleted     EventType = "activity.deleted"
+	EventAchievementUnlocked EventType = "achievement.unlocked"
+	EventStreakBonusClaimed  EventType = "streak.bonus_claimed"
+	EventGoalReached         EventType = "goal.reached"         // minimum goal reached for its period
+	EventGoalBudgetExceeded  EventType = "goal.budget_exceeded" // maximum goal crossed for its period
+)
+
+// eventBufferSize is how many events a slow subscriber can fall behind before events are dropped
+const eventBufferSize = 32
+
+// Event is a live update pushed to a user's connected clients
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type EventType   `json:"type"`
+	Data interface{} `json:"data"`
+	Time time.Time   `json:"time"`
+}
+
+// EventHub is an in-process pub/sub that fans events out to every connection of a user
+// A nil hub is valid and drops everything, so handlers work without live updates
+type EventHub struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	subscribers map[uint]map[chan Event]struct{}
+}
+
+// NewEventHub creates an empty hub
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: make(map[uint]map[chan Event]struct{})}
+}
+
+// Subscribe registers a connection of the user
+// The returned function unsubscribes and must be called when the connection closes
+func (h *EventHub) Subscribe(userID uint) (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[userID], ch)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends an event to every connection of the user without blocking
+// Connections whose buffer is full miss the event; clients resync with a regular GET on reconnect
+func (h *EventHub) Publish(userID uint, eventType EventType, data interface{}) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	h.nextID++
+	event := Event{ID: h.nextID, Type: eventType, Data: data, Time: time.Now()}
+	for ch := range h.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	h.mu.Unlock()
+}
+
+// SubscriberCount returns how many connections the user has open
+func (h *EventHub) SubscriberCount(userID uint) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[userID])
+}
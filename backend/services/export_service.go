@@ -0,0 +1,115 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+// ExportBatchSize is how many entries are loaded from the database at a time while exporting
+const ExportBatchSize = 500
+
+// ExportRow is a flattened time entry with its activity, categories and tags
+type ExportRow struct {
+	ID              uint     `json:"id"`
+	ActivityID      uint     `json:"activity_id"`
+	ActivityName    string   `json:"activity_name"`
+	MainCategory    string   `json:"main_category"`
+	SubCategory     string   `json:"sub_category,omitempty"`
+	Tags            []string `json:"tags"`
+	StartTime       string   `json:"start_time"` // RFC3339 in the requested timezone
+	EndTime         string   `json:"end_time"`
+	DurationSeconds int64    `json:"duration_seconds"`
+	PausedSeconds   int64    `json:"paused_seconds"`
+	Notes           string   `json:"notes,omitempty"`
+}
+
+// ExportHeader is the CSV column order matching ExportRow.CSVRecord
+var ExportHeader = []string{
+	"id", "activity_id", "activity_name", "main_category", "sub_category", "tags",
+	"start_time", "end_time", "duration_seconds", "paused_seconds", "notes",
+}
+
+// CSVRecord returns the row as CSV fields in ExportHeader order
+func (r ExportRow) CSVRecord() []string {
+	return []string{
+		idKey(r.ID),
+		idKey(r.ActivityID),
+		r.ActivityName,
+		r.MainCategory,
+		r.SubCategory,
+		strings.Join(r.Tags, ";"),
+		r.StartTime,
+		r.EndTime,
+		strconv.FormatInt(r.DurationSeconds, 10),
+		strconv.FormatInt(r.PausedSeconds, 10),
+		r.Notes,
+	}
+}
+
+// StreamExportRows walks the user's completed entries started in [from, to) in batches
+// and calls fn for each row, so the full history is never held in memory
+// Batches are paged on (start_time, id) since manual and imported entries have ids out of order with their start times
+func StreamExportRows(db *gorm.DB, userID uint, from, to time.Time, loc *time.Location, fn func(ExportRow) error) error {
+	var last *models.TimeEntry
+
+	for {
+		query := db.
+			Preload("Activity.MainCategory").
+			Preload("Activity.SubCategory").
+			Preload("Activity.Tags").
+			Where("user_id = ? AND end_time IS NOT NULL", userID).
+			Where("start_time >= ? AND start_time < ?", from, to)
+		if last != nil {
+			query = query.Where("(start_time > ? OR (start_time = ? AND id > ?))", last.StartTime, last.StartTime, last.ID)
+		}
+
+		var entries []models.TimeEntry
+		if err := query.Order("start_time ASC, id ASC").Limit(ExportBatchSize).Find(&entries).Error; err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := fn(newExportRow(entry, loc)); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < ExportBatchSize {
+			return nil
+		}
+		last = &entries[len(entries)-1]
+	}
+}
+
+// newExportRow flattens an entry with preloaded activity relations
+func newExportRow(entry models.TimeEntry, loc *time.Location) ExportRow {
+	activity := entry.Activity
+
+	row := ExportRow{
+		ID:              entry.ID,
+		ActivityID:      entry.ActivityID,
+		ActivityName:    activity.Name,
+		MainCategory:    activity.MainCategory.Name,
+		Tags:            make([]string, 0, len(activity.Tags)),
+		StartTime:       entry.StartTime.In(loc).Format(time.RFC3339),
+		EndTime:         entry.EndTime.In(loc).Format(time.RFC3339),
+		DurationSeconds: entry.DurationSeconds(*entry.EndTime),
+		PausedSeconds:   entry.PausedSeconds,
+	}
+
+	if activity.SubCategory != nil {
+		row.SubCategory = activity.SubCategory.Name
+	}
+	for _, tag := range activity.Tags {
+		row.Tags = append(row.Tags, tag.Name)
+	}
+	if entry.Notes != nil {
+		row.Notes = *entry.Notes
+	}
+
+	return row
+}
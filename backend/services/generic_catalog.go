@@ -0,0 +1,303 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gopkg.in/yaml.v3"
+)
+
+// rewardCatalogKeyPattern restricts catalog keys to short URL-safe names
+var rewardCatalogKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// RewardCatalogDefinition describes a custom catalog, in catalog files and in the admin API
+type RewardCatalogDefinition struct {
+	Key     string             `json:"key" yaml:"key"`
+	Name    string             `json:"name" yaml:"name"`
+	Rewards []RewardDefinition `json:"rewards" yaml:"rewards"`
+}
+
+// RewardDefinition describes one reward of a custom catalog
+type RewardDefinition struct {
+	ID       string `json:"id" yaml:"id"`
+	Type     string `json:"type" yaml:"type"`
+	Name     string `json:"name" yaml:"name"`
+	ImageURL string `json:"image_url" yaml:"image_url"`
+	Rarity   string `json:"rarity" yaml:"rarity"` // common, rare or epic; defaults to common
+}
+
+// Validate normalizes the definition and checks it can be used as a catalog
+func (d *RewardCatalogDefinition) Validate() error {
+	d.Key = strings.ToLower(strings.TrimSpace(d.Key))
+	d.Name = strings.TrimSpace(d.Name)
+
+	if !rewardCatalogKeyPattern.MatchString(d.Key) {
+		return errors.New("key must be 1-64 lowercase letters, digits, '-' or '_'")
+	}
+	if d.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(d.Rewards) == 0 {
+		return errors.New("catalog needs at least one reward")
+	}
+
+	seen := make(map[string]bool, len(d.Rewards))
+	for i := range d.Rewards {
+		reward := &d.Rewards[i]
+		reward.ID = strings.TrimSpace(reward.ID)
+		reward.Type = strings.ToLower(strings.TrimSpace(reward.Type))
+		reward.Name = strings.TrimSpace(reward.Name)
+		reward.ImageURL = strings.TrimSpace(reward.ImageURL)
+		reward.Rarity = strings.ToLower(strings.TrimSpace(reward.Rarity))
+
+		if reward.ID == "" || reward.Type == "" || reward.Name == "" {
+			return fmt.Errorf("reward %d: id, type and name are required", i+1)
+		}
+		if seen[reward.ID] {
+			return fmt.Errorf("reward %d: duplicate id %q", i+1, reward.ID)
+		}
+		seen[reward.ID] = true
+
+		switch models.Rarity(reward.Rarity) {
+		case "":
+			reward.Rarity = string(models.RarityCommon)
+		case models.RarityCommon, models.RarityRare, models.RarityEpic:
+		default:
+			return fmt.Errorf("reward %d: rarity must be common, rare or epic", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Items converts the rewards of a validated definition to catalog items
+func (d *RewardCatalogDefinition) Items() []models.RewardCatalogItem {
+	items := make([]models.RewardCatalogItem, 0, len(d.Rewards))
+	for _, reward := range d.Rewards {
+		items = append(items, models.RewardCatalogItem{
+			ExternalID: reward.ID,
+			RewardType: models.RewardType(reward.Type),
+			Name:       reward.Name,
+			ImageURL:   reward.ImageURL,
+			Rarity:     models.Rarity(reward.Rarity),
+		})
+	}
+	return items
+}
+
+// RarityRates defines the percentage chance for each rarity in a custom catalog
+type RarityRates struct {
+	Common int
+	Rare   int
+	Epic   int
+}
+
+// GetRarityRatesForMinutes returns rarity odds based on total minutes tracked,
+// following the same milestones as the League of Legends drop rates
+func GetRarityRatesForMinutes(totalMinutes int) RarityRates {
+	switch {
+	case totalMinutes >= 120:
+		return RarityRates{Common: 40, Rare: 40, Epic: 20}
+	case totalMinutes >= 60:
+		return RarityRates{Common: 55, Rare: 33, Epic: 12}
+	case totalMinutes >= 45:
+		return RarityRates{Common: 65, Rare: 27, Epic: 8}
+	case totalMinutes >= 30:
+		return RarityRates{Common: 75, Rare: 20, Epic: 5}
+	default:
+		return RarityRates{Common: 85, Rare: 12, Epic: 3}
+	}
+}
+
+// WithRareBonus moves up to bonus percentage points from common to rare
+func (r RarityRates) WithRareBonus(bonus int) RarityRates {
+	moved := min(bonus, r.Common)
+	r.Common -= moved
+	r.Rare += moved
+	return r
+}
+
+// rarityFallbacks lists the rarities to draw from, in order, when a catalog has no reward of the rolled one
+// A rare or epic roll never falls back to a common reward while the catalog has any rare or epic
+var rarityFallbacks = map[models.Rarity][]models.Rarity{
+	models.RarityCommon: {models.RarityCommon},
+	models.RarityRare:   {models.RarityRare, models.RarityEpic},
+	models.RarityEpic:   {models.RarityEpic, models.RarityRare},
+}
+
+// GenericCatalog is a user-defined catalog loaded from a file or managed through the admin API
+type GenericCatalog struct {
+	key      string
+	name     string
+	path     string // File the catalog was loaded from, empty for catalogs stored in the database
+	items    []models.RewardCatalogItem
+	byRarity map[models.Rarity][]models.RewardCatalogItem
+}
+
+// NewGenericCatalog creates a catalog from its items
+// path is the file it was loaded from, or empty for catalogs stored in the database
+func NewGenericCatalog(key, name, path string, items []models.RewardCatalogItem) *GenericCatalog {
+	catalog := &GenericCatalog{
+		key:      key,
+		name:     name,
+		path:     path,
+		items:    items,
+		byRarity: make(map[models.Rarity][]models.RewardCatalogItem),
+	}
+	for _, item := range items {
+		catalog.byRarity[item.Rarity] = append(catalog.byRarity[item.Rarity], item)
+	}
+	return catalog
+}
+
+// NewGenericCatalogFromRecord creates a catalog from one stored in the database
+func NewGenericCatalogFromRecord(record *models.RewardCatalogRecord) *GenericCatalog {
+	return NewGenericCatalog(record.Key, record.Name, "", record.Items)
+}
+
+// LoadGenericCatalogFile loads a catalog from a .json, .yaml or .yml file
+// The key defaults to the file name without its extension
+func LoadGenericCatalogFile(path string) (*GenericCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition RewardCatalogDefinition
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &definition)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &definition)
+	default:
+		return nil, fmt.Errorf("%s: unsupported catalog file type", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if definition.Key == "" {
+		definition.Key = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return NewGenericCatalog(definition.Key, definition.Name, path, definition.Items()), nil
+}
+
+// LoadGenericCatalogDir loads every catalog file in dir, ordered by file name
+// A missing directory holds no catalogs
+func LoadGenericCatalogDir(dir string) ([]*GenericCatalog, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	catalogs := make([]*GenericCatalog, 0, len(paths))
+	for _, path := range paths {
+		catalog, err := LoadGenericCatalogFile(path)
+		if err != nil {
+			return nil, err
+		}
+		catalogs = append(catalogs, catalog)
+	}
+	return catalogs, nil
+}
+
+// Key returns the catalog key
+func (c *GenericCatalog) Key() string {
+	return c.key
+}
+
+// Name returns the catalog display name
+func (c *GenericCatalog) Name() string {
+	return c.name
+}
+
+// Size returns how many rewards the catalog holds
+func (c *GenericCatalog) Size() int {
+	return len(c.items)
+}
+
+// Path returns the file the catalog was loaded from, empty if it is stored in the database
+func (c *GenericCatalog) Path() string {
+	return c.path
+}
+
+// Items returns the rewards of the catalog
+func (c *GenericCatalog) Items() []models.RewardCatalogItem {
+	return c.items
+}
+
+// Draw rolls a rarity and picks a random reward of it
+// Falls back per rarityFallbacks, then to the whole catalog, when it has no reward of the rolled rarity
+func (c *GenericCatalog) Draw(totalMinutes, rareBonus int) (*RewardResult, error) {
+	if len(c.items) == 0 {
+		return nil, ErrCatalogUnavailable
+	}
+
+	rates := GetRarityRatesForMinutes(totalMinutes).WithRareBonus(rareBonus)
+	roll := rand.Intn(100)
+
+	rarity := models.RarityEpic
+	switch {
+	case roll < rates.Common:
+		rarity = models.RarityCommon
+	case roll < rates.Common+rates.Rare:
+		rarity = models.RarityRare
+	}
+
+	pool := c.items
+	for _, candidate := range rarityFallbacks[rarity] {
+		if len(c.byRarity[candidate]) > 0 {
+			pool = c.byRarity[candidate]
+			break
+		}
+	}
+	return c.result(pool[rand.Intn(len(pool))]), nil
+}
+
+// Find returns the reward with the given type and ID
+func (c *GenericCatalog) Find(rewardType models.RewardType, externalID string) (*RewardResult, error) {
+	for _, item := range c.items {
+		if item.RewardType == rewardType && item.ExternalID == externalID {
+			return c.result(item), nil
+		}
+	}
+	return nil, ErrRewardNotInCatalog
+}
+
+// result describes one of the catalog's items as a reward
+func (c *GenericCatalog) result(item models.RewardCatalogItem) *RewardResult {
+	return &RewardResult{
+		Catalog:    c.key,
+		RewardType: item.RewardType,
+		ExternalID: item.ExternalID,
+		Name:       item.Name,
+		ImageURL:   item.ImageURL,
+		Rarity:     item.Rarity,
+	}
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGoalNotFound      = errors.New("goal not found")
+	ErrGoalScopeNotFound = errors.New("goal scope not found")
+)
+
+// GoalState is how a goal is doing in its current period
+type GoalState string
+
+const (
+	GoalOnTrack   GoalState = "on_track"  // Minimum keeping pace with the period, or budget not exceeded
+	GoalBehind    GoalState = "behind"    // Minimum falling behind the pace the period needs
+	GoalCompleted GoalState = "completed" // Minimum reached
+	GoalExceeded  GoalState = "exceeded"  // Budget exceeded
+)
+
+// GoalCheckInterval is how often running timers are checked for goals they cross
+const GoalCheckInterval = time.Minute
+
+// GoalProgress is a goal with the time tracked towards it in the current period
+type GoalProgress struct {
+	Goal             models.Goal `json:"goal"`
+	PeriodStart      time.Time   `json:"period_start"`
+	PeriodEnd        time.Time   `json:"period_end"`
+	TrackedSeconds   int64       `json:"tracked_seconds"`
+	TrackedTime      string      `json:"tracked_time"`
+	TargetSeconds    int64       `json:"target_seconds"`
+	RemainingSeconds int64       `json:"remaining_seconds"` // Left to reach a minimum or to spend of a budget
+	Percentage       float64     `json:"percentage"`        // Share of the target tracked so far
+	Status           GoalState   `json:"status"`
+}
+
+// Crossed reports whether the goal's target was crossed in the period
+func (p *GoalProgress) Crossed() bool {
+	return p.Status == GoalCompleted || p.Status == GoalExceeded
+}
+
+// CrossedEvent returns the event announcing the goal was crossed
+func (p *GoalProgress) CrossedEvent() EventType {
+	if p.Goal.Kind == models.GoalKindMaximum {
+		return EventGoalBudgetExceeded
+	}
+	return EventGoalReached
+}
+
+// ListGoals returns the user's goals, oldest first
+func ListGoals(db *gorm.DB, userID uint) ([]models.Goal, error) {
+	var goals []models.Goal
+	err := db.Where("user_id = ?", userID).Order("id").Find(&goals).Error
+	return goals, err
+}
+
+// FindGoal returns one of the user's goals
+func FindGoal(db *gorm.DB, userID, goalID uint) (*models.Goal, error) {
+	var goal models.Goal
+	err := db.Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGoalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// SaveGoal creates or updates a validated goal after checking its scope belongs to the user
+// A goal whose target is already crossed in the current period is not announced again for it
+func SaveGoal(db *gorm.DB, goal *models.Goal, now time.Time) error {
+	if err := checkGoalScope(db, goal); err != nil {
+		return err
+	}
+
+	settings, err := GetUserSettings(db, goal.UserID)
+	if err != nil {
+		return err
+	}
+
+	progress, err := computeGoalProgress(db, []models.Goal{*goal}, settings, now)
+	if err != nil {
+		return err
+	}
+	goal.CrossedPeriod = ""
+	if progress[0].Crossed() {
+		goal.CrossedPeriod = progress[0].PeriodStart.Format(streakDayFormat)
+	}
+
+	return db.Save(goal).Error
+}
+
+// DeleteGoal removes one of the user's goals
+func DeleteGoal(db *gorm.DB, userID, goalID uint) error {
+	result := db.Where("id = ? AND user_id = ?", goalID, userID).Delete(&models.Goal{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGoalNotFound
+	}
+	return nil
+}
+
+// checkGoalScope makes sure the activity, category or tag of a goal exists and belongs to its user
+func checkGoalScope(db *gorm.DB, goal *models.Goal) error {
+	var model interface{}
+	switch goal.ScopeType {
+	case models.GoalScopeActivity:
+		model = &models.Activity{}
+	case models.GoalScopeCategory:
+		model = &models.Category{}
+	case models.GoalScopeTag:
+		model = &models.Tag{}
+	default:
+		return ErrGoalScopeNotFound
+	}
+
+	var count int64
+	err := db.Model(model).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", goal.ScopeID, goal.UserID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrGoalScopeNotFound
+	}
+	return nil
+}
+
+// GetGoalProgress returns the progress of every goal of the user in its current period
+func GetGoalProgress(db *gorm.DB, userID uint, now time.Time) ([]GoalProgress, error) {
+	goals, err := ListGoals(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := GetUserSettings(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeGoalProgress(db, goals, settings, now)
+}
+
+// CheckGoalCrossings returns the user's goals whose target was crossed in the current period
+// and had not been announced yet, marking them as announced
+func CheckGoalCrossings(db *gorm.DB, userID uint, now time.Time) ([]GoalProgress, error) {
+	progress, err := GetGoalProgress(db, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var crossed []GoalProgress
+	for _, p := range progress {
+		if !p.Crossed() {
+			continue
+		}
+
+		// Only the caller that moves the goal to this period announces it
+		period := p.PeriodStart.Format(streakDayFormat)
+		result := db.Model(&models.Goal{}).
+			Where("id = ? AND crossed_period <> ?", p.Goal.ID, period).
+			UpdateColumn("crossed_period", period)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			p.Goal.CrossedPeriod = period
+			crossed = append(crossed, p)
+		}
+	}
+	return crossed, nil
+}
+
+// computeGoalProgress sums the time tracked towards each goal in its current period, up to now
+// Entries only store their total paused time, so it is spread evenly over entries that start before a period
+func computeGoalProgress(db *gorm.DB, goals []models.Goal, settings *models.UserSettings, now time.Time) ([]GoalProgress, error) {
+	progress := make([]GoalProgress, 0, len(goals))
+	if len(goals) == 0 {
+		return progress, nil
+	}
+
+	local := now.In(settings.Location())
+	earliest := now
+	for _, goal := range goals {
+		start, end := goalPeriodRange(goal.Period, local, settings.WeekStart)
+		earliest = minTime(earliest, start)
+		progress = append(progress, GoalProgress{
+			Goal:          goal,
+			PeriodStart:   start,
+			PeriodEnd:     end,
+			TargetSeconds: int64(goal.TargetMinutes) * 60,
+		})
+	}
+
+	var entries []models.TimeEntry
+	err := db.
+		Preload("Activity.Tags").
+		Joins("JOIN activities ON activities.id = time_entries.activity_id").
+		Where("time_entries.user_id = ?", settings.UserID).
+		Where("time_entries.start_time < ? AND (time_entries.end_time IS NULL OR time_entries.end_time > ?)", now, earliest).
+		Where("activities.deleted_at IS NULL").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range progress {
+		p := &progress[i]
+		for _, entry := range entries {
+			if goalMatchesActivity(&p.Goal, &entry.Activity) {
+				p.TrackedSeconds += trackedSecondsBetween(&entry, p.PeriodStart, now)
+			}
+		}
+
+		p.TrackedTime = utils.FormatDuration(p.TrackedSeconds)
+		p.Percentage = percentage(p.TrackedSeconds, p.TargetSeconds)
+		p.RemainingSeconds = max(p.TargetSeconds-p.TrackedSeconds, 0)
+		p.Status = goalState(p, now)
+	}
+
+	return progress, nil
+}
+
+// goalPeriodRange returns the start and end of the day, week or month containing local, on its clock
+func goalPeriodRange(period models.GoalPeriod, local time.Time, weekStart time.Weekday) (time.Time, time.Time) {
+	start, _ := utils.GetPeriodDateRange(string(period), local, weekStart)
+	switch period {
+	case models.GoalPeriodDay:
+		return start, start.AddDate(0, 0, 1)
+	case models.GoalPeriodMonth:
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return start, start.AddDate(0, 0, 7)
+	}
+}
+
+// goalMatchesActivity reports whether time tracked on the activity counts towards the goal
+func goalMatchesActivity(goal *models.Goal, activity *models.Activity) bool {
+	switch goal.ScopeType {
+	case models.GoalScopeActivity:
+		return activity.ID == goal.ScopeID
+	case models.GoalScopeCategory:
+		return activity.MainCategoryID == goal.ScopeID ||
+			(activity.SubCategoryID != nil && *activity.SubCategoryID == goal.ScopeID)
+	case models.GoalScopeTag:
+		for _, tag := range activity.Tags {
+			if tag.ID == goal.ScopeID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trackedSecondsBetween returns the part of an entry's tracked time that falls in [from, now)
+// Running entries are measured up to now
+func trackedSecondsBetween(entry *models.TimeEntry, from, now time.Time) int64 {
+	end := now
+	if entry.EndTime != nil {
+		end = minTime(*entry.EndTime, now)
+	}
+
+	wall := end.Sub(entry.StartTime)
+	overlap := end.Sub(maxTime(entry.StartTime, from))
+	if wall <= 0 || overlap <= 0 {
+		return 0
+	}
+
+	seconds := entry.DurationSeconds(end)
+	if overlap == wall {
+		return seconds
+	}
+	return int64(float64(seconds) * float64(overlap) / float64(wall))
+}
+
+// goalState compares a goal's progress with its target and, for minimums, with the pace of the period
+func goalState(p *GoalProgress, now time.Time) GoalState {
+	if p.Goal.Kind == models.GoalKindMaximum {
+		if p.TrackedSeconds > p.TargetSeconds {
+			return GoalExceeded
+		}
+		return GoalOnTrack
+	}
+
+	if p.TrackedSeconds >= p.TargetSeconds {
+		return GoalCompleted
+	}
+	elapsed := float64(now.Sub(p.PeriodStart)) / float64(p.PeriodEnd.Sub(p.PeriodStart))
+	if float64(p.TrackedSeconds) < float64(p.TargetSeconds)*elapsed {
+		return GoalBehind
+	}
+	return GoalOnTrack
+}
+
+// GoalWatcher announces goals crossed by running timers, which no request is around to notice
+type GoalWatcher struct {
+	DB       *gorm.DB
+	Events   *EventHub
+	Logger   *zap.Logger
+	Interval time.Duration
+}
+
+// NewGoalWatcher creates a watcher that checks every GoalCheckInterval
+func NewGoalWatcher(db *gorm.DB, events *EventHub, logger *zap.Logger) *GoalWatcher {
+	return &GoalWatcher{DB: db, Events: events, Logger: logger, Interval: GoalCheckInterval}
+}
+
+// Start checks running timers in the background until ctx is done
+func (w *GoalWatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := w.CheckRunningTimers(now); err != nil {
+					w.Logger.Error("Failed to check goals of running timers", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// CheckRunningTimers announces the newly crossed goals of every user with a running, unpaused timer
+func (w *GoalWatcher) CheckRunningTimers(now time.Time) error {
+	var userIDs []uint
+	err := w.DB.Model(&models.TimeEntry{}).
+		Where("end_time IS NULL AND paused_at IS NULL").
+		Where("user_id IN (SELECT user_id FROM goals)").
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		crossed, err := CheckGoalCrossings(w.DB, userID, now)
+		if err != nil {
+			w.Logger.Error("Failed to check goals", zap.Uint("user_id", userID), zap.Error(err))
+			continue
+		}
+		for _, progress := range crossed {
+			w.Events.Publish(userID, progress.CrossedEvent(), progress)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,528 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"gorm.io/gorm"
+)
+
+// ImportSource identifies the tool a CSV export comes from
+type ImportSource string
+
+const (
+	ImportSourceToggl    ImportSource = "toggl"
+	ImportSourceClockify ImportSource = "clockify"
+)
+
+// ImportRowStatus is the outcome of a single CSV row
+type ImportRowStatus string
+
+const (
+	ImportRowOK        ImportRowStatus = "ok"        // Imported (or would be, on a dry run)
+	ImportRowDuplicate ImportRowStatus = "duplicate" // Already imported earlier, skipped
+	ImportRowError     ImportRowStatus = "error"     // Invalid row, skipped
+)
+
+// DefaultImportCategory is the main category used when a row has no client
+const DefaultImportCategory = "Imported"
+
+var (
+	ErrUnknownImportFormat  = errors.New("unrecognized CSV export format")
+	ErrAmbiguousImportDates = errors.New("cannot tell whether the CSV dates are day or month first")
+)
+
+// ImportOptions controls how a CSV export is imported
+type ImportOptions struct {
+	Source   ImportSource   // Empty to detect from the header
+	Location *time.Location // Timezone the export's dates and times are written in
+	// DateFormat is the day and month order of slash dates, DD/MM/YYYY or MM/DD/YYYY
+	// Anything else detects it from the file, failing with ErrAmbiguousImportDates if no date tells
+	DateFormat models.DateFormat
+	DryRun     bool // Validate and preview without writing anything
+}
+
+// ImportRowResult describes what happened to one CSV row
+type ImportRowResult struct {
+	Row             int             `json:"row"` // 1-based line number in the file, header included
+	Status          ImportRowStatus `json:"status"`
+	Error           string          `json:"error,omitempty"`
+	ActivityName    string          `json:"activity_name,omitempty"`
+	MainCategory    string          `json:"main_category,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
+	StartTime       *time.Time      `json:"start_time,omitempty"`
+	EndTime         *time.Time      `json:"end_time,omitempty"`
+	DurationSeconds int64           `json:"duration_seconds,omitempty"`
+	Billable        bool            `json:"billable"`
+	TimeEntryID     uint            `json:"time_entry_id,omitempty"`
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	Source     ImportSource      `json:"source"`
+	DryRun     bool              `json:"dry_run"`
+	TotalRows  int               `json:"total_rows"`
+	Imported   int               `json:"imported"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+// importRecord is a parsed CSV row, independent of the source format
+type importRecord struct {
+	row         int
+	project     string
+	client      string
+	task        string
+	description string
+	tags        []string
+	billable    bool
+	start       time.Time
+	end         time.Time
+}
+
+// activityName is the activity the record is tracked against
+func (r importRecord) activityName() string {
+	switch {
+	case r.project != "":
+		return r.project
+	case r.description != "":
+		return r.description
+	default:
+		return "Imported (no project)"
+	}
+}
+
+// category is the main category the record's activity belongs to
+func (r importRecord) category() string {
+	if r.client != "" {
+		return r.client
+	}
+	return DefaultImportCategory
+}
+
+// notes combines task and description into the entry notes
+func (r importRecord) notes() *string {
+	notes := r.description
+	if r.task != "" {
+		notes = strings.TrimSpace(r.task + ": " + r.description)
+	}
+	if notes == "" {
+		return nil
+	}
+	return &notes
+}
+
+// key fingerprints the record so the same export can be imported twice without duplicates
+func (r importRecord) key(source ImportSource) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%s|%s|%s",
+		source, r.start.Unix(), r.end.Unix(), r.project, r.client, r.description)))
+	return hex.EncodeToString(sum[:])
+}
+
+// DetectImportSource guesses the export format from the CSV header
+func DetectImportSource(header []string) (ImportSource, error) {
+	columns := importColumns(header)
+
+	if _, ok := columns["start date"]; !ok {
+		return "", ErrUnknownImportFormat
+	}
+	if _, ok := columns["duration (h)"]; ok {
+		return ImportSourceClockify, nil
+	}
+	if _, ok := columns["duration (decimal)"]; ok {
+		return ImportSourceClockify, nil
+	}
+	if _, ok := columns["duration"]; ok {
+		return ImportSourceToggl, nil
+	}
+	return "", ErrUnknownImportFormat
+}
+
+// ImportTimeEntries parses a Toggl or Clockify CSV export and imports it for the user
+// Invalid, overlapping and already imported rows are reported per row and skipped.
+// The whole import runs in one transaction so a database failure leaves nothing behind.
+func ImportTimeEntries(db *gorm.DB, userID uint, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	// Strip the UTF-8 byte order mark both tools prepend
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	source := opts.Source
+	if source == "" {
+		if source, err = DetectImportSource(header); err != nil {
+			return nil, err
+		}
+	}
+	columns := importColumns(header)
+
+	result := &ImportResult{Source: source, DryRun: opts.DryRun, Rows: []ImportRowResult{}}
+
+	// Rows are read before parsing since the order of slash dates can only be told from the whole file
+	type csvRow struct {
+		line   int
+		fields []string
+	}
+	var rows []csvRow
+	var dates []string
+	for line := 2; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		result.TotalRows++
+		if err != nil {
+			result.Rows = append(result.Rows, ImportRowResult{Row: line, Status: ImportRowError, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, csvRow{line: line, fields: fields})
+		dates = append(dates, importField(fields, columns, "start date"), importField(fields, columns, "end date"))
+	}
+
+	dateFormat := opts.DateFormat
+	if dateFormat != models.DateFormatDMY && dateFormat != models.DateFormatMDY {
+		if dateFormat, err = detectImportDateFormat(dates); err != nil {
+			return nil, err
+		}
+	}
+	dateLayouts := importDateLayouts(dateFormat)
+
+	var records []importRecord
+	for _, row := range rows {
+		record, err := parseImportRecord(row.fields, columns, dateLayouts, opts.Location)
+		if err != nil {
+			result.Rows = append(result.Rows, ImportRowResult{Row: row.line, Status: ImportRowError, Error: err.Error()})
+			continue
+		}
+		record.row = row.line
+		records = append(records, record)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		seen := map[string]bool{}
+		var accepted []importRecord
+
+		for _, record := range records {
+			row := newImportRowResult(record)
+			key := record.key(source)
+
+			status, problem, err := validateImportRecord(tx, userID, record, key, seen, accepted)
+			if err != nil {
+				return err
+			}
+			if status != ImportRowOK {
+				row.Status = status
+				row.Error = problem
+				result.Rows = append(result.Rows, row)
+				continue
+			}
+
+			seen[key] = true
+			accepted = append(accepted, record)
+
+			if !opts.DryRun {
+				entryID, err := createImportedEntry(tx, userID, record, source, key)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", record.row, err)
+				}
+				row.TimeEntryID = entryID
+			}
+
+			result.Rows = append(result.Rows, row)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result.Rows, func(i, j int) bool {
+		return result.Rows[i].Row < result.Rows[j].Row
+	})
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case ImportRowOK:
+			result.Imported++
+		case ImportRowDuplicate:
+			result.Duplicates++
+		case ImportRowError:
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// validateImportRecord checks a record against existing entries and earlier rows of the same file
+// Returns the row status with a description of the problem; err is only set for database failures
+func validateImportRecord(tx *gorm.DB, userID uint, record importRecord, key string, seen map[string]bool, accepted []importRecord) (ImportRowStatus, string, error) {
+	if seen[key] {
+		return ImportRowDuplicate, "", nil
+	}
+
+	var existing int64
+	if err := tx.Model(&models.TimeEntry{}).Where("user_id = ? AND import_key = ?", userID, key).Count(&existing).Error; err != nil {
+		return "", "", err
+	}
+	if existing > 0 {
+		return ImportRowDuplicate, "", nil
+	}
+
+	if record.end.After(time.Now()) {
+		return ImportRowError, "entry ends in the future", nil
+	}
+
+	for _, other := range accepted {
+		if record.start.Before(other.end) && record.end.After(other.start) {
+			return ImportRowError, fmt.Sprintf("overlaps row %d", other.row), nil
+		}
+	}
+
+	overlapping, err := FindOverlappingEntry(tx, userID, record.start, record.end, 0)
+	if err != nil {
+		return "", "", err
+	}
+	if overlapping != nil {
+		return ImportRowError, fmt.Sprintf("overlaps existing time entry %d", overlapping.ID), nil
+	}
+
+	return ImportRowOK, "", nil
+}
+
+// createImportedEntry stores a record, creating its activity, category and tags as needed
+func createImportedEntry(tx *gorm.DB, userID uint, record importRecord, source ImportSource, key string) (uint, error) {
+	activity, err := findOrCreateImportActivity(tx, userID, record)
+	if err != nil {
+		return 0, err
+	}
+
+	sourceName := string(source)
+	entry := models.TimeEntry{
+		UserID:       userID,
+		ActivityID:   activity.ID,
+		StartTime:    record.start,
+		EndTime:      &record.end,
+		Notes:        record.notes(),
+		ImportSource: &sourceName,
+		ImportKey:    &key,
+		Billable:     record.billable,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return 0, err
+	}
+
+	return entry.ID, nil
+}
+
+// findOrCreateImportActivity maps project, client and tags onto an activity of the user
+func findOrCreateImportActivity(tx *gorm.DB, userID uint, record importRecord) (*models.Activity, error) {
+	name := record.activityName()
+
+	var activity models.Activity
+	err := tx.Where("user_id = ? AND LOWER(name) = LOWER(?) AND deleted_at IS NULL", userID, name).First(&activity).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		category, err := FindOrCreateCategory(tx, userID, record.category())
+		if err != nil {
+			return nil, err
+		}
+
+		activity = models.Activity{
+			UserID:         userID,
+			Name:           name,
+			MainCategoryID: category.ID,
+		}
+		if err := tx.Create(&activity).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var tags []models.Tag
+	for _, tagName := range record.tags {
+		tag, err := FindOrCreateTag(tx, userID, tagName)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+	if len(tags) > 0 {
+		if err := tx.Model(&activity).Association("Tags").Append(&tags); err != nil {
+			return nil, err
+		}
+	}
+
+	return &activity, nil
+}
+
+// newImportRowResult builds the preview of a parsed record
+func newImportRowResult(record importRecord) ImportRowResult {
+	start, end := record.start, record.end
+	return ImportRowResult{
+		Row:             record.row,
+		Status:          ImportRowOK,
+		ActivityName:    record.activityName(),
+		MainCategory:    record.category(),
+		Tags:            record.tags,
+		StartTime:       &start,
+		EndTime:         &end,
+		DurationSeconds: utils.CalculateDuration(start, end),
+		Billable:        record.billable,
+	}
+}
+
+// importColumns maps lowercased header names to their column index
+func importColumns(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return columns
+}
+
+// importField returns the trimmed value of a named column, empty if the row lacks it
+func importField(fields []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
+
+// parseImportRecord reads the shared columns of Toggl and Clockify detailed exports
+func parseImportRecord(fields []string, columns map[string]int, dateLayouts []string, loc *time.Location) (importRecord, error) {
+	get := func(name string) string {
+		return importField(fields, columns, name)
+	}
+
+	record := importRecord{
+		project:     get("project"),
+		client:      get("client"),
+		task:        get("task"),
+		description: get("description"),
+	}
+
+	for _, tag := range strings.Split(get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			record.tags = append(record.tags, tag)
+		}
+	}
+
+	var err error
+	if record.billable, err = parseImportBillable(get("billable")); err != nil {
+		return record, err
+	}
+	if record.start, err = parseImportTime(get("start date"), get("start time"), dateLayouts, loc); err != nil {
+		return record, fmt.Errorf("invalid start: %w", err)
+	}
+	if record.end, err = parseImportTime(get("end date"), get("end time"), dateLayouts, loc); err != nil {
+		return record, fmt.Errorf("invalid end: %w", err)
+	}
+	if !record.end.After(record.start) {
+		return record, errors.New("end must be after start")
+	}
+
+	return record, nil
+}
+
+// parseImportBillable reads the Billable column both tools write as Yes or No
+// Exports without the column, or rows with it empty, are billable like entries tracked here
+func parseImportBillable(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "yes", "true", "1":
+		return true, nil
+	case "no", "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid billable %q, expected Yes or No", value)
+	}
+}
+
+// Time layouts used by Toggl and Clockify depending on the workspace settings
+var importTimeLayouts = []string{"15:04:05", "15:04", "03:04:05 PM", "3:04:05 PM", "03:04 PM", "3:04 PM"}
+
+// importDateLayouts returns the date layouts used by Toggl and Clockify depending on the workspace settings
+// Slash dates are only read in the given day and month order, never guessed
+func importDateLayouts(format models.DateFormat) []string {
+	layouts := []string{"2006-01-02", "02.01.2006", "2006/01/02"}
+	switch format {
+	case models.DateFormatDMY:
+		layouts = append(layouts, "02/01/2006")
+	case models.DateFormatMDY:
+		layouts = append(layouts, "01/02/2006")
+	}
+	return layouts
+}
+
+// detectImportDateFormat tells the day and month order of the slash dates in an export
+// A part above 12 can only be a day; files without slash dates need no order and return ""
+func detectImportDateFormat(dates []string) (models.DateFormat, error) {
+	var slashDates, dayFirst, monthFirst bool
+	for _, date := range dates {
+		parts := strings.Split(date, "/")
+		if len(parts) != 3 || len(parts[2]) != 4 {
+			continue
+		}
+		slashDates = true
+		if first, err := strconv.Atoi(parts[0]); err == nil && first > 12 {
+			dayFirst = true
+		}
+		if second, err := strconv.Atoi(parts[1]); err == nil && second > 12 {
+			monthFirst = true
+		}
+	}
+
+	switch {
+	case !slashDates:
+		return "", nil
+	case dayFirst && !monthFirst:
+		return models.DateFormatDMY, nil
+	case monthFirst && !dayFirst:
+		return models.DateFormatMDY, nil
+	default:
+		return "", ErrAmbiguousImportDates
+	}
+}
+
+// parseImportTime combines a date and a time column in the export's timezone
+func parseImportTime(date, clock string, dateLayouts []string, loc *time.Location) (time.Time, error) {
+	if date == "" || clock == "" {
+		return time.Time{}, errors.New("missing date or time")
+	}
+
+	for _, dateLayout := range dateLayouts {
+		for _, timeLayout := range importTimeLayouts {
+			if t, err := time.ParseInLocation(dateLayout+" "+timeLayout, date+" "+clock, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date/time %q %q", date, clock)
+}
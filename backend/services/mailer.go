@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails to users
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewMailerFromEnv picks the mailer from MAIL_DRIVER, which must be set to smtp or log
+// The SMTP mailer reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and MAIL_FROM;
+// the log mailer is for local development only and writes each message to MAIL_DIR when it is set
+func NewMailerFromEnv(logger *zap.Logger) (Mailer, error) {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		from := os.Getenv("MAIL_FROM")
+		if from == "" {
+			from = "no-reply@timetracker.local"
+		}
+		return &SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}, nil
+
+	case "log":
+		logger.Warn("MAIL_DRIVER is log: emails are not sent, use it for local development only")
+		return &LogMailer{Logger: logger, Dir: os.Getenv("MAIL_DIR")}, nil
+
+	case "":
+		return nil, fmt.Errorf("MAIL_DRIVER is not set; use smtp, or log for local development")
+
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q; use smtp or log", driver)
+	}
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the message, authenticating with PLAIN auth when a username is configured
+func (m *SMTPMailer) Send(msg Message) error {
+	if m.Host == "" {
+		return fmt.Errorf("smtp host is not configured")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	headers := []string{
+		"From: " + m.From,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(msg.Body, "\n", "\r\n")
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{msg.To}, []byte(body))
+}
+
+// LogMailer logs emails instead of sending them, for local development and tests
+// Bodies carry secrets such as password reset links, so they are never logged;
+// when Dir is set every message is written there in full as a .eml file
+// Sent keeps the messages in memory so tests can inspect them
+type LogMailer struct {
+	Logger *zap.Logger
+	Dir    string
+
+	mu   sync.Mutex
+	Sent []Message
+}
+
+// Send records the message
+func (m *LogMailer) Send(msg Message) error {
+	m.mu.Lock()
+	m.Sent = append(m.Sent, msg)
+	m.mu.Unlock()
+
+	if m.Logger != nil {
+		m.Logger.Info("Email (not sent, log mailer)",
+			zap.String("to", msg.To),
+			zap.String("subject", msg.Subject),
+		)
+	}
+
+	if m.Dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), sanitizeFilename(msg.To))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o644)
+}
+
+// sanitizeFilename keeps only characters that are safe in file names
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '@' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
+
+// PasswordResetTTL is how long an emailed reset link stays valid
+const PasswordResetTTL = time.Hour
+
+// ChangePassword replaces the user's password after verifying the current one
+// Every other session and every API token of the user is revoked; keepSessionID stays signed in
+func ChangePassword(db *gorm.DB, userID, keepSessionID uint, currentPassword, newPassword string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		if !user.CheckPassword(currentPassword) {
+			return ErrIncorrectPassword
+		}
+
+		if err := user.SetPassword(newPassword); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("password_hash", user.PasswordHash).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		_, err = RevokeAllAPITokens(tx, userID)
+		return err
+	})
+}
+
+// RequestPasswordReset emails a reset link to the user with the given email
+// Unknown emails are silently ignored so the endpoint does not reveal which accounts exist
+func RequestPasswordReset(db *gorm.DB, mailer Mailer, email string) error {
+	var user models.User
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, tokenHash, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only the most recent link works
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			ExpiresAt: now.Add(PasswordResetTTL),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", frontendURL(), token)
+	return mailer.Send(Message{
+		To:      user.Email,
+		Subject: "Reset your Time Tracker password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password of your Time Tracker account.\n"+
+				"Open the link below within %d minutes to choose a new one:\n\n%s\n\n"+
+				"If this wasn't you, ignore this email and your password stays the same.\n",
+			user.Name, int(PasswordResetTTL.Minutes()), link,
+		),
+	})
+}
+
+// ResetPassword sets a new password using an emailed reset token
+// The token is consumed and all of the user's sessions and API tokens are revoked
+func ResetPassword(db *gorm.DB, token, newPassword string) error {
+	now := time.Now()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var reset models.PasswordResetToken
+		err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), now).
+			First(&reset).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		// Consume the token first so a concurrent request with it fails
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		var user models.User
+		if err := tx.First(&user, reset.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if err := user.SetPassword(newPassword); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("password_hash", user.PasswordHash).Error; err != nil {
+			return err
+		}
+
+		if _, err := RevokeAllSessions(tx, user.ID); err != nil {
+			return err
+		}
+		_, err = RevokeAllAPITokens(tx, user.ID)
+		return err
+	})
+}
+
+// frontendURL returns the base URL of the web app used in emailed links
+func frontendURL() string {
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:5173"
+}
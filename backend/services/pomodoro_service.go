@@ -0,0 +1,413 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoActivePomodoro      = errors.New("no active pomodoro session")
+	ErrPomodoroAlreadyActive = errors.New("a pomodoro session is already active")
+	ErrInvalidPomodoroAction = errors.New("action not allowed in the current pomodoro state")
+)
+
+// PomodoroConfig holds the phase lengths for a pomodoro cycle
+type PomodoroConfig struct {
+	WorkMinutes       int `json:"work_minutes"`
+	ShortBreakMinutes int `json:"short_break_minutes"`
+	LongBreakMinutes  int `json:"long_break_minutes"`
+	LongBreakEvery    int `json:"long_break_every"`
+}
+
+// DefaultPomodoroConfig returns the classic 25/5/15 cycle with a long break every 4 pomodoros
+func DefaultPomodoroConfig() PomodoroConfig {
+	return PomodoroConfig{
+		WorkMinutes:       25,
+		ShortBreakMinutes: 5,
+		LongBreakMinutes:  15,
+		LongBreakEvery:    4,
+	}
+}
+
+// WithDefaults fills zero values with the default configuration
+func (c PomodoroConfig) WithDefaults() PomodoroConfig {
+	defaults := DefaultPomodoroConfig()
+	if c.WorkMinutes == 0 {
+		c.WorkMinutes = defaults.WorkMinutes
+	}
+	if c.ShortBreakMinutes == 0 {
+		c.ShortBreakMinutes = defaults.ShortBreakMinutes
+	}
+	if c.LongBreakMinutes == 0 {
+		c.LongBreakMinutes = defaults.LongBreakMinutes
+	}
+	if c.LongBreakEvery == 0 {
+		c.LongBreakEvery = defaults.LongBreakEvery
+	}
+	return c
+}
+
+// Validate checks that all phase lengths are within sane bounds
+func (c PomodoroConfig) Validate() error {
+	if c.WorkMinutes < 1 || c.WorkMinutes > 180 {
+		return errors.New("work_minutes must be between 1 and 180")
+	}
+	if c.ShortBreakMinutes < 1 || c.ShortBreakMinutes > 60 {
+		return errors.New("short_break_minutes must be between 1 and 60")
+	}
+	if c.LongBreakMinutes < 1 || c.LongBreakMinutes > 120 {
+		return errors.New("long_break_minutes must be between 1 and 120")
+	}
+	if c.LongBreakEvery < 1 || c.LongBreakEvery > 12 {
+		return errors.New("long_break_every must be between 1 and 12")
+	}
+	return nil
+}
+
+// GetActivePomodoro returns the user's active session, advancing it to the current time
+// Returns ErrNoActivePomodoro if the user has no active session
+func GetActivePomodoro(db *gorm.DB, userID uint) (*models.PomodoroSession, error) {
+	var session models.PomodoroSession
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := findActivePomodoro(tx, userID, &session); err != nil {
+			return err
+		}
+		return advancePomodoro(tx, &session, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// StartPomodoro starts a new session, or the next work phase of a session waiting after a break
+// Returns ErrActivityNotFound when a new session is started for an activity the user does not have
+func StartPomodoro(db *gorm.DB, userID, activityID uint, config PomodoroConfig) (*models.PomodoroSession, error) {
+	var session models.PomodoroSession
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := findActivePomodoro(tx, userID, &session)
+		if err == nil {
+			if err := advancePomodoro(tx, &session, now); err != nil {
+				return err
+			}
+			if session.Status != models.PomodoroStatusWaiting {
+				return ErrPomodoroAlreadyActive
+			}
+			return startWorkPhase(tx, &session, now)
+		}
+		if !errors.Is(err, ErrNoActivePomodoro) {
+			return err
+		}
+
+		// A waiting session keeps its own activity, so only new cycles check theirs
+		var count int64
+		err = tx.Model(&models.Activity{}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NULL", activityID, userID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrActivityNotFound
+		}
+
+		session = models.PomodoroSession{
+			UserID:            userID,
+			ActivityID:        activityID,
+			WorkMinutes:       config.WorkMinutes,
+			ShortBreakMinutes: config.ShortBreakMinutes,
+			LongBreakMinutes:  config.LongBreakMinutes,
+			LongBreakEvery:    config.LongBreakEvery,
+			Phase:             models.PomodoroPhaseWork,
+			Status:            models.PomodoroStatusRunning,
+			PhaseStartedAt:    now,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		return startWorkPhase(tx, &session, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// PausePomodoro freezes the current phase, pausing the running work entry
+func PausePomodoro(db *gorm.DB, userID uint) (*models.PomodoroSession, error) {
+	return updateActivePomodoro(db, userID, func(tx *gorm.DB, session *models.PomodoroSession, now time.Time) error {
+		if session.Status != models.PomodoroStatusRunning || session.PhaseEndsAt == nil {
+			return ErrInvalidPomodoroAction
+		}
+
+		session.RemainingSeconds = int64(session.PhaseEndsAt.Sub(now).Seconds())
+		if session.Phase == models.PomodoroPhaseWork {
+			if err := withWorkEntry(tx, session, func(entry *models.TimeEntry) error {
+				return PauseTimeEntry(tx, entry, now)
+			}); err != nil {
+				return err
+			}
+		}
+
+		session.Status = models.PomodoroStatusPaused
+		session.PhaseEndsAt = nil
+		return tx.Save(session).Error
+	})
+}
+
+// ResumePomodoro continues a paused phase with the time it had left
+func ResumePomodoro(db *gorm.DB, userID uint) (*models.PomodoroSession, error) {
+	return updateActivePomodoro(db, userID, func(tx *gorm.DB, session *models.PomodoroSession, now time.Time) error {
+		if session.Status != models.PomodoroStatusPaused {
+			return ErrInvalidPomodoroAction
+		}
+
+		if session.Phase == models.PomodoroPhaseWork {
+			if err := withWorkEntry(tx, session, func(entry *models.TimeEntry) error {
+				return ResumeTimeEntry(tx, entry, now)
+			}); err != nil {
+				return err
+			}
+		}
+
+		endsAt := now.Add(time.Duration(session.RemainingSeconds) * time.Second)
+		session.Status = models.PomodoroStatusRunning
+		session.PhaseEndsAt = &endsAt
+		session.RemainingSeconds = 0
+		return tx.Save(session).Error
+	})
+}
+
+// SkipPomodoro ends the current phase early and moves on to the next one
+// A skipped work phase keeps its tracked time but does not count as a completed pomodoro
+func SkipPomodoro(db *gorm.DB, userID uint) (*models.PomodoroSession, error) {
+	return updateActivePomodoro(db, userID, func(tx *gorm.DB, session *models.PomodoroSession, now time.Time) error {
+		if session.Status == models.PomodoroStatusWaiting || session.Phase != models.PomodoroPhaseWork {
+			return startWorkPhase(tx, session, now)
+		}
+
+		if err := closeWorkEntry(tx, session, now, false); err != nil {
+			return err
+		}
+		return startBreakPhase(tx, session, now)
+	})
+}
+
+// AbortPomodoro ends the active session, keeping any time already tracked
+func AbortPomodoro(db *gorm.DB, userID uint) (*models.PomodoroSession, error) {
+	return updateActivePomodoro(db, userID, func(tx *gorm.DB, session *models.PomodoroSession, now time.Time) error {
+		return abortPomodoro(tx, session, now)
+	})
+}
+
+// AbortActivePomodoro ends the user's active session if there is one
+// Used when the plain timer takes over so the two never track at the same time
+func AbortActivePomodoro(db *gorm.DB, userID uint) error {
+	_, err := AbortPomodoro(db, userID)
+	if errors.Is(err, ErrNoActivePomodoro) {
+		return nil
+	}
+	return err
+}
+
+// SyncActivePomodoro advances the user's session so finished work phases close their entries
+// Reports whether a work entry was closed
+func SyncActivePomodoro(db *gorm.DB, userID uint) (bool, error) {
+	session, err := GetActivePomodoro(db, userID)
+	if errors.Is(err, ErrNoActivePomodoro) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return session.ClosedWorkEntry, nil
+}
+
+// PhaseRemainingSeconds returns how many seconds are left in the current phase
+func PhaseRemainingSeconds(session *models.PomodoroSession, now time.Time) int64 {
+	switch session.Status {
+	case models.PomodoroStatusPaused:
+		return session.RemainingSeconds
+	case models.PomodoroStatusRunning:
+		if session.PhaseEndsAt == nil {
+			return 0
+		}
+		remaining := int64(session.PhaseEndsAt.Sub(now).Seconds())
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	default:
+		return 0
+	}
+}
+
+// updateActivePomodoro loads and advances the active session, then applies fn inside a transaction
+func updateActivePomodoro(db *gorm.DB, userID uint, fn func(tx *gorm.DB, session *models.PomodoroSession, now time.Time) error) (*models.PomodoroSession, error) {
+	var session models.PomodoroSession
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := findActivePomodoro(tx, userID, &session); err != nil {
+			return err
+		}
+		if err := advancePomodoro(tx, &session, now); err != nil {
+			return err
+		}
+		return fn(tx, &session, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// findActivePomodoro loads the user's most recent session that has not been aborted
+func findActivePomodoro(tx *gorm.DB, userID uint, session *models.PomodoroSession) error {
+	err := tx.Where("user_id = ? AND status <> ?", userID, models.PomodoroStatusAborted).
+		Order("created_at DESC").
+		First(session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoActivePomodoro
+	}
+	return err
+}
+
+// advancePomodoro moves the session through every phase that has elapsed by now
+// Work phases that ran to the end are recorded as completed pomodoros on their TimeEntry
+func advancePomodoro(tx *gorm.DB, session *models.PomodoroSession, now time.Time) error {
+	changed := false
+
+	for session.Status == models.PomodoroStatusRunning && session.PhaseEndsAt != nil && !now.Before(*session.PhaseEndsAt) {
+		phaseEnd := *session.PhaseEndsAt
+		changed = true
+
+		if session.Phase == models.PomodoroPhaseWork {
+			if err := closeWorkEntry(tx, session, phaseEnd, true); err != nil {
+				return err
+			}
+			session.CompletedPomodoros++
+			if err := startBreakPhase(tx, session, phaseEnd); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Break is over - wait for the user before starting another work phase
+		session.Phase = models.PomodoroPhaseWork
+		session.Status = models.PomodoroStatusWaiting
+		session.PhaseStartedAt = phaseEnd
+		session.PhaseEndsAt = nil
+	}
+
+	if !changed {
+		return nil
+	}
+	return tx.Save(session).Error
+}
+
+// startWorkPhase begins a work phase and opens a TimeEntry for it
+func startWorkPhase(tx *gorm.DB, session *models.PomodoroSession, at time.Time) error {
+	if err := openWorkEntry(tx, session, at); err != nil {
+		return err
+	}
+
+	endsAt := at.Add(session.PhaseDuration(models.PomodoroPhaseWork))
+	session.Phase = models.PomodoroPhaseWork
+	session.Status = models.PomodoroStatusRunning
+	session.PhaseStartedAt = at
+	session.PhaseEndsAt = &endsAt
+	session.RemainingSeconds = 0
+	return tx.Save(session).Error
+}
+
+// startBreakPhase begins a short or long break depending on how many pomodoros are done
+func startBreakPhase(tx *gorm.DB, session *models.PomodoroSession, at time.Time) error {
+	phase := models.PomodoroPhaseShortBreak
+	if session.CompletedPomodoros > 0 && session.CompletedPomodoros%session.LongBreakEvery == 0 {
+		phase = models.PomodoroPhaseLongBreak
+	}
+
+	endsAt := at.Add(session.PhaseDuration(phase))
+	session.Phase = phase
+	session.Status = models.PomodoroStatusRunning
+	session.PhaseStartedAt = at
+	session.PhaseEndsAt = &endsAt
+	session.RemainingSeconds = 0
+	return tx.Save(session).Error
+}
+
+// openWorkEntry stops any running timer of the user and starts a TimeEntry for the session
+func openWorkEntry(tx *gorm.DB, session *models.PomodoroSession, at time.Time) error {
+	if err := StopRunningEntries(tx, session.UserID, at); err != nil {
+		return err
+	}
+
+	entry := models.TimeEntry{
+		UserID:            session.UserID,
+		ActivityID:        session.ActivityID,
+		StartTime:         at,
+		PomodoroSessionID: &session.ID,
+		Billable:          true,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	session.TimeEntryID = &entry.ID
+	return nil
+}
+
+// closeWorkEntry ends the TimeEntry of the current work phase if it is still running
+func closeWorkEntry(tx *gorm.DB, session *models.PomodoroSession, at time.Time, completed bool) error {
+	err := withWorkEntry(tx, session, func(entry *models.TimeEntry) error {
+		if err := CloseTimeEntry(tx, entry, at); err != nil {
+			return err
+		}
+		session.ClosedWorkEntry = true
+		return tx.Model(entry).Update("pomodoro_completed", completed).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	session.TimeEntryID = nil
+	return nil
+}
+
+// withWorkEntry runs fn on the TimeEntry of the current work phase if it is still running
+func withWorkEntry(tx *gorm.DB, session *models.PomodoroSession, fn func(entry *models.TimeEntry) error) error {
+	if session.TimeEntryID == nil {
+		return nil
+	}
+
+	var entry models.TimeEntry
+	err := tx.Where("id = ? AND end_time IS NULL", *session.TimeEntryID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return fn(&entry)
+}
+
+// abortPomodoro closes the running work entry and marks the session as ended
+func abortPomodoro(tx *gorm.DB, session *models.PomodoroSession, at time.Time) error {
+	if session.Phase == models.PomodoroPhaseWork && session.Status != models.PomodoroStatusWaiting {
+		if err := closeWorkEntry(tx, session, at, false); err != nil {
+			return err
+		}
+	}
+
+	session.Status = models.PomodoroStatusAborted
+	session.PhaseEndsAt = nil
+	session.RemainingSeconds = 0
+	session.EndedAt = &at
+	return tx.Save(session).Error
+}
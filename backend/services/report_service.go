@@ -0,0 +1,253 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"github.com/Felipalds/go-pomodoro/utils"
+	"gorm.io/gorm"
+)
+
+// ReportDimension is a field report entries can be grouped by
+type ReportDimension string
+
+const (
+	GroupByActivity     ReportDimension = "activity"
+	GroupByMainCategory ReportDimension = "main_category"
+	GroupBySubCategory  ReportDimension = "sub_category"
+	GroupByTag          ReportDimension = "tag"
+	GroupByDay          ReportDimension = "day"
+	GroupByWeek         ReportDimension = "week"
+	GroupByMonth        ReportDimension = "month"
+)
+
+// MaxReportDepth is how many group_by levels can be nested
+const MaxReportDepth = 2
+
+// IsValid reports whether the dimension is supported
+func (d ReportDimension) IsValid() bool {
+	switch d {
+	case GroupByActivity, GroupByMainCategory, GroupBySubCategory, GroupByTag, GroupByDay, GroupByWeek, GroupByMonth:
+		return true
+	}
+	return false
+}
+
+// isTimeBased reports whether groups of this dimension are ordered chronologically
+func (d ReportDimension) isTimeBased() bool {
+	return d == GroupByDay || d == GroupByWeek || d == GroupByMonth
+}
+
+// ReportFilter selects which entries go into a report
+// Entries are included when their start time falls in [From, To)
+type ReportFilter struct {
+	From        time.Time
+	To          time.Time
+	Location    *time.Location // Used to bucket entries into days, weeks and months
+	WeekStart   time.Weekday   // First day of the weeks entries are grouped into
+	GroupBy     []ReportDimension
+	ActivityIDs []uint
+	CategoryIDs []uint // Matches main or sub category
+	TagIDs      []uint
+}
+
+// ReportGroup holds the totals of one group, with optional nested groups
+type ReportGroup struct {
+	Key                string        `json:"key"`
+	Label              string        `json:"label"`
+	TotalSeconds       int64         `json:"total_seconds"`
+	TotalTime          string        `json:"total_time"`
+	EntryCount         int           `json:"entry_count"`
+	Percentage         float64       `json:"percentage"`           // Share of the whole report
+	PercentageOfParent float64       `json:"percentage_of_parent"` // Share of the enclosing group
+	Groups             []ReportGroup `json:"groups,omitempty"`
+}
+
+// Report is the result of BuildReport
+type Report struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	GroupBy      []ReportDimension `json:"group_by"`
+	TotalSeconds int64             `json:"total_seconds"`
+	TotalTime    string            `json:"total_time"`
+	EntryCount   int               `json:"entry_count"`
+	Groups       []ReportGroup     `json:"groups"`
+}
+
+// reportEntry is a completed entry with its tracked seconds precomputed
+type reportEntry struct {
+	entry   models.TimeEntry
+	seconds int64
+}
+
+// groupKey identifies the group an entry falls into for one dimension
+type groupKey struct {
+	key   string
+	label string
+}
+
+// BuildReport aggregates the user's completed entries according to the filter
+// When grouping by tag an entry counts towards every tag of its activity,
+// so tag percentages can add up to more than 100%
+func BuildReport(db *gorm.DB, userID uint, filter ReportFilter) (*Report, error) {
+	if len(filter.GroupBy) > MaxReportDepth {
+		return nil, fmt.Errorf("group_by supports at most %d levels", MaxReportDepth)
+	}
+	if filter.Location == nil {
+		filter.Location = time.Local
+	}
+
+	query := db.
+		Preload("Activity.MainCategory").
+		Preload("Activity.SubCategory").
+		Preload("Activity.Tags").
+		Joins("JOIN activities ON activities.id = time_entries.activity_id").
+		Where("time_entries.user_id = ?", userID).
+		Where("time_entries.start_time >= ? AND time_entries.start_time < ?", filter.From, filter.To).
+		Where("time_entries.end_time IS NOT NULL").
+		Where("activities.deleted_at IS NULL")
+
+	query = applyEntryFilters(query, filter)
+
+	var entries []models.TimeEntry
+	if err := query.Order("time_entries.start_time ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]reportEntry, 0, len(entries))
+	var total int64
+	for _, entry := range entries {
+		seconds := entry.DurationSeconds(*entry.EndTime)
+		items = append(items, reportEntry{entry: entry, seconds: seconds})
+		total += seconds
+	}
+
+	return &Report{
+		From:         filter.From,
+		To:           filter.To,
+		GroupBy:      filter.GroupBy,
+		TotalSeconds: total,
+		TotalTime:    utils.FormatDuration(total),
+		EntryCount:   len(items),
+		Groups:       groupReportEntries(items, filter, 0, total, total),
+	}, nil
+}
+
+// applyEntryFilters narrows a time entry query joined with activities to the filter's activities, categories and tags
+func applyEntryFilters(query *gorm.DB, filter ReportFilter) *gorm.DB {
+	if len(filter.ActivityIDs) > 0 {
+		query = query.Where("time_entries.activity_id IN ?", filter.ActivityIDs)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("(activities.main_category_id IN ? OR activities.sub_category_id IN ?)", filter.CategoryIDs, filter.CategoryIDs)
+	}
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("time_entries.activity_id IN (SELECT activity_id FROM activity_tags WHERE tag_id IN ?)", filter.TagIDs)
+	}
+	return query
+}
+
+// groupReportEntries splits entries by the filter's dimension at depth and recurses into the rest
+func groupReportEntries(items []reportEntry, filter ReportFilter, depth int, overall, parent int64) []ReportGroup {
+	if depth >= len(filter.GroupBy) {
+		return nil
+	}
+	dim := filter.GroupBy[depth]
+
+	type bucket struct {
+		groupKey
+		items   []reportEntry
+		seconds int64
+	}
+	buckets := map[string]*bucket{}
+
+	for _, item := range items {
+		for _, k := range reportKeys(item.entry, dim, filter) {
+			b, ok := buckets[k.key]
+			if !ok {
+				b = &bucket{groupKey: k}
+				buckets[k.key] = b
+			}
+			b.items = append(b.items, item)
+			b.seconds += item.seconds
+		}
+	}
+
+	groups := make([]ReportGroup, 0, len(buckets))
+	for _, b := range buckets {
+		groups = append(groups, ReportGroup{
+			Key:                b.key,
+			Label:              b.label,
+			TotalSeconds:       b.seconds,
+			TotalTime:          utils.FormatDuration(b.seconds),
+			EntryCount:         len(b.items),
+			Percentage:         percentage(b.seconds, overall),
+			PercentageOfParent: percentage(b.seconds, parent),
+			Groups:             groupReportEntries(b.items, filter, depth+1, overall, b.seconds),
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if dim.isTimeBased() {
+			return groups[i].Key < groups[j].Key
+		}
+		if groups[i].TotalSeconds != groups[j].TotalSeconds {
+			return groups[i].TotalSeconds > groups[j].TotalSeconds
+		}
+		return groups[i].Label < groups[j].Label
+	})
+
+	return groups
+}
+
+// reportKeys returns the groups an entry belongs to for a dimension
+func reportKeys(entry models.TimeEntry, dim ReportDimension, filter ReportFilter) []groupKey {
+	activity := entry.Activity
+	start := entry.StartTime.In(filter.Location)
+
+	switch dim {
+	case GroupByActivity:
+		return []groupKey{{key: idKey(activity.ID), label: activity.Name}}
+	case GroupByMainCategory:
+		return []groupKey{{key: idKey(activity.MainCategoryID), label: activity.MainCategory.Name}}
+	case GroupBySubCategory:
+		if activity.SubCategory == nil {
+			return []groupKey{{key: "0", label: "No sub category"}}
+		}
+		return []groupKey{{key: idKey(activity.SubCategory.ID), label: activity.SubCategory.Name}}
+	case GroupByTag:
+		if len(activity.Tags) == 0 {
+			return []groupKey{{key: "0", label: "Untagged"}}
+		}
+		keys := make([]groupKey, 0, len(activity.Tags))
+		for _, tag := range activity.Tags {
+			keys = append(keys, groupKey{key: idKey(tag.ID), label: tag.Name})
+		}
+		return keys
+	case GroupByDay:
+		day := start.Format("2006-01-02")
+		return []groupKey{{key: day, label: day}}
+	case GroupByWeek:
+		// Weeks are keyed by their first day so they follow the user's week start
+		key := utils.StartOfWeek(start, filter.WeekStart).Format("2006-01-02")
+		return []groupKey{{key: key, label: "Week of " + key}}
+	case GroupByMonth:
+		return []groupKey{{key: start.Format("2006-01"), label: start.Format("January 2006")}}
+	}
+
+	return nil
+}
+
+func idKey(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func percentage(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
@@ -2,13 +2,16 @@ package services
 
 import (
 	"math/rand"
+	"time"
 
 	"github.com/Felipalds/go-pomodoro/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RewardResult represents the result of spinning the roulette
 type RewardResult struct {
+	Catalog      string // Key of the catalog the reward was drawn from
 	RewardType   models.RewardType
 	ExternalID   string
 	Name         string
@@ -16,6 +19,7 @@ type RewardResult struct {
 	Rarity       models.Rarity
 	IsDuplicate  bool
 	MasteryLevel int
+	Pity         *models.RewardPity // Pity counter after this reward
 }
 
 // TimeMilestone represents minutes tracked for drop rate calculation
@@ -63,9 +67,22 @@ func GetDropRatesForMinutes(totalMinutes int) DropRates {
 	}
 }
 
+// WithRareBonus moves up to bonus percentage points from items and champions (common) to skins (rare)
+// Items are given up first; a bonus of 100 leaves only skins and icons
+func (r DropRates) WithRareBonus(bonus int) DropRates {
+	fromItem := min(bonus, r.Item)
+	fromChampion := min(bonus-fromItem, r.Champion)
+
+	r.Item -= fromItem
+	r.Champion -= fromChampion
+	r.Skin += fromItem + fromChampion
+	return r
+}
+
 // SpinRoulette determines what type of reward the user gets based on time milestone
-func SpinRoulette(totalMinutes int) models.RewardType {
-	rates := GetDropRatesForMinutes(totalMinutes)
+// rareBonus raises the odds of a skin, see DropRates.WithRareBonus
+func SpinRoulette(totalMinutes, rareBonus int) models.RewardType {
+	rates := GetDropRatesForMinutes(totalMinutes).WithRareBonus(rareBonus)
 	roll := rand.Intn(100)
 
 	switch {
@@ -96,105 +113,113 @@ func GetRarityForType(rewardType models.RewardType) models.Rarity {
 	}
 }
 
-// GenerateReward spins the roulette and generates a reward
-// totalMinutes is used to determine drop rates
-func GenerateReward(db *gorm.DB, ddService *DataDragonService, userID uint, totalMinutes int) (*RewardResult, error) {
-	rewardType := SpinRoulette(totalMinutes)
-	rarity := GetRarityForType(rewardType)
+// GenerateReward draws a reward from the user's catalog
+// totalMinutes is used to determine drop rates, and the user's pity counter raises
+// the odds of a rare after a streak of common drops
+// League of Legends champions also raise the user's mastery of them
+// Must run inside a transaction: the pity counter stays locked until it commits
+// Returns ErrCatalogUnavailable if the catalog has nothing to hand out
+func GenerateReward(db *gorm.DB, catalog RewardCatalog, userID uint, totalMinutes int) (*RewardResult, error) {
+	if catalog == nil {
+		return nil, ErrCatalogUnavailable
+	}
 
-	var result RewardResult
-	result.RewardType = rewardType
-	result.Rarity = rarity
+	pity, err := lockRewardPity(db, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	switch rewardType {
-	case models.RewardTypeChampion:
-		champ := ddService.GetRandomChampion()
-		if champ == nil {
-			return nil, nil
-		}
-		result.ExternalID = champ.ID
-		result.Name = champ.Name
-		result.ImageURL = ddService.GetChampionImageURL(champ.ID)
-
-		// Check for duplicate and update mastery
-		var mastery models.ChampionMastery
-		err := db.Where("user_id = ? AND champion_id = ?", userID, champ.ID).First(&mastery).Error
-		if err == nil {
-			// Duplicate - update mastery
-			result.IsDuplicate = true
-			mastery.TimesObtained++
-			mastery.MasteryLevel = mastery.GetMasteryLevel()
-			db.Save(&mastery)
-			result.MasteryLevel = mastery.MasteryLevel
-		} else {
-			// New champion - create mastery record
-			result.IsDuplicate = false
-			result.MasteryLevel = 1
-			mastery = models.ChampionMastery{
-				UserID:        userID,
-				ChampionID:    champ.ID,
-				ChampionName:  champ.Name,
-				ImageURL:      result.ImageURL,
-				MasteryLevel:  1,
-				TimesObtained: 1,
-			}
-			db.Create(&mastery)
-		}
+	result, err := catalog.Draw(totalMinutes, pity.RareBonus())
+	if err != nil {
+		return nil, err
+	}
 
-	case models.RewardTypeItem:
-		item := ddService.GetRandomItem()
-		if item == nil {
-			return nil, nil
-		}
-		result.ExternalID = item.ID
-		result.Name = item.Name
-		result.ImageURL = ddService.GetItemImageURL(item.ID)
+	pity.Record(result.Rarity, time.Now())
+	if err := db.Save(pity).Error; err != nil {
+		return nil, err
+	}
+	result.Pity = pity
 
-	case models.RewardTypeSkin:
-		skin := ddService.GetRandomSkin()
-		if skin == nil {
-			return nil, nil
-		}
-		result.ExternalID = skin.ChampionID + "_" + string(rune(skin.SkinNum+'0'))
-		result.Name = skin.Name
-		result.ImageURL = ddService.GetSkinImageURL(skin.ChampionID, skin.SkinNum)
+	if err := recordChampionMastery(db, userID, result); err != nil {
+		return nil, err
+	}
 
-	case models.RewardTypeIcon:
-		icon := ddService.GetRandomIcon()
-		if icon == nil {
-			return nil, nil
-		}
-		result.ExternalID = icon.ID
-		result.Name = "Icon #" + icon.ID
-		result.ImageURL = ddService.GetIconImageURL(icon.ID)
+	return result, nil
+}
+
+// recordChampionMastery raises the user's mastery of a League of Legends champion they received
+// It marks the reward as a duplicate when they already had the champion; other rewards are left alone
+// The upsert keeps concurrent claims of the same champion from failing on the unique index
+func recordChampionMastery(db *gorm.DB, userID uint, result *RewardResult) error {
+	if result.Catalog != DataDragonCatalogKey || result.RewardType != models.RewardTypeChampion {
+		return nil
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "champion_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"times_obtained": gorm.Expr("champion_masteries.times_obtained + 1"),
+			"updated_at":     time.Now(),
+		}),
+	}).Create(&models.ChampionMastery{
+		UserID:        userID,
+		ChampionID:    result.ExternalID,
+		ChampionName:  result.Name,
+		ImageURL:      result.ImageURL,
+		MasteryLevel:  1,
+		TimesObtained: 1,
+	}).Error
+	if err != nil {
+		return err
 	}
 
-	return &result, nil
+	var mastery models.ChampionMastery
+	if err := db.Where("user_id = ? AND champion_id = ?", userID, result.ExternalID).First(&mastery).Error; err != nil {
+		return err
+	}
+
+	result.IsDuplicate = mastery.TimesObtained > 1
+	result.MasteryLevel = mastery.GetMasteryLevel()
+	if mastery.MasteryLevel != result.MasteryLevel {
+		return db.Model(&mastery).Update("mastery_level", result.MasteryLevel).Error
+	}
+	return nil
 }
 
-const RewardIntervalSeconds = 15 * 60 // 15 minutes in seconds
+// GetRewardPity returns the user's pity counter, a fresh one if they have never claimed a reward
+func GetRewardPity(db *gorm.DB, userID uint) (*models.RewardPity, error) {
+	pity := models.RewardPity{UserID: userID}
+	err := db.Where("user_id = ?", userID).Limit(1).Find(&pity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pity, nil
+}
 
-// CalculateClaimableRewards calculates how many rewards an activity can claim
-// Returns: claimable count, progress to next (0.0-1.0), total minutes, error
-func CalculateClaimableRewards(db *gorm.DB, activityID uint) (int, float64, int, error) {
-	// Get total seconds for this activity
-	var totalSeconds int64
-	err := db.Table("time_entries").
-		Select("COALESCE(SUM(EXTRACT(EPOCH FROM (end_time - start_time))::INTEGER), 0)").
-		Where("activity_id = ? AND end_time IS NOT NULL", activityID).
-		Scan(&totalSeconds).Error
+// lockRewardPity returns the user's pity counter locked for update, creating it on their first claim
+// Concurrent claims of any activity or streak bonus then wait for each other instead of
+// all drawing with the same counter
+func lockRewardPity(tx *gorm.DB, userID uint) (*models.RewardPity, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RewardPity{UserID: userID}).Error
 	if err != nil {
-		return 0, 0, 0, err
+		return nil, err
 	}
 
-	// Get activity's intervals_rewarded
-	var activity models.Activity
-	if err := db.First(&activity, activityID).Error; err != nil {
-		return 0, 0, 0, err
+	var pity models.RewardPity
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&pity).Error
+	if err != nil {
+		return nil, err
 	}
+	return &pity, nil
+}
 
+const RewardIntervalSeconds = 15 * 60 // 15 minutes in seconds
+
+// CalculateClaimableRewards calculates how many rewards an activity can claim from its tracked time
+// Returns: claimable count, progress to next (0.0-1.0), total minutes
+func CalculateClaimableRewards(totalSeconds int64, intervalsRewarded int) (int, float64, int) {
 	totalIntervals := int(totalSeconds / RewardIntervalSeconds)
-	claimable := totalIntervals - activity.IntervalsRewarded
+	claimable := totalIntervals - intervalsRewarded
 	totalMinutes := int(totalSeconds / 60)
 
 	// Progress to next interval (0.0 - 1.0)
@@ -205,24 +230,17 @@ func CalculateClaimableRewards(db *gorm.DB, activityID uint) (int, float64, int,
 		claimable = 0
 	}
 
-	return claimable, progress, totalMinutes, nil
+	return claimable, progress, totalMinutes
 }
 
-// GetAllClaimableRewards returns claimable rewards across all activities for a user
-func GetAllClaimableRewards(db *gorm.DB, userID uint) ([]map[string]interface{}, int, error) {
-	var activities []models.Activity
-	if err := db.Where("user_id = ? AND deleted_at IS NULL", userID).Find(&activities).Error; err != nil {
-		return nil, 0, err
-	}
-
+// GetAllClaimableRewards returns claimable rewards across a user's activities
+// activitySeconds holds the tracked seconds of each activity by ID
+func GetAllClaimableRewards(activities []models.Activity, activitySeconds map[uint]int64) ([]map[string]interface{}, int) {
 	var results []map[string]interface{}
 	totalClaimable := 0
 
 	for _, activity := range activities {
-		claimable, progress, totalMinutes, err := CalculateClaimableRewards(db, activity.ID)
-		if err != nil {
-			continue
-		}
+		claimable, progress, totalMinutes := CalculateClaimableRewards(activitySeconds[activity.ID], activity.IntervalsRewarded)
 
 		if claimable > 0 || progress > 0 {
 			results = append(results, map[string]interface{}{
@@ -238,5 +256,5 @@ func GetAllClaimableRewards(db *gorm.DB, userID uint) ([]map[string]interface{},
 		totalClaimable += claimable
 	}
 
-	return results, totalClaimable, nil
+	return results, totalClaimable
 }
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// RefreshTokenTTL is how long a session stays valid without being refreshed
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// SessionClient describes the device a session was created from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// AuthTokens is the token pair handed to a client after login or refresh
+type AuthTokens struct {
+	SessionID        uint
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// CreateSession starts a new session for the user and issues its first token pair
+func CreateSession(db *gorm.DB, user *models.User, client SessionClient) (*AuthTokens, error) {
+	now := time.Now()
+
+	refreshToken, refreshHash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the user's sessions that can no longer be refreshed
+	if err := db.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.Session{}).Error; err != nil {
+		return nil, err
+	}
+
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        truncate(client.UserAgent, 255),
+		IPAddress:        truncate(client.IPAddress, 64),
+		ExpiresAt:        now.Add(RefreshTokenTTL),
+		LastUsedAt:       now,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return issueTokens(user, &session, refreshToken)
+}
+
+// RefreshSession exchanges a refresh token for a new token pair
+// The refresh token is rotated, so each one can only be used once
+func RefreshSession(db *gorm.DB, refreshToken string, client SessionClient) (*AuthTokens, *models.User, error) {
+	now := time.Now()
+
+	var session models.Session
+	err := db.Where("refresh_token_hash = ?", hashToken(refreshToken)).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	if !session.IsActive(now) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	var user models.User
+	if err := db.First(&user, session.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	newToken, newHash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only rotate if the token was not used concurrently by another request
+	result := db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, session.RefreshTokenHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": newHash,
+			"user_agent":         truncate(client.UserAgent, 255),
+			"ip_address":         truncate(client.IPAddress, 64),
+			"expires_at":         now.Add(RefreshTokenTTL),
+			"last_used_at":       now,
+		})
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	session.ExpiresAt = now.Add(RefreshTokenTTL)
+	tokens, err := issueTokens(&user, &session, newToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, &user, nil
+}
+
+// IsSessionActive reports whether the user's session exists and has not been revoked or expired
+func IsSessionActive(db *gorm.DB, userID, sessionID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RevokeSession logs out a single session of the user
+func RevokeSession(db *gorm.DB, userID, sessionID uint) error {
+	return db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllSessions logs out every session of the user and returns how many were active
+func RevokeAllSessions(db *gorm.DB, userID uint) (int64, error) {
+	result := db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// issueTokens signs an access token for the session and pairs it with the refresh token
+func issueTokens(user *models.User, session *models.Session, refreshToken string) (*AuthTokens, error) {
+	accessToken, accessExpiresAt, err := GenerateToken(user.ID, user.Email, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// generateOpaqueToken returns a random opaque token and the hash stored for it
+func generateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the hex SHA-256 of an opaque token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate cuts s to at most max bytes so it fits its column
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetUserSettings returns the user's settings, the defaults if they never changed them
+func GetUserSettings(db *gorm.DB, userID uint) (*models.UserSettings, error) {
+	settings := models.DefaultUserSettings(userID)
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveUserSettings stores the user's settings, which must have been validated
+func SaveUserSettings(db *gorm.DB, settings *models.UserSettings) error {
+	settings.UpdatedAt = time.Now()
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "week_start", "date_format", "updated_at"}),
+	}).Create(settings).Error
+}
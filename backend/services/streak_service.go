@@ -0,0 +1,351 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Felipalds/go-pomodoro/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StreakMilestones are the streak lengths, in days, that earn a bonus reward
+var StreakMilestones = []int{3, 7, 14, 30, 60, 100, 365}
+
+// StreakFreezeWindowDays is how far back a missed day can still be frozen
+const StreakFreezeWindowDays = 7
+
+// streakDayFormat is how calendar days are keyed and stored
+const streakDayFormat = "2006-01-02"
+
+var (
+	ErrNoFreezeTokens        = errors.New("no freeze tokens left")
+	ErrStreakDayNotFreezable = errors.New("day cannot be frozen")
+	ErrNoStreakBonus         = errors.New("no streak bonus to claim")
+)
+
+// StreakStatus describes a user's daily tracking streak
+type StreakStatus struct {
+	Current             int      `json:"current"`      // Days in the current streak
+	Longest             int      `json:"longest"`      // Longest streak ever
+	StreakStart         *string  `json:"streak_start"` // First day of the current streak
+	TodayMinutes        int      `json:"today_minutes"`
+	TodayCompleted      bool     `json:"today_completed"` // Today already counts towards the streak
+	MinMinutes          int      `json:"min_minutes"`
+	Timezone            string   `json:"timezone"`
+	FreezeTokens        int      `json:"freeze_tokens"`
+	FrozenDays          []string `json:"frozen_days"`
+	NextMilestone       *int     `json:"next_milestone"`
+	ClaimableMilestones []int    `json:"claimable_milestones"` // Milestones of the current streak without a claimed bonus
+}
+
+// GetStreakState returns the user's streak settings, the defaults if they never changed them
+func GetStreakState(db *gorm.DB, userID uint) (*models.StreakState, error) {
+	state := models.StreakState{UserID: userID, MinMinutes: models.DefaultStreakMinMinutes}
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// UpdateStreakMinMinutes changes how many tracked minutes a day needs to count towards the streak
+func UpdateStreakMinMinutes(db *gorm.DB, userID uint, minMinutes int) error {
+	if minMinutes < 1 || minMinutes > 24*60 {
+		return errors.New("min_minutes must be between 1 and 1440")
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_minutes", "updated_at"}),
+	}).Create(&models.StreakState{UserID: userID, MinMinutes: minMinutes, UpdatedAt: time.Now()}).Error
+}
+
+// dailyTrackedSeconds sums the user's tracked seconds per calendar day in loc
+// Entries count on the day they started; a running entry counts up to now
+func dailyTrackedSeconds(db *gorm.DB, userID uint, loc *time.Location, now time.Time) (map[string]int64, error) {
+	var entries []models.TimeEntry
+	err := db.Select("start_time", "end_time", "paused_at", "paused_seconds").
+		Where("user_id = ?", userID).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]int64)
+	for _, entry := range entries {
+		seconds := entry.DurationSeconds(now)
+		if seconds > 0 {
+			days[entry.StartTime.In(loc).Format(streakDayFormat)] += seconds
+		}
+	}
+	return days, nil
+}
+
+// frozenStreakDays returns the days the user spent freeze tokens on
+func frozenStreakDays(db *gorm.DB, userID uint) (map[string]bool, []string, error) {
+	var freezes []models.StreakFreeze
+	if err := db.Where("user_id = ?", userID).Order("day").Find(&freezes).Error; err != nil {
+		return nil, nil, err
+	}
+
+	frozen := make(map[string]bool, len(freezes))
+	days := make([]string, 0, len(freezes))
+	for _, freeze := range freezes {
+		frozen[freeze.Day] = true
+		days = append(days, freeze.Day)
+	}
+	return frozen, days, nil
+}
+
+// ComputeStreak returns the user's streak as of now, counting days in loc
+// A day counts if it has at least the user's minimum tracked minutes or was frozen
+// Today not counting yet does not break the streak, since it is not over
+func ComputeStreak(db *gorm.DB, userID uint, loc *time.Location, now time.Time) (*StreakStatus, error) {
+	state, err := GetStreakState(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seconds, err := dailyTrackedSeconds(db, userID, loc, now)
+	if err != nil {
+		return nil, err
+	}
+
+	frozen, frozenDays, err := frozenStreakDays(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	minSeconds := int64(state.MinMinutes) * 60
+	counts := func(day string) bool {
+		return seconds[day] >= minSeconds || frozen[day]
+	}
+
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	todayKey := today.Format(streakDayFormat)
+
+	status := StreakStatus{
+		TodayMinutes:        int(seconds[todayKey] / 60),
+		TodayCompleted:      counts(todayKey),
+		MinMinutes:          state.MinMinutes,
+		Timezone:            loc.String(),
+		FreezeTokens:        state.FreezeTokens,
+		FrozenDays:          frozenDays,
+		ClaimableMilestones: []int{},
+	}
+
+	// Current streak: back from today, or from yesterday while today does not count yet
+	day := today
+	if !status.TodayCompleted {
+		day = today.AddDate(0, 0, -1)
+	}
+	for counts(day.Format(streakDayFormat)) {
+		status.Current++
+		start := day.Format(streakDayFormat)
+		status.StreakStart = &start
+		day = day.AddDate(0, 0, -1)
+	}
+
+	// Longest streak: walk every day from the first one that counts
+	first := todayKey
+	for key := range seconds {
+		if key < first && counts(key) {
+			first = key
+		}
+	}
+	for key := range frozen {
+		if key < first {
+			first = key
+		}
+	}
+	if start, err := time.ParseInLocation(streakDayFormat, first, loc); err == nil {
+		run := 0
+		for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+			if counts(day.Format(streakDayFormat)) {
+				run++
+				status.Longest = max(status.Longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+
+	// A claim belongs to the current streak if its milestone was reached on one of the streak's days;
+	// the start moves either way as days start or stop counting, and claims made before must still count
+	claimed := make(map[int]bool)
+	if status.StreakStart != nil {
+		var claims []models.StreakBonusClaim
+		err := db.Where("user_id = ? AND reached_on >= ? AND reached_on <= ?", userID, *status.StreakStart, todayKey).Find(&claims).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, claim := range claims {
+			claimed[claim.Milestone] = true
+		}
+	}
+
+	for _, milestone := range StreakMilestones {
+		if milestone > status.Current {
+			next := milestone
+			status.NextMilestone = &next
+			break
+		}
+		if !claimed[milestone] {
+			status.ClaimableMilestones = append(status.ClaimableMilestones, milestone)
+		}
+	}
+
+	return &status, nil
+}
+
+// FreezeStreakDay spends a freeze token on a missed day so it does not break the streak
+// The day must be before today, within StreakFreezeWindowDays, and not already count
+func FreezeStreakDay(db *gorm.DB, userID uint, day time.Time, loc *time.Location, now time.Time) error {
+	status, err := ComputeStreak(db, userID, loc, now)
+	if err != nil {
+		return err
+	}
+
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	if !day.Before(today) || day.Before(today.AddDate(0, 0, -StreakFreezeWindowDays)) {
+		return ErrStreakDayNotFreezable
+	}
+
+	key := day.Format(streakDayFormat)
+	seconds, err := dailyTrackedSeconds(db, userID, loc, now)
+	if err != nil {
+		return err
+	}
+	if seconds[key] >= int64(status.MinMinutes)*60 {
+		return ErrStreakDayNotFreezable
+	}
+	for _, frozen := range status.FrozenDays {
+		if frozen == key {
+			return ErrStreakDayNotFreezable
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.StreakState{}).
+			Where("user_id = ? AND freeze_tokens > 0", userID).
+			Updates(map[string]interface{}{
+				"freeze_tokens": gorm.Expr("freeze_tokens - 1"),
+				"updated_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrNoFreezeTokens
+		}
+
+		return tx.Create(&models.StreakFreeze{UserID: userID, Day: key}).Error
+	})
+}
+
+// StreakBonusMinutes is the tracked time a streak milestone counts as for the reward drop rates
+// Longer streaks get the better odds of longer sessions
+func StreakBonusMinutes(milestone int) int {
+	return milestone * 15
+}
+
+// ClaimStreakBonus claims the bonus reward of the lowest unclaimed milestone of the current streak
+// The reward is drawn like an activity reward and each claim also earns a freeze token
+func ClaimStreakBonus(db *gorm.DB, catalog RewardCatalog, userID uint, loc *time.Location, now time.Time) (*models.UserReward, *RewardResult, int, error) {
+	var reward *models.UserReward
+	var result *RewardResult
+	var milestone int
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Freezes also update the streak state, so locking it keeps the streak from changing during the claim
+		if err := lockStreakState(tx, userID); err != nil {
+			return err
+		}
+
+		status, err := ComputeStreak(tx, userID, loc, now)
+		if err != nil {
+			return err
+		}
+		if len(status.ClaimableMilestones) == 0 {
+			return ErrNoStreakBonus
+		}
+		milestone = status.ClaimableMilestones[0]
+
+		// The unique index makes a concurrent claim of the same milestone fail here
+		start, err := time.ParseInLocation(streakDayFormat, *status.StreakStart, loc)
+		if err != nil {
+			return err
+		}
+		claim := models.StreakBonusClaim{
+			UserID:      userID,
+			StreakStart: *status.StreakStart,
+			Milestone:   milestone,
+			ReachedOn:   start.AddDate(0, 0, milestone-1).Format(streakDayFormat),
+			ClaimedAt:   now,
+		}
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&claim)
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected != 1 {
+			return ErrNoStreakBonus
+		}
+
+		result, err = GenerateReward(tx, catalog, userID, StreakBonusMinutes(milestone))
+		if err != nil {
+			return err
+		}
+
+		reward, err = addDrawnReward(tx, userID, result)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&claim).Update("user_reward_id", reward.ID).Error; err != nil {
+			return err
+		}
+
+		return grantFreezeToken(tx, userID)
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return reward, result, milestone, nil
+}
+
+// lockStreakState locks the user's streak state for update, creating it with the defaults if they have none
+// Must run inside a transaction
+func lockStreakState(tx *gorm.DB, userID uint) error {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StreakState{
+		UserID:     userID,
+		MinMinutes: models.DefaultStreakMinMinutes,
+		UpdatedAt:  time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var state models.StreakState
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&state).Error
+}
+
+// grantFreezeToken gives the user a freeze token, up to MaxStreakFreezeTokens
+func grantFreezeToken(tx *gorm.DB, userID uint) error {
+	now := time.Now()
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"freeze_tokens": gorm.Expr("CASE WHEN streak_states.freeze_tokens < ? THEN streak_states.freeze_tokens + 1 ELSE streak_states.freeze_tokens END", models.MaxStreakFreezeTokens),
+			"updated_at":    now,
+		}),
+	}).Create(&models.StreakState{
+		UserID:       userID,
+		MinMinutes:   models.DefaultStreakMinMinutes,
+		FreezeTokens: 1,
+		UpdatedAt:    now,
+	}).Error
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// StreamTicketTTL is how long a stream ticket can wait before it is used to open the event stream
+const StreamTicketTTL = 30 * time.Second
+
+// StreamTicket is who an event stream ticket was issued to, copied from the credential that asked for it
+type StreamTicket struct {
+	UserID     uint
+	SessionID  uint      // set when issued to an access token
+	APITokenID uint      // set when issued to a personal API token
+	ExpiresAt  time.Time // when the access token expires, zero for API tokens
+}
+
+// StreamTicketStore hands out short-lived single-use tickets for opening the event stream
+// The browser EventSource cannot set headers, so the ticket goes in the query string instead of
+// a token; unlike a token it is worthless once used or after StreamTicketTTL, even if it is logged
+// Tickets live in memory like the EventHub they open streams on
+type StreamTicketStore struct {
+	mu      sync.Mutex
+	tickets map[string]issuedStreamTicket // by hash of the ticket
+}
+
+type issuedStreamTicket struct {
+	ticket    StreamTicket
+	expiresAt time.Time
+}
+
+// NewStreamTicketStore creates an empty store
+func NewStreamTicketStore() *StreamTicketStore {
+	return &StreamTicketStore{tickets: make(map[string]issuedStreamTicket)}
+}
+
+// Issue creates a ticket and returns its value with when it expires
+func (s *StreamTicketStore) Issue(ticket StreamTicket) (string, time.Time, error) {
+	value, hash, err := generateOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(StreamTicketTTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop tickets that were never used
+	for key, issued := range s.tickets {
+		if !now.Before(issued.expiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+	s.tickets[hash] = issuedStreamTicket{ticket: ticket, expiresAt: expiresAt}
+
+	return value, expiresAt, nil
+}
+
+// Redeem uses up a ticket, reporting false if it is unknown, already used or expired
+func (s *StreamTicketStore) Redeem(value string) (StreamTicket, bool) {
+	hash := hashToken(value)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.tickets[hash]
+	if !ok {
+		return StreamTicket{}, false
+	}
+	delete(s.tickets, hash)
+
+	if !time.Now().Before(issued.expiresAt) {
+		return StreamTicket{}, false
+	}
+	return issued.ticket, true
+}
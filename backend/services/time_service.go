@@ -1,11 +1,19 @@
 package services
 
 import (
+	"errors"
+	"time"
+
 	"github.com/Felipalds/go-pomodoro/models"
 	"github.com/Felipalds/go-pomodoro/utils"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrTimerAlreadyPaused = errors.New("timer is already paused")
+	ErrTimerNotPaused     = errors.New("timer is not paused")
+)
+
 // ActivityStats holds statistics for an activity
 type ActivityStats struct {
 	TotalSeconds int64 `json:"total_seconds"`
@@ -27,13 +35,107 @@ func GetActivityStats(db *gorm.DB, activityID uint) (*ActivityStats, error) {
 		EntryCount:   int64(len(entries)),
 	}
 
-	// Calculate total duration
+	// Calculate total duration (paused time excluded)
 	for _, entry := range entries {
 		if entry.EndTime != nil {
-			duration := utils.CalculateDuration(entry.StartTime, *entry.EndTime)
-			stats.TotalSeconds += duration
+			stats.TotalSeconds += entry.DurationSeconds(*entry.EndTime)
 		}
 	}
 
 	return stats, nil
 }
+
+// PauseTimeEntry pauses a running entry and records the start of the pause
+func PauseTimeEntry(db *gorm.DB, entry *models.TimeEntry, at time.Time) error {
+	if entry.IsPaused() {
+		return ErrTimerAlreadyPaused
+	}
+
+	pause := models.TimePause{
+		TimeEntryID: entry.ID,
+		StartTime:   at,
+	}
+	if err := db.Create(&pause).Error; err != nil {
+		return err
+	}
+
+	entry.PausedAt = &at
+	return saveTimerState(db, entry)
+}
+
+// ResumeTimeEntry ends the open pause of an entry and adds it to the paused total
+func ResumeTimeEntry(db *gorm.DB, entry *models.TimeEntry, at time.Time) error {
+	if !entry.IsPaused() {
+		return ErrTimerNotPaused
+	}
+
+	err := db.Model(&models.TimePause{}).
+		Where("time_entry_id = ? AND end_time IS NULL", entry.ID).
+		Update("end_time", at).Error
+	if err != nil {
+		return err
+	}
+
+	entry.PausedSeconds += utils.CalculateDuration(*entry.PausedAt, at)
+	entry.PausedAt = nil
+	return saveTimerState(db, entry)
+}
+
+// CloseTimeEntry stops a running entry, ending its open pause first if it is paused
+func CloseTimeEntry(db *gorm.DB, entry *models.TimeEntry, at time.Time) error {
+	if entry.IsPaused() {
+		if err := ResumeTimeEntry(db, entry, at); err != nil {
+			return err
+		}
+	}
+
+	entry.EndTime = &at
+	return saveTimerState(db, entry)
+}
+
+// StopRunningEntries closes every running entry of the user
+func StopRunningEntries(db *gorm.DB, userID uint, at time.Time) error {
+	var entries []models.TimeEntry
+	if err := db.Where("user_id = ? AND end_time IS NULL", userID).Find(&entries).Error; err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if err := CloseTimeEntry(db, &entries[i], at); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveTimerState persists only the timer columns so preloaded associations are left alone
+func saveTimerState(db *gorm.DB, entry *models.TimeEntry) error {
+	return db.Model(entry).
+		Select("end_time", "paused_at", "paused_seconds").
+		Updates(entry).Error
+}
+
+// FindOverlappingEntry returns another entry of the user that overlaps [start, end)
+// A running entry (no end time) is treated as extending to now, so callers must not pass a future end
+// excludeID skips the entry being edited (0 to check all entries)
+func FindOverlappingEntry(db *gorm.DB, userID uint, start, end time.Time, excludeID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+
+	query := db.Where("user_id = ?", userID).
+		Where("start_time < ?", end).
+		Where("(end_time IS NULL OR end_time > ?)", start)
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+
+	err := query.Order("start_time ASC").First(&entry).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
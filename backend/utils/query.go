@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseIDList parses a comma-separated list of numeric IDs (e.g. "1,2,3")
+func ParseIDList(value string) ([]uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q", part)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
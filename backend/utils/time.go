@@ -5,37 +5,33 @@ import (
 	"time"
 )
 
-// GetPeriodDateRange returns start and end dates for a given period
-func GetPeriodDateRange(period string) (start time.Time, end time.Time) {
-	now := time.Now()
+// GetPeriodDateRange returns the start of the day, week, month or year containing now, and now itself
+// Boundaries are midnights on now's clock, so pass now in the user's location; weeks begin on weekStart
+// Unknown periods are treated as a week
+func GetPeriodDateRange(period string, now time.Time, weekStart time.Weekday) (start time.Time, end time.Time) {
 	end = now
 
 	switch period {
 	case "day":
 		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	case "week":
-		// Go back to Monday of current week
-		weekday := int(now.Weekday())
-		if weekday == 0 {
-			weekday = 7 // Sunday = 7
-		}
-		start = time.Date(now.Year(), now.Month(), now.Day()-(weekday-1), 0, 0, 0, 0, now.Location())
 	case "month":
 		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	case "year":
 		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
 	default:
-		// Default to week
-		weekday := int(now.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		start = time.Date(now.Year(), now.Month(), now.Day()-(weekday-1), 0, 0, 0, 0, now.Location())
+		start = StartOfWeek(now, weekStart)
 	}
 
 	return start, end
 }
 
+// StartOfWeek returns midnight of the first day of the week containing t, on t's clock
+// Days are counted with time.Date rather than 24h steps so DST changes do not shift the boundary
+func StartOfWeek(t time.Time, weekStart time.Weekday) time.Time {
+	daysIntoWeek := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysIntoWeek, 0, 0, 0, 0, t.Location())
+}
+
 // CalculateDuration calculates the duration in seconds between start and end time
 func CalculateDuration(start, end time.Time) int64 {
 	return int64(end.Sub(start).Seconds())
@@ -60,3 +56,22 @@ func FormatDuration(seconds int64) string {
 
 	return fmt.Sprintf("%dm", minutes)
 }
+
+// ParseDateParam parses a query parameter given as RFC3339 or YYYY-MM-DD
+// Plain dates are resolved in loc; with endOfDay they point to the start of the next day
+// so they can be used as an exclusive upper bound
+func ParseDateParam(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or RFC3339", value)
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,72 @@
+import { useEffect } from "react";
+import { useQueryClient } from "@tanstack/react-query";
+import { API_URL, api, tokenStorage } from "@/services/api";
+
+// Queries to refetch when the server pushes an event of each type
+const invalidatedQueries: Record<string, string[][]> = {
+  "timer.started": [["activeTimer"]],
+  "timer.stopped": [["activeTimer"], ["activities"], ["rewardStatus"]],
+  "timer.auto_stopped": [["activities"], ["rewardStatus"]],
+  "timer.paused": [["activeTimer"]],
+  "timer.resumed": [["activeTimer"]],
+  "reward.claimed": [["rewards"], ["rewardStatus"]],
+  "activity.created": [["activities"], ["categories"], ["tags"]],
+  "activity.updated": [["activities"], ["categories"], ["tags"]],
+  "activity.deleted": [["activities"]],
+};
+
+// Keeps queries in sync with changes made on other devices via the server event stream
+export const useLiveEvents = (enabled: boolean) => {
+  const queryClient = useQueryClient();
+
+  useEffect(() => {
+    if (!enabled) {
+      return;
+    }
+
+    let source: EventSource | null = null;
+    let retryTimeout: ReturnType<typeof setTimeout> | undefined;
+    let closed = false;
+
+    // Tokens never go in the URL; each connection uses a single-use ticket instead
+    const connect = async () => {
+      if (!tokenStorage.getToken() || closed) {
+        return;
+      }
+
+      let ticket: string;
+      try {
+        ({ ticket } = await api.post<{ ticket: string }>("/events/ticket"));
+      } catch {
+        retryTimeout = setTimeout(connect, 3000);
+        return;
+      }
+      if (closed) {
+        return;
+      }
+
+      source = new EventSource(`${API_URL}/events?ticket=${encodeURIComponent(ticket)}`);
+
+      Object.entries(invalidatedQueries).forEach(([type, queryKeys]) => {
+        source?.addEventListener(type, () => {
+          queryKeys.forEach((queryKey) => queryClient.invalidateQueries({ queryKey }));
+        });
+      });
+
+      // The stream dropped or was closed by the server; reconnect with a new ticket,
+      // which refreshes the access token if needed
+      source.onerror = () => {
+        source?.close();
+        retryTimeout = setTimeout(connect, 3000);
+      };
+    };
+
+    connect();
+
+    return () => {
+      closed = true;
+      clearTimeout(retryTimeout);
+      source?.close();
+    };
+  }, [enabled, queryClient]);
+};
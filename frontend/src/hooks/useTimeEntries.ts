@@ -6,7 +6,8 @@ export const useActiveTimer = () => {
     queryKey: ["activeTimer"],
     queryFn: () => timeEntryService.getActive(),
     select: (data) => data.active_timer,
-    refetchInterval: 5000,
+    // Live events keep this fresh; polling is only a fallback
+    refetchInterval: 60000,
   });
 };
 
@@ -0,0 +1,16 @@
+export interface Achievement {
+  key: string;
+  name: string;
+  description: string;
+  metric: string;
+  target: number;
+  current: number;
+  unlocked: boolean;
+  unlocked_at?: string;
+}
+
+export interface AchievementsResponse {
+  achievements: Achievement[];
+  unlocked: number;
+  total: number;
+}
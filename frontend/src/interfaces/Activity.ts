@@ -1,3 +1,4 @@
+import type { Project } from "./Billing";
 import type { Category } from "./Category";
 import type { Tag } from "./Tag";
 
@@ -7,6 +8,10 @@ export interface Activity {
   main_category: Category;
   sub_category?: Category | null;
   tags?: Tag[];
+  project_id?: number | null;
+  project?: Project | null;
+  hourly_rate_cents?: number | null;
+  currency?: string;
   total_seconds?: number;
   total_formatted?: string;
   entry_count?: number;
@@ -0,0 +1,32 @@
+export interface AnalyticsSession {
+  entry_id: number;
+  activity_id: number;
+  activity_name: string;
+  start_time: string;
+  seconds: number;
+}
+
+export interface Analytics {
+  from: string;
+  to: string;
+  timezone: string;
+  heatmap: number[][]; // [weekday][hour] tracked seconds, rows named by weekdays
+  weekdays: string[];
+  week_start: number; // Row the user's week begins with
+  total_seconds: number;
+  total_time: string;
+  session_count: number;
+  average_session_seconds: number;
+  longest_session: AnalyticsSession | null;
+  active_days: number;
+  median_daily_seconds: number;
+}
+
+export interface AnalyticsQuery {
+  from: string; // YYYY-MM-DD
+  to: string; // YYYY-MM-DD, inclusive
+  tz?: string;
+  activity_id?: number[];
+  category_id?: number[];
+  tag_id?: number[];
+}
@@ -0,0 +1,110 @@
+export type RoundingMode = "up" | "down" | "nearest";
+export type BillingRateSource = "activity" | "project" | "client";
+
+// Rates are in cents per hour
+export interface Client {
+  id: number;
+  user_id: number;
+  name: string;
+  hourly_rate_cents: number | null;
+  currency: string;
+  rounding_minutes: number;
+  rounding_mode: RoundingMode;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ClientRequest {
+  name: string;
+  hourly_rate_cents?: number | null;
+  currency?: string;
+  rounding_minutes?: number;
+  rounding_mode?: RoundingMode;
+}
+
+export interface Project {
+  id: number;
+  user_id: number;
+  client_id: number;
+  client?: Client;
+  name: string;
+  hourly_rate_cents: number | null;
+  currency: string; // Empty uses the client's
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ProjectRequest {
+  client_id: number;
+  name: string;
+  hourly_rate_cents?: number | null;
+  currency?: string;
+}
+
+export interface ActivityBillingRequest {
+  project_id: number | null;
+  hourly_rate_cents?: number | null;
+  currency?: string;
+}
+
+export interface BillingRate {
+  hourly_rate_cents: number;
+  currency: string;
+  source: BillingRateSource;
+}
+
+export interface BillingAmount {
+  currency: string;
+  amount_cents: number;
+}
+
+export interface BillingLine {
+  activity_id: number;
+  activity_name: string;
+  entry_count: number;
+  tracked_seconds: number;
+  billed_seconds: number;
+  billed_time: string;
+  rate: BillingRate | null;
+  amount_cents: number;
+}
+
+export interface ProjectBilling {
+  project_id: number;
+  name: string;
+  tracked_seconds: number;
+  billed_seconds: number;
+  billed_time: string;
+  amounts: BillingAmount[];
+  lines: BillingLine[];
+}
+
+export interface ClientBilling {
+  client_id: number;
+  name: string;
+  rounding_minutes: number;
+  rounding_mode: RoundingMode;
+  tracked_seconds: number;
+  billed_seconds: number;
+  billed_time: string;
+  amounts: BillingAmount[];
+  projects: ProjectBilling[];
+}
+
+export interface BillingSummary {
+  from: string;
+  to: string;
+  timezone: string;
+  amounts: BillingAmount[];
+  clients: ClientBilling[];
+}
+
+export interface BillingSummaryQuery {
+  from: string; // YYYY-MM-DD
+  to: string; // YYYY-MM-DD, inclusive
+  tz?: string;
+  client_id?: number[];
+  activity_id?: number[];
+  category_id?: number[];
+  tag_id?: number[];
+}
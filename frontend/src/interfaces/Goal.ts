@@ -0,0 +1,43 @@
+export type GoalKind = "minimum" | "maximum";
+export type GoalPeriod = "day" | "week" | "month";
+export type GoalScope = "activity" | "category" | "tag";
+export type GoalState = "on_track" | "behind" | "completed" | "exceeded";
+
+export interface Goal {
+  id: number;
+  user_id: number;
+  name: string;
+  kind: GoalKind;
+  period: GoalPeriod;
+  target_minutes: number;
+  scope_type: GoalScope;
+  scope_id: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface GoalRequest {
+  name?: string;
+  kind?: GoalKind;
+  period?: GoalPeriod;
+  target_minutes?: number;
+  scope_type?: GoalScope;
+  scope_id?: number;
+}
+
+// Also the payload of the goal.reached and goal.budget_exceeded events
+export interface GoalProgress {
+  goal: Goal;
+  period_start: string;
+  period_end: string;
+  tracked_seconds: number;
+  tracked_time: string;
+  target_seconds: number;
+  remaining_seconds: number;
+  percentage: number;
+  status: GoalState;
+}
+
+export interface GoalStatusResponse {
+  goals: GoalProgress[];
+}
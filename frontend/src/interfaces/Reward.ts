@@ -5,7 +5,8 @@ export type Rarity = "common" | "rare" | "epic";
 
 export interface Reward {
   id: number;
-  reward_type: RewardType;
+  catalog: string;
+  reward_type: RewardType | string;
   external_id: string;
   name: string;
   image_url: string;
@@ -13,13 +14,44 @@ export interface Reward {
   created_at?: string;
 }
 
+export interface OwnedReward {
+  id: number;
+  catalog: string;
+  reward_type: RewardType | string;
+  external_id: string;
+  name: string;
+  image_url: string;
+  rarity: Rarity;
+  count: number;
+  first_obtained_at: string;
+  last_obtained_at: string;
+}
+
+export interface CollectionResponse {
+  collection: OwnedReward[];
+  stats: {
+    unique_rewards: number;
+    total_copies: number;
+    by_rarity: Partial<Record<Rarity, number>>;
+  };
+}
+
 export interface ClaimedReward extends Reward {
   is_duplicate?: boolean;
   mastery_level?: number;
 }
 
+export interface RewardPity {
+  claims_since_rare: number;
+  claims_until_guaranteed: number;
+  rare_bonus: number;
+  guarantee_after: number;
+  last_rare_at?: string;
+}
+
 export interface RewardStatus {
   total_claimable: number;
+  pity: RewardPity;
   activities: {
     activity_id: number;
     activity_name: string;
@@ -34,5 +66,54 @@ export interface RewardsResponse {
 
 export interface ClaimResponse {
   reward: ClaimedReward;
+  pity: RewardPity;
   intervals_remaining: number;
 }
+
+export interface ClaimAllResponse {
+  rewards: (ClaimedReward & { activity_id: number })[];
+  claimed: number;
+  claimed_by_activity: Record<number, number>;
+  pity: RewardPity;
+}
+
+export interface RewardCatalog {
+  key: string;
+  name: string;
+  size: number;
+  source: "builtin" | "file" | "admin";
+}
+
+export interface RewardCatalogsResponse {
+  selected: string;
+  catalogs: RewardCatalog[];
+}
+
+export interface EssenceTransaction {
+  id: number;
+  amount: number;
+  balance: number;
+  reason: "disenchant" | "craft";
+  catalog: string;
+  reward_type: string;
+  external_id: string;
+  name: string;
+  created_at: string;
+}
+
+export interface EssenceResponse {
+  balance: number;
+  transactions: EssenceTransaction[];
+  disenchant_values: Record<Rarity, number>;
+  craft_costs: Record<Rarity, number>;
+}
+
+export interface EssenceChangeResponse {
+  essence: number;
+  balance: number;
+  transaction: EssenceTransaction;
+}
+
+export interface CraftResponse extends EssenceChangeResponse {
+  reward: Reward;
+}
@@ -0,0 +1,14 @@
+export type DateFormat = "YYYY-MM-DD" | "DD/MM/YYYY" | "MM/DD/YYYY";
+
+export interface UserSettings {
+  timezone: string; // IANA zone, empty for the server's zone
+  week_start: number; // 0 is Sunday
+  date_format: DateFormat;
+  updated_at: string;
+}
+
+export interface UpdateSettingsRequest {
+  timezone?: string;
+  week_start?: number;
+  date_format?: DateFormat;
+}
@@ -0,0 +1,26 @@
+import type { ClaimedReward, RewardPity } from "./Reward";
+
+export interface Streak {
+  current: number;
+  longest: number;
+  streak_start: string | null;
+  today_minutes: number;
+  today_completed: boolean;
+  min_minutes: number;
+  timezone: string;
+  freeze_tokens: number;
+  frozen_days: string[];
+  next_milestone: number | null;
+  claimable_milestones: number[];
+}
+
+export interface StreakSettingsRequest {
+  min_minutes?: number;
+}
+
+export interface StreakBonusResponse {
+  reward: ClaimedReward;
+  milestone: number;
+  pity: RewardPity;
+  streak: Streak;
+}
@@ -4,6 +4,7 @@ export interface TimeEntry {
   start_time: string;
   end_time?: string;
   duration_seconds?: number;
+  billable?: boolean;
 }
 
 export interface ActiveTimer {
@@ -8,6 +8,9 @@ export interface User {
 export interface AuthResponse {
   user: User;
   token: string;
+  expires_at: string;
+  refresh_token: string;
+  refresh_expires_at: string;
 }
 
 export interface LoginRequest {
@@ -6,3 +6,9 @@ export * from "./Reward";
 export * from "./ChampionMastery";
 export * from "./Resume";
 export * from "./User";
+export * from "./Achievement";
+export * from "./Streak";
+export * from "./Analytics";
+export * from "./Settings";
+export * from "./Goal";
+export * from "./Billing";
@@ -29,11 +29,15 @@ import {
   useRewardStatus,
   useClaimReward,
 } from "@/hooks/useRewards";
+import { useLiveEvents } from "@/hooks/useLiveEvents";
 
 export const HomePage: React.FC = () => {
   const { user, logout } = useAuth();
   const navigate = useNavigate();
 
+  // Refetch when the timer or activities change on another device
+  useLiveEvents(!!user);
+
   // React Query hooks
   const { data: activities = [] } = useActivities();
   const { data: categories = [] } = useCategories();
@@ -0,0 +1,6 @@
+import { api } from "./api";
+import type { AchievementsResponse } from "@/interfaces";
+
+export const achievementService = {
+  getAll: () => api.get<AchievementsResponse>("/achievements"),
+};
@@ -1,5 +1,5 @@
 import { api } from "./api";
-import type { Activity } from "@/interfaces";
+import type { Activity, ActivityBillingRequest } from "@/interfaces";
 
 export interface CreateActivityData {
   name: string;
@@ -24,5 +24,8 @@ export const activityService = {
   update: (id: number, data: UpdateActivityData) =>
     api.put<Activity>(`/activities/${id}`, data),
 
+  updateBilling: (id: number, data: ActivityBillingRequest) =>
+    api.put<Activity>(`/activities/${id}/billing`, data),
+
   delete: (id: number) => api.delete(`/activities/${id}`),
 };
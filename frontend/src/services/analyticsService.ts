@@ -0,0 +1,12 @@
+import { api } from "./api";
+import type { Analytics, AnalyticsQuery } from "@/interfaces";
+
+export const analyticsService = {
+  get: ({ activity_id, category_id, tag_id, ...range }: AnalyticsQuery) => {
+    const params = new URLSearchParams(range);
+    if (activity_id?.length) params.set("activity_id", activity_id.join(","));
+    if (category_id?.length) params.set("category_id", category_id.join(","));
+    if (tag_id?.length) params.set("tag_id", tag_id.join(","));
+    return api.get<Analytics>(`/analytics?${params}`);
+  },
+};
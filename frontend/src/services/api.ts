@@ -1,7 +1,25 @@
-const API_URL = "http://localhost:8085/api";
+import type { AuthResponse } from "@/interfaces";
+
+export const API_URL = "http://localhost:8085/api";
+
+export const tokenStorage = {
+  getToken: (): string | null => localStorage.getItem("token"),
+
+  getRefreshToken: (): string | null => localStorage.getItem("refresh_token"),
+
+  store: (authResponse: AuthResponse) => {
+    localStorage.setItem("token", authResponse.token);
+    localStorage.setItem("refresh_token", authResponse.refresh_token);
+  },
+
+  clear: () => {
+    localStorage.removeItem("token");
+    localStorage.removeItem("refresh_token");
+  },
+};
 
 const getAuthHeaders = (): HeadersInit => {
-  const token = localStorage.getItem("token");
+  const token = tokenStorage.getToken();
   const headers: HeadersInit = {
     "Content-Type": "application/json",
   };
@@ -13,64 +31,86 @@ const getAuthHeaders = (): HeadersInit => {
   return headers;
 };
 
-export const api = {
-  get: async <T>(endpoint: string): Promise<T> => {
-    const response = await fetch(`${API_URL}${endpoint}`, {
+// Shared so parallel requests that hit a 401 wait for a single refresh
+let refreshPromise: Promise<boolean> | null = null;
+
+export const refreshAccessToken = (): Promise<boolean> => {
+  if (!refreshPromise) {
+    refreshPromise = (async () => {
+      const refreshToken = tokenStorage.getRefreshToken();
+      if (!refreshToken) {
+        return false;
+      }
+
+      const response = await fetch(`${API_URL}/auth/refresh`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      });
+      if (!response.ok) {
+        return false;
+      }
+
+      tokenStorage.store(await response.json());
+      return true;
+    })()
+      .catch(() => false)
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
+// Sends a request and retries it once with a refreshed access token on 401
+const request = async (endpoint: string, init: RequestInit = {}): Promise<Response> => {
+  let response = await fetch(`${API_URL}${endpoint}`, {
+    ...init,
+    headers: getAuthHeaders(),
+  });
+
+  if (response.status === 401 && (await refreshAccessToken())) {
+    response = await fetch(`${API_URL}${endpoint}`, {
+      ...init,
       headers: getAuthHeaders(),
     });
-    if (!response.ok) {
-      if (response.status === 401) {
-        localStorage.removeItem("token");
-        window.location.href = "/login";
-      }
-      throw new Error(`API Error: ${response.status}`);
+  }
+
+  if (!response.ok) {
+    if (response.status === 401) {
+      tokenStorage.clear();
+      window.location.href = "/login";
     }
+    throw new Error(`API Error: ${response.status}`);
+  }
+  return response;
+};
+
+export const api = {
+  get: async <T>(endpoint: string): Promise<T> => {
+    const response = await request(endpoint);
     return response.json();
   },
 
   post: async <T>(endpoint: string, data?: unknown): Promise<T> => {
-    const response = await fetch(`${API_URL}${endpoint}`, {
+    const response = await request(endpoint, {
       method: "POST",
-      headers: getAuthHeaders(),
       body: data ? JSON.stringify(data) : undefined,
     });
-    if (!response.ok) {
-      if (response.status === 401) {
-        localStorage.removeItem("token");
-        window.location.href = "/login";
-      }
-      throw new Error(`API Error: ${response.status}`);
-    }
     return response.json();
   },
 
   put: async <T>(endpoint: string, data: unknown): Promise<T> => {
-    const response = await fetch(`${API_URL}${endpoint}`, {
+    const response = await request(endpoint, {
       method: "PUT",
-      headers: getAuthHeaders(),
       body: JSON.stringify(data),
     });
-    if (!response.ok) {
-      if (response.status === 401) {
-        localStorage.removeItem("token");
-        window.location.href = "/login";
-      }
-      throw new Error(`API Error: ${response.status}`);
-    }
     return response.json();
   },
 
   delete: async (endpoint: string): Promise<void> => {
-    const response = await fetch(`${API_URL}${endpoint}`, {
+    await request(endpoint, {
       method: "DELETE",
-      headers: getAuthHeaders(),
     });
-    if (!response.ok) {
-      if (response.status === 401) {
-        localStorage.removeItem("token");
-        window.location.href = "/login";
-      }
-      throw new Error(`API Error: ${response.status}`);
-    }
   },
 };
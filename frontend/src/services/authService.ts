@@ -4,6 +4,7 @@ import type {
   RegisterRequest,
   User,
 } from "@/interfaces";
+import { refreshAccessToken, tokenStorage } from "@/services/api";
 
 const API_URL = "http://localhost:8085/api";
 
@@ -21,7 +22,7 @@ export const authService = {
     }
 
     const authResponse: AuthResponse = await response.json();
-    localStorage.setItem("token", authResponse.token);
+    tokenStorage.store(authResponse);
     return authResponse;
   },
 
@@ -38,25 +39,39 @@ export const authService = {
     }
 
     const authResponse: AuthResponse = await response.json();
-    localStorage.setItem("token", authResponse.token);
+    tokenStorage.store(authResponse);
     return authResponse;
   },
 
-  logout: () => {
-    localStorage.removeItem("token");
+  logout: async () => {
+    const token = tokenStorage.getToken();
+    tokenStorage.clear();
+
+    // Revoke the session server-side; the local tokens are gone either way
+    if (token) {
+      await fetch(`${API_URL}/auth/logout`, {
+        method: "POST",
+        headers: { Authorization: `Bearer ${token}` },
+      }).catch(() => undefined);
+    }
   },
 
   getMe: async (): Promise<User> => {
-    const token = localStorage.getItem("token");
-    if (!token) {
+    const fetchMe = () =>
+      fetch(`${API_URL}/auth/me`, {
+        headers: {
+          Authorization: `Bearer ${tokenStorage.getToken()}`,
+        },
+      });
+
+    if (!tokenStorage.getToken()) {
       throw new Error("No token found");
     }
 
-    const response = await fetch(`${API_URL}/auth/me`, {
-      headers: {
-        Authorization: `Bearer ${token}`,
-      },
-    });
+    let response = await fetchMe();
+    if (response.status === 401 && (await refreshAccessToken())) {
+      response = await fetchMe();
+    }
 
     if (!response.ok) {
       throw new Error("Failed to fetch user");
@@ -67,6 +82,6 @@ export const authService = {
   },
 
   getToken: (): string | null => {
-    return localStorage.getItem("token");
+    return tokenStorage.getToken();
   },
 };
@@ -0,0 +1,13 @@
+import { api } from "./api";
+import type { BillingSummary, BillingSummaryQuery } from "@/interfaces";
+
+export const billingService = {
+  getSummary: ({ client_id, activity_id, category_id, tag_id, ...range }: BillingSummaryQuery) => {
+    const params = new URLSearchParams(range);
+    if (client_id?.length) params.set("client_id", client_id.join(","));
+    if (activity_id?.length) params.set("activity_id", activity_id.join(","));
+    if (category_id?.length) params.set("category_id", category_id.join(","));
+    if (tag_id?.length) params.set("tag_id", tag_id.join(","));
+    return api.get<BillingSummary>(`/billing/summary?${params}`);
+  },
+};
@@ -0,0 +1,12 @@
+import { api } from "./api";
+import type { Client, ClientRequest } from "@/interfaces";
+
+export const clientService = {
+  getAll: () => api.get<{ clients: Client[] }>("/clients"),
+
+  create: (data: ClientRequest) => api.post<Client>("/clients", data),
+
+  update: (id: number, data: ClientRequest) => api.put<Client>(`/clients/${id}`, data),
+
+  delete: (id: number) => api.delete(`/clients/${id}`),
+};
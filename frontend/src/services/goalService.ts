@@ -0,0 +1,14 @@
+import { api } from "./api";
+import type { Goal, GoalRequest, GoalStatusResponse } from "@/interfaces";
+
+export const goalService = {
+  getAll: () => api.get<Goal[]>("/goals"),
+
+  getStatus: () => api.get<GoalStatusResponse>("/goals/status"),
+
+  create: (data: GoalRequest) => api.post<Goal>("/goals", data),
+
+  update: (id: number, data: GoalRequest) => api.put<Goal>(`/goals/${id}`, data),
+
+  delete: (id: number) => api.delete(`/goals/${id}`),
+};
@@ -0,0 +1,13 @@
+import { api } from "./api";
+import type { Project, ProjectRequest } from "@/interfaces";
+
+export const projectService = {
+  getAll: (clientId?: number) =>
+    api.get<{ projects: Project[] }>(clientId ? `/projects?client_id=${clientId}` : "/projects"),
+
+  create: (data: ProjectRequest) => api.post<Project>("/projects", data),
+
+  update: (id: number, data: ProjectRequest) => api.put<Project>(`/projects/${id}`, data),
+
+  delete: (id: number) => api.delete(`/projects/${id}`),
+};
@@ -1,11 +1,44 @@
 import { api } from "./api";
-import type { RewardsResponse, RewardStatus, ClaimResponse } from "@/interfaces";
+import type {
+  RewardsResponse,
+  RewardStatus,
+  CollectionResponse,
+  ClaimResponse,
+  ClaimAllResponse,
+  RewardCatalog,
+  RewardCatalogsResponse,
+  EssenceResponse,
+  EssenceChangeResponse,
+  CraftResponse,
+} from "@/interfaces";
 
 export const rewardService = {
   getAll: () => api.get<RewardsResponse>("/rewards"),
 
   getStatus: () => api.get<RewardStatus>("/rewards/status"),
 
+  getCollection: () => api.get<CollectionResponse>("/rewards/collection"),
+
   claim: (activityId: number) =>
     api.post<ClaimResponse>("/rewards/claim", { activity_id: activityId }),
+
+  claimAll: () => api.post<ClaimAllResponse>("/rewards/claim-all", {}),
+
+  getCatalogs: () => api.get<RewardCatalogsResponse>("/rewards/catalogs"),
+
+  selectCatalog: (catalog: string) =>
+    api.put<{ selected: string; catalog: RewardCatalog }>("/rewards/catalog", {
+      catalog,
+    }),
+
+  getEssence: () => api.get<EssenceResponse>("/rewards/essence"),
+
+  disenchant: (rewardId: number) =>
+    api.post<EssenceChangeResponse>(`/rewards/${rewardId}/disenchant`, {}),
+
+  craft: (rewardType: string, externalId: string) =>
+    api.post<CraftResponse>("/rewards/craft", {
+      reward_type: rewardType,
+      external_id: externalId,
+    }),
 };
@@ -0,0 +1,8 @@
+import { api } from "./api";
+import type { UpdateSettingsRequest, UserSettings } from "@/interfaces";
+
+export const settingsService = {
+  get: () => api.get<UserSettings>("/settings"),
+
+  update: (data: UpdateSettingsRequest) => api.put<UserSettings>("/settings", data),
+};
@@ -0,0 +1,12 @@
+import { api } from "./api";
+import type { Streak, StreakBonusResponse, StreakSettingsRequest } from "@/interfaces";
+
+export const streakService = {
+  get: () => api.get<Streak>("/streaks"),
+
+  updateSettings: (data: StreakSettingsRequest) => api.put<Streak>("/streaks/settings", data),
+
+  freeze: (date?: string) => api.post<Streak>("/streaks/freeze", date ? { date } : {}),
+
+  claimBonus: () => api.post<StreakBonusResponse>("/streaks/claim"),
+};